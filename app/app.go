@@ -1,14 +1,18 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"raygo/canvas"
 	"raygo/obj"
 	"raygo/parser"
 	"raygo/progress"
+	"runtime"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -23,46 +27,107 @@ const (
 	UNKNOWN
 )
 
-func Run(args []string) {
+// samples per pixel axis when antialiasing is turned on with --aa
+const AA_SAMPLES = 4
+
+// exit codes returned by Run
+const (
+	EXIT_SUCCESS       = 0
+	EXIT_FAILURE       = 1 // everything that doesn't fit into the other categories
+	EXIT_USAGE         = 2 // invalid command line arguments
+	EXIT_INVALID_SCENE = 3 // the scene description is not valid yaml or fails validation
+	EXIT_LOAD_FAILED   = 4 // an input file or a file referenced by the scene cannot be loaded
+)
+
+// UsageError is returned for invalid command line arguments
+type UsageError struct {
+	Message string
+}
+
+func (e *UsageError) Error() string {
+	return e.Message
+}
+
+// Run executes the command given by args, reports errors on stderr and returns the exit code
+func Run(args []string) int {
+	if err := run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", parser.FormatError(err))
+		return exitCode(err)
+	}
+	return EXIT_SUCCESS
+}
+
+func run(args []string) error {
 	if fileFlagIndex := slices.Index(args, "-f"); fileFlagIndex != -1 {
 		if len(args) <= fileFlagIndex+1 {
-			panic("missing file path after -f flag")
+			return &UsageError{Message: "missing file path after -f flag"}
 		}
 		fp := args[fileFlagIndex+1]
 
 		switch determineFileType(fp) {
 		case OBJ:
-			handleObjStats(fp)
+			return handleObjStats(fp)
 		case YAML:
-			handleRendering(args, fp)
+			return handleRendering(args, fp)
 		default:
-			fmt.Printf("encountered input file with unfamiliar file ending: '%v'\n", fp)
+			return &UsageError{Message: fmt.Sprintf("encountered input file with unfamiliar file ending: '%v'", fp)}
 		}
 	}
+	return nil
 }
 
-func handleObjStats(fp string) {
-	object := obj.ParseFile(fp)
+func exitCode(err error) int {
+	var usageErr *UsageError
+	var syntaxErr *parser.SyntaxError
+	var validationErr *parser.ValidationError
+	var loadErr *parser.LoadError
+	var objErr *obj.ParseError
+
+	switch {
+	case errors.As(err, &usageErr):
+		return EXIT_USAGE
+	case errors.As(err, &syntaxErr), errors.As(err, &validationErr):
+		return EXIT_INVALID_SCENE
+	case errors.As(err, &loadErr), errors.As(err, &objErr), errors.Is(err, fs.ErrNotExist):
+		return EXIT_LOAD_FAILED
+	}
+	return EXIT_FAILURE
+}
+
+func handleObjStats(fp string) error {
+	object, err := obj.ParseFile(fp)
+	if err != nil {
+		return err
+	}
 	object.PrintStats()
+	return nil
 }
 
-func handleRendering(args []string, fp string) {
+func handleRendering(args []string, fp string) error {
 	startTime := time.Now()
 
-	outputFilename := getOutputFilename(args)
+	outputFilename, err := getOutputFilename(args)
+	if err != nil {
+		return err
+	}
 	outputFiletype := determineFileType(outputFilename)
 	switch outputFiletype {
 	case YAML, OBJ, UNKNOWN:
 		outputFiletype = PNG
 	}
 	antialias := checkAntialiasFlag(args)
-
-	progress.Step("Parsing Yaml")
-	yml := parseYamlFile(fp)
+	samples, err := getIntFlag(args, "--samples", 0)
+	if err != nil {
+		return err
+	}
+	threads, err := getIntFlag(args, "--threads", runtime.NumCPU())
+	if err != nil {
+		return err
+	}
 
 	absolutePath, err := filepath.Abs(fp)
 	if err != nil {
-		panic("unable to get absolute file path for yaml file")
+		return fmt.Errorf("unable to get absolute file path for yaml file: %w", err)
 	}
 	lastDirSep := strings.LastIndex(absolutePath, string(os.PathSeparator))
 	dirpath := ""
@@ -70,12 +135,36 @@ func handleRendering(args []string, fp string) {
 		dirpath = absolutePath[:lastDirSep+1]
 	}
 
+	progress.Step("Parsing Yaml")
+	yml, builder, err := parseYamlFile(fp, dirpath)
+	if err != nil {
+		return err
+	}
+	// the adaptive sampler ignores the sample count, so these flags would have no effect
+	if yml.Render != nil && yml.Render.Adaptive && (samples > 0 || antialias) {
+		return &UsageError{Message: "--samples and --aa cannot be combined with adaptive antialiasing of the scene"}
+	}
+
 	progress.Step("Creating Scene from Yaml")
-	world := parser.CreateWorld(yml, dirpath)
-	camera := parser.CreateCamera(yml)
-	camera.Antialias = antialias
+	world, err := builder.CreateWorld()
+	if err != nil {
+		return err
+	}
+	camera := builder.CreateCamera()
+	if samples > 0 {
+		camera.Sampler.Samples = samples
+	} else if antialias && camera.Sampler.Samples <= 1 {
+		camera.Sampler.Samples = AA_SAMPLES
+	}
+	camera.Threads = threads
 
 	c := camera.Render(world, true)
+	if camera.Sampler.Adaptive {
+		pixels := float64(camera.Hsize * camera.Vsize * len(c))
+		extraRays := camera.ExtraRays.Load()
+		progress.Step(fmt.Sprintf("Adaptive antialiasing cast %d extra rays (%.2f per pixel)",
+			extraRays, float64(extraRays)/pixels))
+	}
 	progress.Step("Writing output file")
 	if len(c) == 1 {
 		if outputFiletype == PNG {
@@ -84,41 +173,59 @@ func handleRendering(args []string, fp string) {
 			c[0].WritePPM(fmt.Sprintf("%v.ppm", outputFilename))
 		}
 	} else {
-		canvas.WriteGif(c, yml.Camera.Animation.Time, fmt.Sprintf("%v.gif", outputFilename))
+		// if both the camera and the world are animated they have the same duration
+		var duration float64
+		if yml.Camera.Animation != nil {
+			duration = yml.Camera.Animation.Time
+		} else {
+			duration = world.Animation.Duration
+		}
+		canvas.WriteGif(c, duration, fmt.Sprintf("%v.gif", outputFilename))
 	}
 	elapsed := time.Since(startTime)
 	progress.Complete(fmt.Sprintf("%.2f seconds", elapsed.Seconds()))
+	return nil
 }
 
-func parseYamlFile(path string) *parser.YamlDescription {
-	data, err := os.ReadFile(path)
+func parseYamlFile(path string, directory string) (*parser.YamlDescription, *parser.SceneBuilder, error) {
+	yml, err := parser.ParseYamlFile(path)
 	if err != nil {
-		panic(err)
+		return nil, nil, err
 	}
-
-	yml := parser.ParseYaml(string(data))
 	progress.Step("Validating Yaml")
-	validationResult := yml.Validate()
-	validationResult = append(validationResult, parser.ValidateReferences(yml)...)
-	if len(validationResult) != 0 {
-		for i, vr := range validationResult {
-			fmt.Printf("%v. %v\n", i, vr.Error())
-		}
-		os.Exit(1)
+	builder := parser.CreateSceneBuilder(yml, directory)
+	if err := builder.Validate(); err != nil {
+		return nil, nil, err
 	}
 
-	return yml
+	return yml, builder, nil
 }
 
-func getOutputFilename(args []string) string {
+func getOutputFilename(args []string) (string, error) {
 	outputFilename := "default"
 	if outputFlagIndex := slices.Index(args, "-o"); outputFlagIndex != -1 {
 		if len(args) <= outputFlagIndex+1 {
-			panic("missing file name after -o flag")
+			return "", &UsageError{Message: "missing file name after -o flag"}
 		}
 		outputFilename = args[outputFlagIndex+1]
 	}
-	return outputFilename
+	return outputFilename, nil
+}
+
+// getIntFlag returns the positive number following flag or defaultValue if the flag is missing
+func getIntFlag(args []string, flag string, defaultValue int) (int, error) {
+	if flagIndex := slices.Index(args, flag); flagIndex != -1 {
+		if len(args) <= flagIndex+1 {
+			return 0, &UsageError{Message: fmt.Sprintf("missing number after %v flag", flag)}
+		}
+		value, err := strconv.Atoi(args[flagIndex+1])
+		if err != nil || value < 1 {
+			message := fmt.Sprintf("invalid value '%v' for %v flag, expected a positive number", args[flagIndex+1], flag)
+			return 0, &UsageError{Message: message}
+		}
+		return value, nil
+	}
+	return defaultValue, nil
 }
 
 func checkAntialiasFlag(args []string) bool {
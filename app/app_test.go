@@ -1,5 +1,119 @@
 package app
 
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
 //func TestRun(t *testing.T) {
 //	Run([]string{"-f", "../local/penguin-scene.yaml", "-o", "penguin"})
 //}
+
+func TestGetIntFlag(t *testing.T) {
+	threads, err := getIntFlag([]string{"-f", "scene.yaml"}, "--threads", runtime.NumCPU())
+	assert.NilError(t, err)
+	assert.Assert(t, threads == runtime.NumCPU())
+
+	threads, err = getIntFlag([]string{"-f", "scene.yaml", "--threads", "3"}, "--threads", 1)
+	assert.NilError(t, err)
+	assert.Assert(t, threads == 3)
+
+	samples, err := getIntFlag([]string{"--samples", "5", "--threads", "3"}, "--samples", 0)
+	assert.NilError(t, err)
+	assert.Assert(t, samples == 5)
+}
+
+func TestGetIntFlagInvalidValue(t *testing.T) {
+	var usageErr *UsageError
+
+	_, err := getIntFlag([]string{"--threads", "none"}, "--threads", 1)
+	assert.Assert(t, errors.As(err, &usageErr))
+
+	_, err = getIntFlag([]string{"--threads"}, "--threads", 1)
+	assert.Assert(t, errors.As(err, &usageErr))
+}
+
+func TestRunExitCodes(t *testing.T) {
+	directory := t.TempDir()
+	writeFile := func(name string, content string) string {
+		path := filepath.Join(directory, name)
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	invalidYaml := writeFile("invalid.yaml", "scene: [spheres")
+	invalidScene := writeFile("scene.yaml", "width: 0")
+	missingObj := writeFile("teapot.yaml", `
+width: 10
+height: 10
+scene:
+  objects:
+    - name: teapot
+      file: missing.obj
+camera:
+  from:
+    x: 0
+    y: 0
+    z: -5
+  to:
+    x: 0
+    y: 0
+    z: 0
+  up:
+    x: 0
+    y: 1
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: 0
+  intensity:
+    r: 255
+    g: 255
+    b: 255`)
+	brokenObj := writeFile("broken.obj", "v 1 2\n")
+	adaptive := writeFile("adaptive.yaml", `
+width: 10
+height: 10
+render:
+  adaptive: true
+camera:
+  from:
+    x: 0
+    y: 0
+    z: -5
+  to:
+    x: 0
+    y: 0
+    z: 0
+  up:
+    x: 0
+    y: 1
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: 0
+  intensity:
+    r: 255
+    g: 255
+    b: 255`)
+
+	assert.Assert(t, Run([]string{"raygo", "-f"}) == EXIT_USAGE)
+	assert.Assert(t, Run([]string{"raygo", "-f", "scene.txt"}) == EXIT_USAGE)
+	assert.Assert(t, Run([]string{"raygo", "-f", invalidYaml}) == EXIT_INVALID_SCENE)
+	assert.Assert(t, Run([]string{"raygo", "-f", invalidScene}) == EXIT_INVALID_SCENE)
+	assert.Assert(t, Run([]string{"raygo", "-f", invalidScene, "--threads", "0"}) == EXIT_USAGE)
+	assert.Assert(t, Run([]string{"raygo", "-f", missingObj}) == EXIT_LOAD_FAILED)
+	assert.Assert(t, Run([]string{"raygo", "-f", filepath.Join(directory, "missing.yaml")}) == EXIT_LOAD_FAILED)
+	assert.Assert(t, Run([]string{"raygo", "-f", brokenObj}) == EXIT_LOAD_FAILED)
+	assert.Assert(t, Run([]string{"raygo", "-f", adaptive, "--samples", "3"}) == EXIT_USAGE)
+	assert.Assert(t, Run([]string{"raygo", "-f", adaptive, "--aa"}) == EXIT_USAGE)
+}
@@ -36,7 +36,7 @@ func CalculateBBCorners(b Bounds) []math.Point {
 
 func FindBoundingBox(corners []math.Point) *Bounds {
 	minX, minY, minZ := gomath.MaxFloat64, gomath.MaxFloat64, gomath.MaxFloat64
-	maxX, maxY, maxZ := gomath.SmallestNonzeroFloat64, gomath.SmallestNonzeroFloat64, gomath.SmallestNonzeroFloat64
+	maxX, maxY, maxZ := -gomath.MaxFloat64, -gomath.MaxFloat64, -gomath.MaxFloat64
 
 	for _, p := range corners {
 		minX = gomath.Min(minX, p.X)
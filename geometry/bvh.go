@@ -0,0 +1,214 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+)
+
+// nodes with at most this many children are not split any further
+const BVH_LEAF_SIZE = 4
+
+// number of buckets along an axis that are evaluated as split candidates
+const bvhBins = 12
+
+// estimated cost of testing a bounding box relative to intersecting one child
+const bvhTraversalCost = 1.0
+
+type bvhEntry struct {
+	shape    Shape
+	bounds   *Bounds // in the space of the group that is being divided
+	centroid math.Point
+}
+
+// BuildBVH subdivides the children of g into a tree of nested groups. Splits are
+// chosen with the surface area heuristic so that rays only have to test the
+// children whose bounding boxes they actually hit.
+// Children with infinite bounds (e.g. planes) stay direct children of g.
+func (g *Group) BuildBVH() {
+	entries := make([]bvhEntry, 0, len(g.Children))
+	unbounded := make([]Shape, 0)
+	for _, child := range g.Children {
+		b := TransformedBounds(child)
+		if !b.isFinite() {
+			unbounded = append(unbounded, child)
+			continue
+		}
+		entries = append(entries, bvhEntry{shape: child, bounds: b, centroid: b.center()})
+	}
+
+	g.Children = unbounded
+	for _, s := range splitBVH(entries) {
+		g.AddChild(s)
+	}
+	g.CachedBoundingBox = nil
+}
+
+// splitBVH returns the shapes that become the children of the node holding entries
+func splitBVH(entries []bvhEntry) []Shape {
+	if len(entries) > BVH_LEAF_SIZE {
+		if left, right, ok := sahSplit(entries); ok {
+			return []Shape{bvhNode(left), bvhNode(right)}
+		}
+	}
+
+	shapes := make([]Shape, len(entries))
+	for i, e := range entries {
+		shapes[i] = e.shape
+	}
+	return shapes
+}
+
+func bvhNode(entries []bvhEntry) Shape {
+	if len(entries) == 1 {
+		return entries[0].shape
+	}
+
+	node := EmptyGroup()
+	for _, s := range splitBVH(entries) {
+		node.AddChild(s)
+	}
+	return node
+}
+
+type bvhBin struct {
+	count  int
+	bounds *Bounds
+}
+
+// sahSplit looks for the cheapest partition of entries along any axis.
+// ok is false if keeping all entries in one node is cheaper.
+func sahSplit(entries []bvhEntry) (left []bvhEntry, right []bvhEntry, ok bool) {
+	centroids := make([]math.Point, len(entries))
+	var total *Bounds
+	for i, e := range entries {
+		centroids[i] = e.centroid
+		total = total.merge(e.bounds)
+	}
+	centroidBounds := FindBoundingBox(centroids)
+
+	// cost of a leaf is to intersect every child
+	bestCost := float64(len(entries))
+	bestAxis, bestSplit := -1, 0
+	totalArea := total.surfaceArea()
+
+	for axis := range 3 {
+		lower := component(centroidBounds.Minimum, axis)
+		extent := component(centroidBounds.Maximum, axis) - lower
+		if extent <= 0.0 {
+			continue
+		}
+
+		bins := make([]bvhBin, bvhBins)
+		for _, e := range entries {
+			b := &bins[binIndex(component(e.centroid, axis), lower, extent)]
+			b.count++
+			b.bounds = b.bounds.merge(e.bounds)
+		}
+
+		// rightArea[i] and rightCount[i] describe the bins from i to the end
+		rightArea := make([]float64, bvhBins)
+		rightCount := make([]int, bvhBins)
+		var acc *Bounds
+		count := 0
+		for i := bvhBins - 1; i > 0; i-- {
+			acc = acc.merge(bins[i].bounds)
+			count += bins[i].count
+			rightArea[i] = acc.surfaceArea()
+			rightCount[i] = count
+		}
+
+		acc = nil
+		count = 0
+		for split := 1; split < bvhBins; split++ {
+			acc = acc.merge(bins[split-1].bounds)
+			count += bins[split-1].count
+			if count == 0 || rightCount[split] == 0 {
+				continue
+			}
+
+			cost := bvhTraversalCost +
+				(acc.surfaceArea()*float64(count)+rightArea[split]*float64(rightCount[split]))/totalArea
+			if cost < bestCost {
+				bestCost = cost
+				bestAxis = axis
+				bestSplit = split
+			}
+		}
+	}
+
+	if bestAxis < 0 {
+		return nil, nil, false
+	}
+
+	lower := component(centroidBounds.Minimum, bestAxis)
+	extent := component(centroidBounds.Maximum, bestAxis) - lower
+	for _, e := range entries {
+		if binIndex(component(e.centroid, bestAxis), lower, extent) < bestSplit {
+			left = append(left, e)
+		} else {
+			right = append(right, e)
+		}
+	}
+	return left, right, true
+}
+
+func binIndex(value float64, lower float64, extent float64) int {
+	i := int(bvhBins * (value - lower) / extent)
+	return min(i, bvhBins-1)
+}
+
+func component(t math.Tuple, axis int) float64 {
+	switch axis {
+	case 0:
+		return t.X
+	case 1:
+		return t.Y
+	}
+	return t.Z
+}
+
+// merge returns the bounding box containing b and other, either of them may be nil
+func (b *Bounds) merge(other *Bounds) *Bounds {
+	if b == nil {
+		return other
+	}
+	if other == nil {
+		return b
+	}
+	return &Bounds{
+		Minimum: math.CreatePoint(
+			gomath.Min(b.Minimum.X, other.Minimum.X),
+			gomath.Min(b.Minimum.Y, other.Minimum.Y),
+			gomath.Min(b.Minimum.Z, other.Minimum.Z)),
+		Maximum: math.CreatePoint(
+			gomath.Max(b.Maximum.X, other.Maximum.X),
+			gomath.Max(b.Maximum.Y, other.Maximum.Y),
+			gomath.Max(b.Maximum.Z, other.Maximum.Z)),
+	}
+}
+
+func (b *Bounds) surfaceArea() float64 {
+	if b == nil {
+		return 0.0
+	}
+	dx := b.Maximum.X - b.Minimum.X
+	dy := b.Maximum.Y - b.Minimum.Y
+	dz := b.Maximum.Z - b.Minimum.Z
+	return 2.0 * (dx*dy + dy*dz + dz*dx)
+}
+
+func (b *Bounds) center() math.Point {
+	return math.CreatePoint(
+		(b.Minimum.X+b.Maximum.X)/2.0,
+		(b.Minimum.Y+b.Maximum.Y)/2.0,
+		(b.Minimum.Z+b.Maximum.Z)/2.0)
+}
+
+func (b *Bounds) isFinite() bool {
+	for _, v := range []float64{b.Minimum.X, b.Minimum.Y, b.Minimum.Z, b.Maximum.X, b.Maximum.Y, b.Maximum.Z} {
+		if gomath.IsInf(v, 0) || gomath.IsNaN(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package geometry
+
+import (
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func sphereRow(count int) *Group {
+	g := EmptyGroup()
+	for i := range count {
+		s := CreateSphere()
+		s.SetTransform(math.Translation(float64(3*i), 0.0, 0.0))
+		g.AddChild(s)
+	}
+	return g
+}
+
+func TestBuildBVHKeepsSmallGroups(t *testing.T) {
+	g := sphereRow(BVH_LEAF_SIZE)
+
+	g.BuildBVH()
+
+	assert.Assert(t, len(g.Children) == BVH_LEAF_SIZE)
+}
+
+func TestBuildBVHSplitsAlongSpreadAxis(t *testing.T) {
+	g := sphereRow(16)
+
+	g.BuildBVH()
+
+	assert.Assert(t, len(g.Children) == 2)
+	assert.Assert(t, g.Size() == 16)
+	left := g.Children[0].(*Group)
+	right := g.Children[1].(*Group)
+	assert.Assert(t, left.Bounds().Maximum.X < right.Bounds().Minimum.X)
+	assert.Assert(t, left.GetParent() == g)
+	assert.Assert(t, math.CreatePoint(-1.0, -1.0, -1.0).Equals(g.Bounds().Minimum))
+	assert.Assert(t, math.CreatePoint(46.0, 1.0, 1.0).Equals(g.Bounds().Maximum))
+}
+
+func TestBuildBVHKeepsUnboundedChildren(t *testing.T) {
+	g := sphereRow(16)
+	p := CreatePlane()
+	g.AddChild(p)
+
+	g.BuildBVH()
+
+	assert.Assert(t, len(g.Children) == 3)
+	assert.Assert(t, g.Children[0].Equals(p))
+	assert.Assert(t, p.GetParent() == g)
+}
+
+func TestBuildBVHIdenticalCentroids(t *testing.T) {
+	g := EmptyGroup()
+	for range 8 {
+		g.AddChild(CreateSphere())
+	}
+
+	g.BuildBVH()
+
+	assert.Assert(t, len(g.Children) == 8)
+}
+
+func TestBVHIntersectionsMatchFlatGroup(t *testing.T) {
+	flat := sphereRow(32)
+	bvh := sphereRow(32)
+	bvh.BuildBVH()
+	flat.CalculateInverseTransform()
+	bvh.CalculateInverseTransform()
+
+	for i := range 100 {
+		x := float64(i) - 2.0
+		r := CreateRay(math.CreatePoint(x, 0.5, -5.0), math.CreateVector(0.1, 0.0, 1.0))
+
+		expected := flat.Intersect(r)
+		actual := bvh.Intersect(r)
+
+		assert.Assert(t, len(expected) == len(actual))
+		for j := range expected {
+			assert.Assert(t, expected[j].IntersectionAt == actual[j].IntersectionAt)
+			assert.Assert(t, expected[j].Object.GetTransform().Equals(actual[j].Object.GetTransform()))
+		}
+	}
+}
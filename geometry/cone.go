@@ -18,6 +18,7 @@ type Cone struct {
 	Closed           bool
 	Parent           *Group
 	InverseTransform math.Matrix
+	Motion           *Motion
 }
 
 func CreateCone() *Cone {
@@ -68,7 +69,7 @@ func (c *Cone) Equals(other Shape) bool {
 }
 
 func (c *Cone) Intersect(ray Ray) []Intersection {
-	transformedRay := ray.Transform(c.GetInverseTransform())
+	transformedRay := ray.Transform(InverseTransformAt(c, ray.Time))
 	return c.localConeIntersect(transformedRay)
 }
 
@@ -149,9 +150,9 @@ func (c *Cone) intersectCaps(ray Ray) []Intersection {
 }
 
 func (c *Cone) NormalAt(point math.Point, hit Intersection) math.Vector {
-	objectSpace := WorldToObject(c, point)
+	objectSpace := WorldToObjectAt(c, point, hit.Time)
 	objectNormal := c.localConeNormalAt(objectSpace)
-	return NormalToWorld(c, objectNormal)
+	return NormalToWorldAt(c, objectNormal, hit.Time)
 }
 
 func (c *Cone) localConeNormalAt(point math.Point) math.Vector {
@@ -182,8 +183,17 @@ func (c *Cone) GetInverseTransform() math.Matrix {
 	return c.InverseTransform
 }
 
+func (c *Cone) SetMotion(m *Motion) {
+	c.Motion = m
+}
+
+func (c *Cone) GetMotion() *Motion {
+	return c.Motion
+}
+
 func (c *Cone) CalculateInverseTransform() {
 	c.InverseTransform = c.Transform.Inverse()
+	c.Motion.calculateInverses(c.Transform)
 }
 
 func (c *Cone) GetUvCoordinate(point math.Point, direction math.Vector) Texel {
@@ -0,0 +1,194 @@
+package geometry
+
+import (
+	"log"
+	"raygo/math"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+type CsgOperation int
+
+const (
+	UNION CsgOperation = iota
+	INTERSECTION
+	DIFFERENCE
+)
+
+type CSG struct {
+	Id        string
+	Operation CsgOperation
+	Left      Shape
+	Right     Shape
+	Material  Material
+	// the node carries transform and parent of the csg.
+	// Left and Right are its children, that way they can walk up the
+	// parent chain in WorldToObject and NormalToWorld like any grouped shape.
+	node *Group
+}
+
+func CreateCSG(op CsgOperation, left Shape, right Shape) *CSG {
+	node := EmptyGroup()
+	node.AddChild(left)
+	node.AddChild(right)
+
+	return &CSG{
+		Id:        uuid.NewString(),
+		Operation: op,
+		Left:      left,
+		Right:     right,
+		Material:  DefaultMaterial(),
+		node:      node,
+	}
+}
+
+func (c *CSG) Equals(other Shape) bool {
+	if reflect.TypeOf(c) == reflect.TypeOf(other) {
+		otherCsg := other.(*CSG)
+		return c.Id == otherCsg.Id &&
+			c.Operation == otherCsg.Operation &&
+			c.GetTransform().Equals(otherCsg.GetTransform()) &&
+			c.Left.Equals(otherCsg.Left) &&
+			c.Right.Equals(otherCsg.Right)
+	}
+	return false
+}
+
+func (c *CSG) GetId() string {
+	return c.Id
+}
+
+func (c *CSG) SetTransform(m math.Matrix) {
+	c.node.Transform = m
+}
+
+func (c *CSG) GetTransform() math.Matrix {
+	return c.node.Transform
+}
+
+func (c *CSG) SetMaterial(m Material) {
+	c.Material = m
+	c.Left.SetMaterial(m)
+	c.Right.SetMaterial(m)
+}
+
+func (c *CSG) GetMaterial() *Material {
+	return &c.Material
+}
+
+func (c *CSG) GetParent() *Group {
+	return c.node.Parent
+}
+
+func (c *CSG) SetParent(g *Group) {
+	c.node.Parent = g
+}
+
+func (c *CSG) Intersect(ray Ray) []Intersection {
+	transformedRay := ray.Transform(InverseTransformAt(c, ray.Time))
+	return c.localIntersect(transformedRay)
+}
+
+func (c *CSG) localIntersect(ray Ray) []Intersection {
+	bb := c.Bounds()
+	if len(BoundingBoxIntersect(ray, c, bb.Minimum, bb.Maximum)) == 0 {
+		return make([]Intersection, 0)
+	}
+
+	xs := c.Left.Intersect(ray)
+	xs = append(xs, c.Right.Intersect(ray)...)
+	SortIntersections(xs)
+
+	return c.FilterIntersections(xs)
+}
+
+// FilterIntersections keeps only the intersections that lie on the surface
+// of the combined shape. xs has to be sorted.
+func (c *CSG) FilterIntersections(xs []Intersection) []Intersection {
+	// begin outside of both children
+	inLeft := false
+	inRight := false
+
+	result := make([]Intersection, 0)
+	for _, i := range xs {
+		leftHit := Includes(c.Left, i.Object)
+
+		if IntersectionAllowed(c.Operation, leftHit, inLeft, inRight) {
+			result = append(result, i)
+		}
+
+		// depending on which object was hit, toggle either inLeft or inRight
+		if leftHit {
+			inLeft = !inLeft
+		} else {
+			inRight = !inRight
+		}
+	}
+
+	return result
+}
+
+// IntersectionAllowed decides if a hit is part of the surface of the csg.
+// leftHit is true if the left shape was hit, inLeft/inRight tell whether the
+// hit happens inside of the left/right shape.
+func IntersectionAllowed(op CsgOperation, leftHit bool, inLeft bool, inRight bool) bool {
+	switch op {
+	case UNION:
+		return (leftHit && !inRight) || (!leftHit && !inLeft)
+	case INTERSECTION:
+		return (leftHit && inRight) || (!leftHit && inLeft)
+	case DIFFERENCE:
+		return (leftHit && !inRight) || (!leftHit && inLeft)
+	}
+	return false
+}
+
+// Includes checks if other is s or one of its descendants
+func Includes(s Shape, other Shape) bool {
+	switch v := s.(type) {
+	case *Group:
+		for _, child := range v.Children {
+			if Includes(child, other) {
+				return true
+			}
+		}
+		return false
+	case *CSG:
+		return Includes(v.Left, other) || Includes(v.Right, other)
+	case *Instance:
+		hit, ok := other.(instanceHit)
+		return ok && hit.instance == v
+	}
+	return s.GetId() == other.GetId()
+}
+
+func (c *CSG) NormalAt(p math.Point, hit Intersection) math.Vector {
+	// the normal always comes from the child that was hit
+	return math.CreateVector(0.0, 1.0, 0.0)
+}
+
+func (c *CSG) Bounds() *Bounds {
+	return c.node.Bounds()
+}
+
+func (c *CSG) GetInverseTransform() math.Matrix {
+	return c.node.InverseTransform
+}
+
+func (c *CSG) SetMotion(m *Motion) {
+	c.node.Motion = m
+}
+
+func (c *CSG) GetMotion() *Motion {
+	return c.node.Motion
+}
+
+func (c *CSG) CalculateInverseTransform() {
+	c.node.CalculateInverseTransform()
+}
+
+func (c *CSG) GetUvCoordinate(point math.Point, direction math.Vector) Texel {
+	log.Fatal("GetUvCoordinate NOP")
+	return Texel{}
+}
@@ -0,0 +1,165 @@
+package geometry
+
+import (
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCreateCSG(t *testing.T) {
+	s1 := CreateSphere()
+	s2 := CreateCube()
+
+	c := CreateCSG(UNION, s1, s2)
+
+	assert.Assert(t, c.Operation == UNION)
+	assert.Assert(t, c.Left.Equals(s1))
+	assert.Assert(t, c.Right.Equals(s2))
+	assert.Assert(t, s1.GetParent() != nil)
+	assert.Assert(t, s1.GetParent() == s2.GetParent())
+}
+
+func TestIntersectionAllowed(t *testing.T) {
+	testCases := []struct {
+		op       CsgOperation
+		leftHit  bool
+		inLeft   bool
+		inRight  bool
+		expected bool
+	}{
+		{UNION, true, true, true, false},
+		{UNION, true, true, false, true},
+		{UNION, true, false, true, false},
+		{UNION, true, false, false, true},
+		{UNION, false, true, true, false},
+		{UNION, false, true, false, false},
+		{UNION, false, false, true, true},
+		{UNION, false, false, false, true},
+		{INTERSECTION, true, true, true, true},
+		{INTERSECTION, true, true, false, false},
+		{INTERSECTION, true, false, true, true},
+		{INTERSECTION, true, false, false, false},
+		{INTERSECTION, false, true, true, true},
+		{INTERSECTION, false, true, false, true},
+		{INTERSECTION, false, false, true, false},
+		{INTERSECTION, false, false, false, false},
+		{DIFFERENCE, true, true, true, false},
+		{DIFFERENCE, true, true, false, true},
+		{DIFFERENCE, true, false, true, false},
+		{DIFFERENCE, true, false, false, true},
+		{DIFFERENCE, false, true, true, true},
+		{DIFFERENCE, false, true, false, true},
+		{DIFFERENCE, false, false, true, false},
+		{DIFFERENCE, false, false, false, false},
+	}
+
+	for _, tc := range testCases {
+		actual := IntersectionAllowed(tc.op, tc.leftHit, tc.inLeft, tc.inRight)
+		assert.Assert(t, actual == tc.expected, "%v %v %v %v", tc.op, tc.leftHit, tc.inLeft, tc.inRight)
+	}
+}
+
+func TestFilterIntersections(t *testing.T) {
+	testCases := []struct {
+		op    CsgOperation
+		first int
+		last  int
+	}{
+		{UNION, 0, 3},
+		{INTERSECTION, 1, 2},
+		{DIFFERENCE, 0, 1},
+	}
+
+	for _, tc := range testCases {
+		s1 := CreateSphere()
+		s2 := CreateCube()
+		c := CreateCSG(tc.op, s1, s2)
+		xs := []Intersection{
+			CreateIntersection(1.0, s1),
+			CreateIntersection(2.0, s2),
+			CreateIntersection(3.0, s1),
+			CreateIntersection(4.0, s2),
+		}
+
+		actual := c.FilterIntersections(xs)
+
+		assert.Assert(t, len(actual) == 2)
+		assert.Assert(t, actual[0].IntersectionAt == xs[tc.first].IntersectionAt)
+		assert.Assert(t, actual[1].IntersectionAt == xs[tc.last].IntersectionAt)
+	}
+}
+
+func TestFilterIntersectionsWithNestedGroup(t *testing.T) {
+	s1 := CreateSphere()
+	g := EmptyGroup()
+	s2 := CreateCube()
+	g.AddChild(s2)
+	c := CreateCSG(DIFFERENCE, g, s1)
+	xs := []Intersection{
+		CreateIntersection(1.0, s1),
+		CreateIntersection(2.0, s2),
+		CreateIntersection(3.0, s1),
+		CreateIntersection(4.0, s2),
+	}
+
+	actual := c.FilterIntersections(xs)
+
+	assert.Assert(t, len(actual) == 2)
+	assert.Assert(t, actual[0].IntersectionAt == 3.0)
+	assert.Assert(t, actual[1].IntersectionAt == 4.0)
+}
+
+func TestRayMissesCSG(t *testing.T) {
+	c := CreateCSG(UNION, CreateSphere(), CreateCube())
+	c.CalculateInverseTransform()
+	r := CreateRay(math.CreatePoint(0.0, 2.0, -5.0), math.CreateVector(0.0, 0.0, 1.0))
+
+	actual := c.Intersect(r)
+
+	assert.Assert(t, len(actual) == 0)
+}
+
+func TestRayHitsCSG(t *testing.T) {
+	s1 := CreateSphere()
+	s2 := CreateSphere()
+	s2.SetTransform(math.Translation(0.0, 0.0, 0.5))
+	c := CreateCSG(UNION, s1, s2)
+	c.CalculateInverseTransform()
+	r := CreateRay(math.CreatePoint(0.0, 0.0, -5.0), math.CreateVector(0.0, 0.0, 1.0))
+
+	actual := c.Intersect(r)
+
+	assert.Assert(t, len(actual) == 2)
+	assert.Assert(t, actual[0].IntersectionAt == 4.0)
+	assert.Assert(t, actual[0].Object.Equals(s1))
+	assert.Assert(t, actual[1].IntersectionAt == 6.5)
+	assert.Assert(t, actual[1].Object.Equals(s2))
+}
+
+func TestCSGBounds(t *testing.T) {
+	s1 := CreateSphere()
+	s2 := CreateSphere()
+	s2.SetTransform(math.Translation(2.0, 3.0, 4.0))
+	c := CreateCSG(DIFFERENCE, s1, s2)
+
+	actual := c.Bounds()
+
+	assert.Assert(t, math.CreatePoint(-1.0, -1.0, -1.0).Equals(actual.Minimum))
+	assert.Assert(t, math.CreatePoint(3.0, 4.0, 5.0).Equals(actual.Maximum))
+}
+
+func TestCSGNormalAtChildInGroup(t *testing.T) {
+	g := EmptyGroup()
+	g.SetTransform(math.Translation(0.0, 5.0, 0.0))
+	s1 := CreateSphere()
+	c := CreateCSG(UNION, s1, CreateCube())
+	c.SetTransform(math.Scaling(2.0, 2.0, 2.0))
+	g.AddChild(c)
+	g.CalculateInverseTransform()
+
+	actual := s1.NormalAt(math.CreatePoint(2.0, 5.0, 0.0), Intersection{})
+
+	assert.Assert(t, math.CreateVector(1.0, 0.0, 0.0).Equals(actual))
+	assert.Assert(t, c.GetParent() == g)
+}
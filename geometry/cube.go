@@ -14,6 +14,7 @@ type Cube struct {
 	Material         Material
 	Parent           *Group
 	InverseTransform math.Matrix
+	Motion           *Motion
 }
 
 func CreateCube() *Cube {
@@ -61,7 +62,7 @@ func (c *Cube) Equals(other Shape) bool {
 }
 
 func (c *Cube) Intersect(ray Ray) []Intersection {
-	transformedRay := ray.Transform(c.GetInverseTransform())
+	transformedRay := ray.Transform(InverseTransformAt(c, ray.Time))
 	b := c.Bounds()
 	return BoundingBoxIntersect(transformedRay, c, b.Minimum, b.Maximum)
 }
@@ -108,9 +109,9 @@ func (c *Cube) checkAxis(origin float64, direction float64) (float64, float64) {
 }
 
 func (c *Cube) NormalAt(point math.Point, hit Intersection) math.Vector {
-	objectSpace := WorldToObject(c, point)
+	objectSpace := WorldToObjectAt(c, point, hit.Time)
 	objectNormal := c.localCubeNormalAt(objectSpace)
-	return NormalToWorld(c, objectNormal)
+	return NormalToWorldAt(c, objectNormal, hit.Time)
 }
 
 func (c *Cube) localCubeNormalAt(point math.Point) math.Vector {
@@ -142,8 +143,17 @@ func (c *Cube) GetInverseTransform() math.Matrix {
 	return c.InverseTransform
 }
 
+func (c *Cube) SetMotion(m *Motion) {
+	c.Motion = m
+}
+
+func (c *Cube) GetMotion() *Motion {
+	return c.Motion
+}
+
 func (c *Cube) CalculateInverseTransform() {
 	c.InverseTransform = c.Transform.Inverse()
+	c.Motion.calculateInverses(c.Transform)
 }
 
 // TODO: Cube Map textures?
@@ -18,6 +18,7 @@ type Cylinder struct {
 	Closed           bool
 	Parent           *Group
 	InverseTransform math.Matrix
+	Motion           *Motion
 }
 
 func CreateCylinder() *Cylinder {
@@ -68,7 +69,7 @@ func (c *Cylinder) Equals(other Shape) bool {
 }
 
 func (c *Cylinder) Intersect(ray Ray) []Intersection {
-	transformedRay := ray.Transform(c.GetInverseTransform())
+	transformedRay := ray.Transform(InverseTransformAt(c, ray.Time))
 	return c.localCylinderIntersect(transformedRay)
 }
 
@@ -142,9 +143,9 @@ func (c *Cylinder) intersectCaps(ray Ray) []Intersection {
 }
 
 func (c *Cylinder) NormalAt(point math.Point, hit Intersection) math.Vector {
-	objectSpace := WorldToObject(c, point)
+	objectSpace := WorldToObjectAt(c, point, hit.Time)
 	objectNormal := c.localCylinderNormalAt(objectSpace)
-	return NormalToWorld(c, objectNormal)
+	return NormalToWorldAt(c, objectNormal, hit.Time)
 }
 
 func (c *Cylinder) localCylinderNormalAt(point math.Point) math.Vector {
@@ -170,8 +171,17 @@ func (c *Cylinder) GetInverseTransform() math.Matrix {
 	return c.InverseTransform
 }
 
+func (c *Cylinder) SetMotion(m *Motion) {
+	c.Motion = m
+}
+
+func (c *Cylinder) GetMotion() *Motion {
+	return c.Motion
+}
+
 func (c *Cylinder) CalculateInverseTransform() {
 	c.InverseTransform = c.Transform.Inverse()
+	c.Motion.calculateInverses(c.Transform)
 }
 
 func (c *Cylinder) GetUvCoordinate(point math.Point, direction math.Vector) Texel {
@@ -0,0 +1,132 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// Disk lies in the xz plane around the origin and faces up. An InnerRadius greater than 0 cuts a hole
+// into the disk and turns it into an annulus.
+type Disk struct {
+	Id               string
+	Transform        math.Matrix
+	Material         Material
+	Radius           float64
+	InnerRadius      float64
+	Parent           *Group
+	InverseTransform math.Matrix
+	Motion           *Motion
+}
+
+func CreateDisk() *Disk {
+	return &Disk{
+		Id:          uuid.NewString(),
+		Transform:   math.IdentityMatrix(),
+		Material:    DefaultMaterial(),
+		Radius:      1.0,
+		InnerRadius: 0.0,
+		Parent:      nil,
+	}
+}
+
+func (d *Disk) SetTransform(m math.Matrix) {
+	d.Transform = m
+}
+
+func (d *Disk) GetId() string {
+	return d.Id
+}
+
+func (d *Disk) GetTransform() math.Matrix {
+	return d.Transform
+}
+
+func (d *Disk) GetMaterial() *Material {
+	return &d.Material
+}
+
+func (d *Disk) SetMaterial(m Material) {
+	d.Material = m
+}
+
+func (d *Disk) GetParent() *Group {
+	return d.Parent
+}
+
+func (d *Disk) SetParent(g *Group) {
+	d.Parent = g
+}
+
+func (d *Disk) Equals(other Shape) bool {
+	if reflect.TypeOf(d) != reflect.TypeOf(other) {
+		return false
+	}
+	otherDisk := other.(*Disk)
+	return d.Transform.Equals(other.GetTransform()) &&
+		d.Material.Equals(*other.GetMaterial()) &&
+		d.Radius == otherDisk.Radius &&
+		d.InnerRadius == otherDisk.InnerRadius &&
+		d.Parent == other.GetParent()
+}
+
+func (d *Disk) NormalAt(point math.Point, hit Intersection) math.Vector {
+	return NormalToWorldAt(d, math.CreateVector(0.0, 1.0, 0.0), hit.Time)
+}
+
+func (d *Disk) Intersect(ray Ray) []Intersection {
+	transformedRay := ray.Transform(InverseTransformAt(d, ray.Time))
+	return d.localDiskIntersect(transformedRay)
+}
+
+func (d *Disk) localDiskIntersect(localRay Ray) []Intersection {
+	xs := make([]Intersection, 0)
+	if gomath.Abs(localRay.Direction.Y) < math.EPSILON {
+		return xs
+	}
+
+	t := -localRay.Origin.Y / localRay.Direction.Y
+	p := localRay.Position(t)
+	distance2 := p.X*p.X + p.Z*p.Z
+	if distance2 > d.Radius*d.Radius || distance2 < d.InnerRadius*d.InnerRadius {
+		return xs
+	}
+	xs = append(xs, CreateIntersection(t, d))
+
+	return xs
+}
+
+func (d *Disk) Bounds() *Bounds {
+	return &Bounds{
+		Minimum: math.CreatePoint(-d.Radius, 0.0, -d.Radius),
+		Maximum: math.CreatePoint(d.Radius, 0.0, d.Radius),
+	}
+}
+
+func (d *Disk) GetInverseTransform() math.Matrix {
+	return d.InverseTransform
+}
+
+func (d *Disk) SetMotion(m *Motion) {
+	d.Motion = m
+}
+
+func (d *Disk) GetMotion() *Motion {
+	return d.Motion
+}
+
+func (d *Disk) CalculateInverseTransform() {
+	d.InverseTransform = d.Transform.Inverse()
+	d.Motion.calculateInverses(d.Transform)
+}
+
+// GetUvCoordinate projects the disk onto the unit square from above
+func (d *Disk) GetUvCoordinate(point math.Point, _ math.Vector) Texel {
+	return Texel{
+		U: (point.X/d.Radius + 1.0) / 2.0,
+		V: (point.Z/d.Radius + 1.0) / 2.0,
+		F: UNDEFINED,
+	}
+}
@@ -0,0 +1,61 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIntersectDisk(t *testing.T) {
+	d := CreateDisk()
+	d.Radius = 2.0
+	d.InnerRadius = 0.5
+	d.CalculateInverseTransform()
+
+	testCases := []struct {
+		origin math.Point
+		hits   bool
+	}{
+		{math.CreatePoint(1, 1, 0), true},
+		{math.CreatePoint(0, 1, 2), true},
+		{math.CreatePoint(1.5, 1, 1.5), false},
+		{math.CreatePoint(0.25, 1, 0.25), false},
+		{math.CreatePoint(0, 1, 0.5), true},
+	}
+
+	for _, tc := range testCases {
+		xs := d.Intersect(CreateRay(tc.origin, math.CreateVector(0, -1, 0)))
+
+		assert.Assert(t, (len(xs) == 1) == tc.hits, "%v: %v", tc.origin, xs)
+		if tc.hits {
+			assert.Assert(t, floatEquals(xs[0].IntersectionAt, 1.0))
+		}
+	}
+
+	xs := d.Intersect(CreateRay(math.CreatePoint(-5, 0, 0), math.CreateVector(1, 0, 0)))
+	assert.Assert(t, len(xs) == 0)
+}
+
+func TestDiskNormal(t *testing.T) {
+	d := CreateDisk()
+	d.SetTransform(math.Rotation_X(gomath.Pi / 2))
+	d.CalculateInverseTransform()
+
+	n := d.NormalAt(math.CreatePoint(0, 0, 0), CreateIntersection(0.0, d))
+
+	assert.Assert(t, n.Equals(math.CreateVector(0, 0, 1)), "%v", n)
+}
+
+func TestDiskBoundsAndUvCoordinate(t *testing.T) {
+	d := CreateDisk()
+	d.Radius = 2.0
+
+	b := d.Bounds()
+	texel := d.GetUvCoordinate(math.CreatePoint(1, 0, -2), math.CreateVector(0, 0, 0))
+
+	assert.Assert(t, b.Minimum.Equals(math.CreatePoint(-2, 0, -2)))
+	assert.Assert(t, b.Maximum.Equals(math.CreatePoint(2, 0, 2)))
+	assert.Assert(t, floatEquals(texel.U, 0.75) && floatEquals(texel.V, 0.0), "%v", texel)
+}
@@ -17,6 +17,7 @@ type Group struct {
 	Parent            *Group
 	CachedBoundingBox *Bounds
 	InverseTransform  math.Matrix
+	Motion            *Motion
 }
 
 func EmptyGroup() *Group {
@@ -92,7 +93,7 @@ func (g *Group) Size() int {
 }
 
 func (g *Group) Intersect(ray Ray) []Intersection {
-	transformedRay := ray.Transform(g.GetInverseTransform())
+	transformedRay := ray.Transform(InverseTransformAt(g, ray.Time))
 	return g.localIntersect(transformedRay)
 }
 
@@ -122,8 +123,7 @@ func (g *Group) Bounds() *Bounds {
 	// those BBs are in their respective object space but not axis aligned
 	nonAlignedBoundingBoxes := make([]*Bounds, 0)
 	for _, child := range g.Children {
-		childBB := child.Bounds()
-		nonAlignedBoundingBoxes = append(nonAlignedBoundingBoxes, childBB.ApplyTransform(child.GetTransform()))
+		nonAlignedBoundingBoxes = append(nonAlignedBoundingBoxes, TransformedBounds(child))
 	}
 
 	alignedMinimumBB := FindMinimalContainingBoundingBox(nonAlignedBoundingBoxes)
@@ -135,10 +135,19 @@ func (g *Group) GetInverseTransform() math.Matrix {
 	return g.InverseTransform
 }
 
+func (g *Group) SetMotion(m *Motion) {
+	g.Motion = m
+}
+
+func (g *Group) GetMotion() *Motion {
+	return g.Motion
+}
+
 func (g *Group) CalculateInverseTransform() {
 	var wg sync.WaitGroup
 
 	g.InverseTransform = g.Transform.Inverse()
+	g.Motion.calculateInverses(g.Transform)
 	for _, child := range g.Children {
 		wg.Go(func() {
 			child.CalculateInverseTransform()
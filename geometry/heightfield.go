@@ -0,0 +1,328 @@
+package geometry
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	gomath "math"
+	"raygo/math"
+	"reflect"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Heightfield is a terrain whose heights are sampled on a regular grid. Without scaling the grid covers
+// x and z from -0.5 to 0.5 and the heights go from 0 to 1. Scale stretches the terrain along each axis.
+// Neighbouring samples are connected by two triangles per cell, the normals are interpolated between the samples.
+type Heightfield struct {
+	Id               string
+	Transform        math.Matrix
+	Material         Material
+	Heights          []float64 // row by row, a row goes along x
+	Columns          int
+	Rows             int
+	Scale            math.Vector
+	Parent           *Group
+	InverseTransform math.Matrix
+	Motion           *Motion
+	normals          []math.Vector // of the unscaled terrain at every sample
+	minHeight        float64
+	maxHeight        float64
+}
+
+// HeightfieldError is returned when the image of a heightfield cannot be loaded
+type HeightfieldError struct {
+	File string
+	Err  error
+}
+
+func (e *HeightfieldError) Error() string {
+	return fmt.Sprintf("cannot load heightfield '%v': %v", e.File, e.Err)
+}
+
+func (e *HeightfieldError) Unwrap() error {
+	return e.Err
+}
+
+// CreateHeightfield takes the brightness of every pixel as the height of a sample, black is 0 and white is 1.
+// The columns of the image go along x, the rows along z.
+func CreateHeightfield(img image.Image) *Heightfield {
+	bb := img.Bounds()
+	heights := make([]float64, 0, bb.Dx()*bb.Dy())
+	for y := bb.Min.Y; y < bb.Max.Y; y++ {
+		for x := bb.Min.X; x < bb.Max.X; x++ {
+			gray := color.Gray16Model.Convert(img.At(x, y)).(color.Gray16)
+			heights = append(heights, float64(gray.Y)/0xffff)
+		}
+	}
+
+	h := &Heightfield{
+		Id:        uuid.NewString(),
+		Transform: math.IdentityMatrix(),
+		Material:  DefaultMaterial(),
+		Heights:   heights,
+		Columns:   bb.Dx(),
+		Rows:      bb.Dy(),
+		Scale:     math.CreateVector(1.0, 1.0, 1.0),
+		Parent:    nil,
+	}
+	h.initSamples()
+	return h
+}
+
+// LoadHeightfield creates a heightfield from a png or jpeg file, it needs at least 2 x 2 pixels
+func LoadHeightfield(file string) (*Heightfield, error) {
+	img, err := loadImage(file)
+	if err != nil {
+		return nil, &HeightfieldError{File: file, Err: err}
+	}
+	if img.Bounds().Dx() < 2 || img.Bounds().Dy() < 2 {
+		err := fmt.Errorf("image has %vx%v pixels, at least 2x2 are needed", img.Bounds().Dx(), img.Bounds().Dy())
+		return nil, &HeightfieldError{File: file, Err: err}
+	}
+	return CreateHeightfield(img), nil
+}
+
+// initSamples computes the range of the heights and the normals at the samples from their neighbours
+func (h *Heightfield) initSamples() {
+	h.minHeight = slices.Min(h.Heights)
+	h.maxHeight = slices.Max(h.Heights)
+
+	spacingX := 1.0 / float64(h.Columns-1)
+	spacingZ := 1.0 / float64(h.Rows-1)
+	h.normals = make([]math.Vector, 0, len(h.Heights))
+	for row := range h.Rows {
+		for column := range h.Columns {
+			left, right := max(column-1, 0), min(column+1, h.Columns-1)
+			back, front := max(row-1, 0), min(row+1, h.Rows-1)
+			slopeX := (h.heightAt(right, row) - h.heightAt(left, row)) / (float64(right-left) * spacingX)
+			slopeZ := (h.heightAt(column, front) - h.heightAt(column, back)) / (float64(front-back) * spacingZ)
+			h.normals = append(h.normals, math.CreateVector(-slopeX, 1.0, -slopeZ).Normalize())
+		}
+	}
+}
+
+func (h *Heightfield) heightAt(column int, row int) float64 {
+	return h.Heights[row*h.Columns+column]
+}
+
+func (h *Heightfield) SetTransform(m math.Matrix) {
+	h.Transform = m
+}
+
+func (h *Heightfield) GetId() string {
+	return h.Id
+}
+
+func (h *Heightfield) GetTransform() math.Matrix {
+	return h.Transform
+}
+
+func (h *Heightfield) GetMaterial() *Material {
+	return &h.Material
+}
+
+func (h *Heightfield) SetMaterial(m Material) {
+	h.Material = m
+}
+
+func (h *Heightfield) GetParent() *Group {
+	return h.Parent
+}
+
+func (h *Heightfield) SetParent(g *Group) {
+	h.Parent = g
+}
+
+func (h *Heightfield) Equals(other Shape) bool {
+	if reflect.TypeOf(h) != reflect.TypeOf(other) {
+		return false
+	}
+	otherHeightfield := other.(*Heightfield)
+	return h.Transform.Equals(other.GetTransform()) &&
+		h.Material.Equals(*other.GetMaterial()) &&
+		h.Columns == otherHeightfield.Columns &&
+		h.Rows == otherHeightfield.Rows &&
+		slices.Equal(h.Heights, otherHeightfield.Heights) &&
+		h.Scale.Equals(otherHeightfield.Scale) &&
+		h.Parent == other.GetParent()
+}
+
+func (h *Heightfield) Intersect(ray Ray) []Intersection {
+	transformedRay := ray.Transform(InverseTransformAt(h, ray.Time))
+	return h.localHeightfieldIntersect(transformedRay)
+}
+
+// localHeightfieldIntersect walks through the cells of the grid that lie under the ray with a 2D DDA
+// and tests the two triangles of each cell. The walk stops at the first cell that is hit, so only the closest
+// intersection in front of the origin is returned. A terrain is not a closed solid that could be refracted through.
+func (h *Heightfield) localHeightfieldIntersect(ray Ray) []Intersection {
+	xs := make([]Intersection, 0)
+
+	// in the unscaled terrain the t values stay the same
+	unscaled := CreateRay(
+		math.CreatePoint(ray.Origin.X/h.Scale.X, ray.Origin.Y/h.Scale.Y, ray.Origin.Z/h.Scale.Z),
+		math.CreateVector(ray.Direction.X/h.Scale.X, ray.Direction.Y/h.Scale.Y, ray.Direction.Z/h.Scale.Z))
+	tmin, tmax := branchlessCheck(unscaled,
+		math.CreatePoint(-0.5, h.minHeight, -0.5),
+		math.CreatePoint(0.5, h.maxHeight, 0.5))
+	tmin = gomath.Max(tmin, 0.0)
+	if tmax < tmin || gomath.IsNaN(tmin) || gomath.IsNaN(tmax) {
+		return xs
+	}
+
+	// grid coordinates go from 0 to the number of cells along each axis
+	cellsX, cellsZ := h.Columns-1, h.Rows-1
+	start := unscaled.Position(tmin)
+	gx := (start.X + 0.5) * float64(cellsX)
+	gz := (start.Z + 0.5) * float64(cellsZ)
+	dx := unscaled.Direction.X * float64(cellsX)
+	dz := unscaled.Direction.Z * float64(cellsZ)
+
+	column := min(max(int(gomath.Floor(gx)), 0), cellsX-1)
+	row := min(max(int(gomath.Floor(gz)), 0), cellsZ-1)
+	stepX, nextX, deltaX := ddaAxis(gx, dx, column, tmin)
+	stepZ, nextZ, deltaZ := ddaAxis(gz, dz, row, tmin)
+
+	for column >= 0 && column < cellsX && row >= 0 && row < cellsZ {
+		if t, ok := h.intersectCell(unscaled, column, row); ok {
+			xs = append(xs, CreateIntersection(t, h))
+			return xs
+		}
+		if nextX < nextZ {
+			if nextX > tmax {
+				break
+			}
+			column += stepX
+			nextX += deltaX
+		} else {
+			if nextZ > tmax {
+				break
+			}
+			row += stepZ
+			nextZ += deltaZ
+		}
+	}
+	return xs
+}
+
+// ddaAxis returns the direction of the steps along one axis, the t at which the ray leaves the current cell
+// and the t it takes to cross a whole cell
+func ddaAxis(position float64, direction float64, cell int, t float64) (int, float64, float64) {
+	switch {
+	case direction > 0.0:
+		return 1, t + (float64(cell+1)-position)/direction, 1.0 / direction
+	case direction < 0.0:
+		return -1, t + (float64(cell)-position)/direction, -1.0 / direction
+	}
+	return 0, gomath.Inf(1), gomath.Inf(1)
+}
+
+// intersectCell returns the closest intersection in front of the origin with the two triangles of a cell
+func (h *Heightfield) intersectCell(ray Ray, column int, row int) (float64, bool) {
+	x0 := float64(column)/float64(h.Columns-1) - 0.5
+	x1 := float64(column+1)/float64(h.Columns-1) - 0.5
+	z0 := float64(row)/float64(h.Rows-1) - 0.5
+	z1 := float64(row+1)/float64(h.Rows-1) - 0.5
+	p00 := math.CreatePoint(x0, h.heightAt(column, row), z0)
+	p10 := math.CreatePoint(x1, h.heightAt(column+1, row), z0)
+	p01 := math.CreatePoint(x0, h.heightAt(column, row+1), z1)
+	p11 := math.CreatePoint(x1, h.heightAt(column+1, row+1), z1)
+
+	t1, ok1 := intersectTriangle(ray, p00, p11, p10)
+	t2, ok2 := intersectTriangle(ray, p00, p01, p11)
+	switch {
+	case ok1 && ok2:
+		return gomath.Min(t1, t2), true
+	case ok1:
+		return t1, true
+	case ok2:
+		return t2, true
+	}
+	return 0.0, false
+}
+
+// intersectTriangle is the Möller–Trumbore test of Triangle for a triangle that is not a shape of its own.
+// The small tolerance closes the gaps along the shared edges of the triangles.
+func intersectTriangle(ray Ray, p1 math.Point, p2 math.Point, p3 math.Point) (float64, bool) {
+	const tolerance = 1e-9
+	e1 := p2.Subtract(p1)
+	e2 := p3.Subtract(p1)
+	dirCrossE2 := ray.Direction.Cross(e2)
+	determinant := e1.Dot(dirCrossE2)
+	if gomath.Abs(determinant) < math.EPSILON*math.EPSILON {
+		return 0.0, false
+	}
+
+	f := 1.0 / determinant
+	p1ToOrigin := ray.Origin.Subtract(p1)
+	u := f * p1ToOrigin.Dot(dirCrossE2)
+	if u < -tolerance || u > 1.0+tolerance {
+		return 0.0, false
+	}
+	originCrossE1 := p1ToOrigin.Cross(e1)
+	v := f * ray.Direction.Dot(originCrossE1)
+	if v < -tolerance || u+v > 1.0+tolerance {
+		return 0.0, false
+	}
+	t := f * e2.Dot(originCrossE1)
+	return t, t >= 0.0
+}
+
+func (h *Heightfield) NormalAt(point math.Point, hit Intersection) math.Vector {
+	objectSpace := WorldToObjectAt(h, point, hit.Time)
+	objectNormal := h.localHeightfieldNormalAt(objectSpace)
+	return NormalToWorldAt(h, objectNormal, hit.Time)
+}
+
+// localHeightfieldNormalAt interpolates bilinearly between the normals of the four samples around the point
+func (h *Heightfield) localHeightfieldNormalAt(point math.Point) math.Vector {
+	gx := (point.X/h.Scale.X + 0.5) * float64(h.Columns-1)
+	gz := (point.Z/h.Scale.Z + 0.5) * float64(h.Rows-1)
+	column := min(max(int(gomath.Floor(gx)), 0), h.Columns-2)
+	row := min(max(int(gomath.Floor(gz)), 0), h.Rows-2)
+	fx := clamp(gx-float64(column), 0.0, 1.0)
+	fz := clamp(gz-float64(row), 0.0, 1.0)
+
+	n := h.normals
+	back := n[row*h.Columns+column].Mul(1.0 - fx).Add(n[row*h.Columns+column+1].Mul(fx))
+	front := n[(row+1)*h.Columns+column].Mul(1.0 - fx).Add(n[(row+1)*h.Columns+column+1].Mul(fx))
+	normal := back.Mul(1.0 - fz).Add(front.Mul(fz))
+
+	// normals are scaled by the inverse of the scale
+	return math.CreateVector(normal.X/h.Scale.X, normal.Y/h.Scale.Y, normal.Z/h.Scale.Z).Normalize()
+}
+
+func (h *Heightfield) Bounds() *Bounds {
+	return &Bounds{
+		Minimum: math.CreatePoint(-0.5*h.Scale.X, h.minHeight*h.Scale.Y, -0.5*h.Scale.Z),
+		Maximum: math.CreatePoint(0.5*h.Scale.X, h.maxHeight*h.Scale.Y, 0.5*h.Scale.Z),
+	}
+}
+
+func (h *Heightfield) GetInverseTransform() math.Matrix {
+	return h.InverseTransform
+}
+
+func (h *Heightfield) SetMotion(m *Motion) {
+	h.Motion = m
+}
+
+func (h *Heightfield) GetMotion() *Motion {
+	return h.Motion
+}
+
+func (h *Heightfield) CalculateInverseTransform() {
+	h.InverseTransform = h.Transform.Inverse()
+	h.Motion.calculateInverses(h.Transform)
+}
+
+// GetUvCoordinate maps the terrain onto the image it was created from
+func (h *Heightfield) GetUvCoordinate(point math.Point, _ math.Vector) Texel {
+	return Texel{
+		U: point.X/h.Scale.X + 0.5,
+		V: point.Z/h.Scale.Z + 0.5,
+		F: UNDEFINED,
+	}
+}
@@ -0,0 +1,137 @@
+package geometry
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func grayImage(columns int, rows int, heights ...uint16) image.Image {
+	img := image.NewGray16(image.Rect(0, 0, columns, rows))
+	for i, h := range heights {
+		img.SetGray16(i%columns, i/columns, color.Gray16{Y: h})
+	}
+	return img
+}
+
+func TestIntersectHeightfield(t *testing.T) {
+	// a peak in the middle of a flat terrain
+	h := CreateHeightfield(grayImage(3, 3,
+		0, 0, 0,
+		0, 0xffff, 0,
+		0, 0, 0))
+	h.CalculateInverseTransform()
+
+	testCases := []struct {
+		origin    math.Point
+		direction math.Vector
+		expected  []float64
+	}{
+		{math.CreatePoint(0, 5, 0), math.CreateVector(0, -1, 0), []float64{4}},
+		{math.CreatePoint(0.25, 5, 0), math.CreateVector(0, -1, 0), []float64{4.5}},
+		{math.CreatePoint(0.4, 2, -0.4), math.CreateVector(0, -1, 0), []float64{2}},
+		// through the side of the peak
+		{math.CreatePoint(-5, 0.5, 0), math.CreateVector(1, 0, 0), []float64{4.75}},
+		{math.CreatePoint(0, 0.5, 5), math.CreateVector(0, 0, -2), []float64{2.375}},
+		// above the peak and away from the terrain
+		{math.CreatePoint(-5, 1.5, 0), math.CreateVector(1, 0, 0), []float64{}},
+		{math.CreatePoint(0, 5, 0), math.CreateVector(0, 1, 0), []float64{}},
+		{math.CreatePoint(2, 5, 0), math.CreateVector(0, -1, 0), []float64{}},
+	}
+
+	for _, tc := range testCases {
+		xs := h.Intersect(CreateRay(tc.origin, tc.direction))
+
+		assert.Assert(t, len(xs) == len(tc.expected), "%v: %v", tc.origin, xs)
+		for i, expected := range tc.expected {
+			assert.Assert(t, floatEquals(xs[i].IntersectionAt, expected), "%v: %v", tc.origin, xs[i].IntersectionAt)
+		}
+	}
+}
+
+func TestIntersectLargeHeightfield(t *testing.T) {
+	// a diagonal ray crosses many cells before it reaches the wall at the far end
+	heights := make([]uint16, 100*100)
+	for i := range 100 {
+		heights[i*100+99] = 0xffff
+	}
+	h := CreateHeightfield(grayImage(100, 100, heights...))
+	h.Scale = math.CreateVector(99, 1, 99)
+	h.CalculateInverseTransform()
+	r := CreateRay(math.CreatePoint(-49.5, 0.5, -49.5), math.CreateVector(1, 0, 1))
+
+	xs := h.Intersect(r)
+
+	// the wall rises from x = 48.5 to x = 49.5, half way up at x = 49
+	assert.Assert(t, len(xs) == 1, "%v", xs)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 98.5), "%v", xs[0].IntersectionAt)
+}
+
+func TestHeightfieldNormal(t *testing.T) {
+	// a ramp along x
+	h := CreateHeightfield(grayImage(2, 2,
+		0, 0xffff,
+		0, 0xffff))
+	h.CalculateInverseTransform()
+
+	n := h.NormalAt(math.CreatePoint(0.1, 0.6, 0.2), CreateIntersection(0.0, h))
+	assert.Assert(t, n.Equals(math.CreateVector(-1, 1, 0).Normalize()), "%v", n)
+
+	h.Scale = math.CreateVector(1, 2, 1)
+	n = h.NormalAt(math.CreatePoint(0.1, 1.2, 0.2), CreateIntersection(0.0, h))
+	assert.Assert(t, n.Equals(math.CreateVector(-2, 1, 0).Normalize()), "%v", n)
+
+	// normals are interpolated between the samples
+	peak := CreateHeightfield(grayImage(3, 3,
+		0, 0, 0,
+		0, 0xffff, 0,
+		0, 0, 0))
+	peak.CalculateInverseTransform()
+	assert.Assert(t, peak.NormalAt(math.CreatePoint(0, 1, 0), CreateIntersection(0.0, peak)).Equals(math.CreateVector(0, 1, 0)))
+	n = peak.NormalAt(math.CreatePoint(0.25, 0.5, 0), CreateIntersection(0.0, peak))
+	assert.Assert(t, n.X > 0 && n.Y > 0 && floatEquals(n.Z, 0), "%v", n)
+}
+
+func TestHeightfieldBoundsAndUvCoordinate(t *testing.T) {
+	h := CreateHeightfield(grayImage(2, 2, 0x4000, 0x8000, 0xc000, 0x8000))
+	h.Scale = math.CreateVector(10, 2, 4)
+
+	b := h.Bounds()
+	texel := h.GetUvCoordinate(math.CreatePoint(2.5, 1, -2), math.CreateVector(0, 0, 0))
+
+	assert.Assert(t, b.Minimum.Equals(math.CreatePoint(-5, 2*float64(0x4000)/0xffff, -2)), "%v", b.Minimum)
+	assert.Assert(t, b.Maximum.Equals(math.CreatePoint(5, 2*float64(0xc000)/0xffff, 2)), "%v", b.Maximum)
+	assert.Assert(t, floatEquals(texel.U, 0.75) && floatEquals(texel.V, 0.0), "%v", texel)
+}
+
+func TestLoadHeightfield(t *testing.T) {
+	dir := t.TempDir()
+	writePng := func(name string, img image.Image) string {
+		file := filepath.Join(dir, name)
+		f, err := os.Create(file)
+		assert.NilError(t, err)
+		defer f.Close()
+		assert.NilError(t, png.Encode(f, img))
+		return file
+	}
+
+	h, err := LoadHeightfield(writePng("terrain.png", grayImage(3, 2, 0, 0xffff, 0, 0, 0, 0)))
+	assert.NilError(t, err)
+	assert.Assert(t, h.Columns == 3 && h.Rows == 2)
+	assert.Assert(t, h.Heights[1] == 1.0)
+
+	var heightfieldErr *HeightfieldError
+	_, err = LoadHeightfield(writePng("pixel.png", grayImage(1, 1)))
+	assert.Assert(t, errors.As(err, &heightfieldErr))
+	_, err = LoadHeightfield(filepath.Join(dir, "missing.png"))
+	assert.Assert(t, errors.As(err, &heightfieldErr))
+	assert.Assert(t, errors.Is(err, fs.ErrNotExist))
+}
@@ -0,0 +1,240 @@
+package geometry
+
+import (
+	"log"
+	"raygo/math"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// Instance places a shared Prototype with its own transform and optionally its own material.
+// The prototype must not be part of the scene itself, its inverse transforms are calculated once
+// by whoever creates it because several instances may use it concurrently.
+type Instance struct {
+	Id               string
+	Transform        math.Matrix
+	Material         *Material            // nil uses the materials of the prototype
+	NamedMaterials   map[string]*Material // replace materials of the prototype with the same name
+	Prototype        *Group
+	Parent           *Group
+	InverseTransform math.Matrix
+	Motion           *Motion
+}
+
+func CreateInstance(prototype *Group) *Instance {
+	return &Instance{
+		Id:             uuid.NewString(),
+		Transform:      math.IdentityMatrix(),
+		Material:       nil,
+		NamedMaterials: make(map[string]*Material),
+		Prototype:      prototype,
+		Parent:         nil,
+	}
+}
+
+func (i *Instance) SetTransform(m math.Matrix) {
+	i.Transform = m
+}
+
+func (i *Instance) GetId() string {
+	return i.Id
+}
+
+func (i *Instance) GetTransform() math.Matrix {
+	return i.Transform
+}
+
+func (i *Instance) GetMaterial() *Material {
+	if i.Material != nil {
+		return i.Material
+	}
+	return i.Prototype.GetMaterial()
+}
+
+// SetMaterial overrides the material of every shape of the prototype for this instance only
+func (i *Instance) SetMaterial(m Material) {
+	i.Material = &m
+}
+
+// SetNamedMaterial replaces the material called name, like one from an mtl file, for this instance only.
+// It takes precedence over the material set by SetMaterial.
+func (i *Instance) SetNamedMaterial(name string, m Material) {
+	i.NamedMaterials[name] = &m
+}
+
+// PrototypeMaterials returns the materials of the prototype's shapes as seen through this instance,
+// keyed by the name of the material in the prototype. Shapes without a named material are listed under "".
+func (i *Instance) PrototypeMaterials() map[string]Material {
+	materials := make(map[string]Material)
+	var collect func(g *Group)
+	collect = func(g *Group) {
+		for _, child := range g.Children {
+			if group, ok := child.(*Group); ok {
+				collect(group)
+				continue
+			}
+			name := child.GetMaterial().Name
+			if _, ok := materials[name]; !ok {
+				materials[name] = *instanceHit{instance: i, shape: child}.GetMaterial()
+			}
+		}
+	}
+	collect(i.Prototype)
+	return materials
+}
+
+// SetPrototypeMaterial replaces the material called name in the prototype for this instance only,
+// the empty name replaces the material of shapes without a named material
+func (i *Instance) SetPrototypeMaterial(name string, m Material) {
+	if name == "" {
+		i.SetMaterial(m)
+	} else {
+		i.SetNamedMaterial(name, m)
+	}
+}
+
+func (i *Instance) GetParent() *Group {
+	return i.Parent
+}
+
+func (i *Instance) SetParent(g *Group) {
+	i.Parent = g
+}
+
+func (i *Instance) Equals(other Shape) bool {
+	if reflect.TypeOf(i) != reflect.TypeOf(other) {
+		return false
+	}
+	otherInstance := other.(*Instance)
+	return i.Transform.Equals(other.GetTransform()) &&
+		i.GetMaterial().Equals(*other.GetMaterial()) &&
+		i.Prototype == otherInstance.Prototype &&
+		i.Parent == other.GetParent()
+}
+
+// Intersect hands the ray to the prototype in the object space of the instance. The hits are
+// wrapped so that normals, materials and points are resolved through this instance.
+func (i *Instance) Intersect(ray Ray) []Intersection {
+	transformedRay := ray.Transform(InverseTransformAt(i, ray.Time))
+	xs := i.Prototype.Intersect(transformedRay)
+	for j := range xs {
+		xs[j].Object = instanceHit{instance: i, shape: xs[j].Object}
+	}
+	return xs
+}
+
+func (i *Instance) NormalAt(p math.Point, hit Intersection) math.Vector {
+	// the normal always comes from the shape of the prototype that was hit
+	return math.CreateVector(0.0, 1.0, 0.0)
+}
+
+func (i *Instance) Bounds() *Bounds {
+	return TransformedBounds(i.Prototype)
+}
+
+func (i *Instance) GetInverseTransform() math.Matrix {
+	return i.InverseTransform
+}
+
+func (i *Instance) SetMotion(m *Motion) {
+	i.Motion = m
+}
+
+func (i *Instance) GetMotion() *Motion {
+	return i.Motion
+}
+
+// CalculateInverseTransform leaves the shared prototype alone
+func (i *Instance) CalculateInverseTransform() {
+	i.InverseTransform = i.Transform.Inverse()
+	i.Motion.calculateInverses(i.Transform)
+}
+
+func (i *Instance) GetUvCoordinate(point math.Point, direction math.Vector) Texel {
+	log.Fatal("GetUvCoordinate NOP")
+	return Texel{}
+}
+
+// instanceHit is a shape of a prototype as seen through one of its instances. The prototype's
+// parent chain ends at the prototype, the instance continues it into world space.
+// It is a value so that two hits of the same shape through the same instance compare equal.
+type instanceHit struct {
+	instance *Instance
+	shape    Shape
+}
+
+func (h instanceHit) Equals(other Shape) bool {
+	otherHit, ok := other.(instanceHit)
+	return ok && h.instance == otherHit.instance && h.shape.Equals(otherHit.shape)
+}
+
+func (h instanceHit) GetId() string {
+	return h.shape.GetId()
+}
+
+func (h instanceHit) SetTransform(m math.Matrix) {
+	h.shape.SetTransform(m)
+}
+
+func (h instanceHit) GetTransform() math.Matrix {
+	return h.shape.GetTransform()
+}
+
+func (h instanceHit) SetMaterial(m Material) {
+	h.shape.SetMaterial(m)
+}
+
+func (h instanceHit) GetMaterial() *Material {
+	material := h.shape.GetMaterial()
+	if named := h.instance.NamedMaterials[material.Name]; named != nil && material.Name != "" {
+		return named
+	}
+	if h.instance.Material != nil {
+		return h.instance.Material
+	}
+	return material
+}
+
+func (h instanceHit) Intersect(ray Ray) []Intersection {
+	return h.shape.Intersect(ray)
+}
+
+func (h instanceHit) NormalAt(p math.Point, hit Intersection) math.Vector {
+	hit.Object = h.shape
+	prototypeSpace := WorldToObjectAt(h.instance, p, hit.Time)
+	prototypeNormal := h.shape.NormalAt(prototypeSpace, hit)
+	return NormalToWorldAt(h.instance, prototypeNormal, hit.Time)
+}
+
+func (h instanceHit) GetParent() *Group {
+	return h.shape.GetParent()
+}
+
+func (h instanceHit) SetParent(g *Group) {
+	h.shape.SetParent(g)
+}
+
+func (h instanceHit) Bounds() *Bounds {
+	return h.shape.Bounds()
+}
+
+func (h instanceHit) GetInverseTransform() math.Matrix {
+	return h.shape.GetInverseTransform()
+}
+
+func (h instanceHit) CalculateInverseTransform() {
+	h.shape.CalculateInverseTransform()
+}
+
+func (h instanceHit) GetUvCoordinate(point math.Point, direction math.Vector) Texel {
+	return h.shape.GetUvCoordinate(point, direction)
+}
+
+func (h instanceHit) SetMotion(m *Motion) {
+	h.shape.SetMotion(m)
+}
+
+func (h instanceHit) GetMotion() *Motion {
+	return h.shape.GetMotion()
+}
@@ -0,0 +1,143 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+// a prototype with a sphere that is moved inside of it, like a part of a model
+func spherePrototype() (*Group, *Sphere) {
+	prototype := EmptyGroup()
+	sphere := CreateSphere()
+	sphere.SetTransform(math.Translation(0.0, 2.0, 0.0))
+	prototype.AddChild(sphere)
+	prototype.CalculateInverseTransform()
+	return prototype, sphere
+}
+
+func TestInstancesShareThePrototype(t *testing.T) {
+	prototype, _ := spherePrototype()
+	left := CreateInstance(prototype)
+	left.SetTransform(math.Translation(-5.0, 0.0, 0.0))
+	left.CalculateInverseTransform()
+	right := CreateInstance(prototype)
+	right.SetTransform(math.Translation(5.0, 0.0, 0.0).MulM(math.Scaling(2.0, 2.0, 2.0)))
+	right.CalculateInverseTransform()
+
+	xs := left.Intersect(CreateRay(math.CreatePoint(-5.0, 2.0, -5.0), math.CreateVector(0.0, 0.0, 1.0)))
+	assert.Assert(t, len(xs) == 2)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 4.0))
+
+	xs = right.Intersect(CreateRay(math.CreatePoint(5.0, 4.0, -5.0), math.CreateVector(0.0, 0.0, 1.0)))
+	assert.Assert(t, len(xs) == 2)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 3.0))
+
+	// the prototype is not moved by its instances
+	xs = prototype.Intersect(CreateRay(math.CreatePoint(0.0, 2.0, -5.0), math.CreateVector(0.0, 0.0, 1.0)))
+	assert.Assert(t, len(xs) == 2)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 4.0))
+}
+
+func TestInstanceNormalThroughParentChain(t *testing.T) {
+	prototype, _ := spherePrototype()
+	instance := CreateInstance(prototype)
+	instance.SetTransform(math.Rotation_Z(gomath.Pi / 2.0))
+	outer := EmptyGroup()
+	outer.SetTransform(math.Translation(0.0, 0.0, 3.0))
+	outer.AddChild(instance)
+	outer.CalculateInverseTransform()
+
+	// the sphere ends up at (-2, 0, 3)
+	ray := CreateRay(math.CreatePoint(-2.0, 5.0, 3.0), math.CreateVector(0.0, -1.0, 0.0))
+	xs := outer.Intersect(ray)
+	assert.Assert(t, len(xs) == 2)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 4.0))
+
+	point := ray.Position(xs[0].IntersectionAt)
+	assert.Assert(t, WorldToObject(xs[0].Object, point).Equals(math.CreatePoint(1.0, 0.0, 0.0)))
+	assert.Assert(t, ObjectToWorld(xs[0].Object, math.CreatePoint(1.0, 0.0, 0.0)).Equals(point))
+	assert.Assert(t, xs[0].Object.NormalAt(point, xs[0]).Equals(math.CreateVector(0.0, 1.0, 0.0)))
+
+	point = ray.Position(xs[1].IntersectionAt)
+	assert.Assert(t, xs[1].Object.NormalAt(point, xs[1]).Equals(math.CreateVector(0.0, -1.0, 0.0)))
+}
+
+func TestInstanceMaterialOverride(t *testing.T) {
+	prototype, sphere := spherePrototype()
+	sphere.Material.Reflective = 0.5
+	plain := CreateInstance(prototype)
+	plain.CalculateInverseTransform()
+	glassMaterial := DefaultMaterial()
+	glassMaterial.SetTransparency(1.0)
+	glassMaterial.SetRefractiveIndex(1.5)
+	glass := CreateInstance(prototype)
+	glass.SetMaterial(glassMaterial)
+	glass.CalculateInverseTransform()
+	ray := CreateRay(math.CreatePoint(0.0, 2.0, -5.0), math.CreateVector(0.0, 0.0, 1.0))
+
+	xs := plain.Intersect(ray)
+	assert.Assert(t, xs[0].Object.GetMaterial().Equals(sphere.Material))
+
+	xs = glass.Intersect(ray)
+	assert.Assert(t, xs[0].Object.GetMaterial().Equals(glassMaterial))
+	assert.Assert(t, sphere.Material.Reflective == 0.5)
+}
+
+func TestInstanceHitsAreComparable(t *testing.T) {
+	prototype, _ := spherePrototype()
+	first := CreateInstance(prototype)
+	first.CalculateInverseTransform()
+	second := CreateInstance(prototype)
+	second.CalculateInverseTransform()
+	ray := CreateRay(math.CreatePoint(0.0, 2.0, -5.0), math.CreateVector(0.0, 0.0, 1.0))
+
+	firstXs := first.Intersect(ray)
+	secondXs := second.Intersect(ray)
+
+	// entry and exit of the same instance are the same object, which refraction relies on
+	assert.Assert(t, firstXs[0].Object == firstXs[1].Object)
+	assert.Assert(t, firstXs[0].Object != secondXs[0].Object)
+	assert.Assert(t, !firstXs[0].Object.Equals(secondXs[0].Object))
+	assert.Assert(t, Includes(first, firstXs[0].Object))
+	assert.Assert(t, !Includes(second, firstXs[0].Object))
+}
+
+func TestInstanceBounds(t *testing.T) {
+	prototype, _ := spherePrototype()
+	instance := CreateInstance(prototype)
+	instance.SetTransform(math.Translation(1.0, 0.0, 0.0))
+
+	outer := EmptyGroup()
+	outer.AddChild(instance)
+	b := outer.Bounds()
+
+	assert.Assert(t, b.Minimum.Equals(math.CreatePoint(0.0, 1.0, -1.0)))
+	assert.Assert(t, b.Maximum.Equals(math.CreatePoint(2.0, 3.0, 1.0)))
+}
+
+func TestInstanceNamedMaterialOverride(t *testing.T) {
+	prototype, sphere := spherePrototype()
+	sphere.Material.Name = "paint"
+	other := CreateSphere()
+	other.SetTransform(math.Translation(0.0, -2.0, 0.0))
+	prototype.AddChild(other)
+	prototype.CalculateInverseTransform()
+
+	red := DefaultMaterial()
+	red.SetColor(math.CreateColor(1.0, 0.0, 0.0))
+	blue := DefaultMaterial()
+	blue.SetColor(math.CreateColor(0.0, 0.0, 1.0))
+	instance := CreateInstance(prototype)
+	instance.SetMaterial(blue)
+	instance.SetNamedMaterial("paint", red)
+	instance.CalculateInverseTransform()
+
+	xs := instance.Intersect(CreateRay(math.CreatePoint(0.0, 2.0, -5.0), math.CreateVector(0.0, 0.0, 1.0)))
+	assert.Assert(t, xs[0].Object.GetMaterial().Equals(red))
+	xs = instance.Intersect(CreateRay(math.CreatePoint(0.0, -2.0, -5.0), math.CreateVector(0.0, 0.0, 1.0)))
+	assert.Assert(t, xs[0].Object.GetMaterial().Equals(blue))
+	assert.Assert(t, sphere.Material.Color.Equals(math.CreateColor(1.0, 1.0, 1.0)))
+}
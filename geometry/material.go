@@ -16,6 +16,7 @@ type Material struct {
 	Transparency    float64
 	RefractiveIndex float64
 	Texture         Texture
+	Name            string // of the material in the mtl file it comes from, empty otherwise
 }
 
 func CreateMaterial(c math.Color,
@@ -0,0 +1,192 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+)
+
+// number of inverse transforms that are precomputed between the start and the end of a motion
+const MOTION_STEPS = 16
+
+// below this determinant the linear part of a transform is treated as flattened, it has no rotation
+const MOTION_SINGULAR_DETERMINANT = 1e-12
+
+// Motion moves a shape while the shutter of the camera is open. The transform of the shape is its state
+// when the shutter opens (time 0), End is its state when the shutter closes (time 1).
+// Transforms in between are interpolated by their translation, rotation and stretch separately,
+// that way even large rotations keep the shape intact instead of collapsing it halfway.
+type Motion struct {
+	End      math.Matrix
+	inverses []math.Matrix // MOTION_STEPS + 1 evenly spaced inverse transforms, see calculateInverses
+}
+
+func CreateMotion(end math.Matrix) *Motion {
+	return &Motion{
+		End: end,
+	}
+}
+
+// calculateInverses precomputes the inverse transforms on the way from start to End,
+// inverting a matrix for every ray would be too expensive. Must be called before rendering.
+func (m *Motion) calculateInverses(start math.Matrix) {
+	if m == nil {
+		return
+	}
+	m.inverses = make([]math.Matrix, 0, MOTION_STEPS+1)
+	for _, tf := range m.steps(start) {
+		m.inverses = append(m.inverses, tf.Inverse())
+	}
+}
+
+// steps returns MOTION_STEPS + 1 evenly spaced transforms from start to End
+func (m *Motion) steps(start math.Matrix) []math.Matrix {
+	from := decompose(start)
+	to := decompose(m.End)
+	transforms := make([]math.Matrix, 0, MOTION_STEPS+1)
+	for step := range MOTION_STEPS + 1 {
+		transforms = append(transforms, from.interpolate(to, float64(step)/MOTION_STEPS).compose())
+	}
+	return transforms
+}
+
+// inverseAt interpolates between the two closest precomputed inverse transforms
+func (m *Motion) inverseAt(time float64) math.Matrix {
+	position := min(max(time, 0.0), 1.0) * MOTION_STEPS
+	step := min(int(position), MOTION_STEPS-1)
+	return lerpMatrix(m.inverses[step], m.inverses[step+1], position-float64(step))
+}
+
+func lerpMatrix(a math.Matrix, b math.Matrix, f float64) math.Matrix {
+	data := make([]float64, 0, 16)
+	for row := range 4 {
+		for column := range 4 {
+			data = append(data, a.Get(row, column)+(b.Get(row, column)-a.Get(row, column))*f)
+		}
+	}
+	return math.CreateMatrixFlat(data)
+}
+
+// decomposedTransform splits an affine transform into translation * rotation * stretch,
+// the stretch contains scaling and shearing
+type decomposedTransform struct {
+	translation math.Vector
+	rotation    quaternion
+	stretch     math.Matrix
+}
+
+type quaternion struct {
+	w, x, y, z float64
+}
+
+// decompose uses the polar decomposition of the linear part of m, see Shoemake and Duff, "Matrix animation and polar decomposition"
+func decompose(m math.Matrix) decomposedTransform {
+	linear := math.CreateMatrixFlat([]float64{
+		m.Get(0, 0), m.Get(0, 1), m.Get(0, 2), 0.0,
+		m.Get(1, 0), m.Get(1, 1), m.Get(1, 2), 0.0,
+		m.Get(2, 0), m.Get(2, 1), m.Get(2, 2), 0.0,
+		0.0, 0.0, 0.0, 1.0,
+	})
+	d := decomposedTransform{
+		translation: math.CreateVector(m.Get(0, 3), m.Get(1, 3), m.Get(2, 3)),
+		rotation:    quaternion{w: 1.0},
+		stretch:     linear,
+	}
+	if gomath.Abs(linear.Determinant()) < MOTION_SINGULAR_DETERMINANT {
+		return d
+	}
+
+	// averaging with the inverse transpose converges to the closest rotation
+	rotation := linear
+	for range 30 {
+		next := lerpMatrix(rotation, rotation.Inverse().Transpose(), 0.5)
+		converged := next.Equals(rotation)
+		rotation = next
+		if converged {
+			break
+		}
+	}
+	// mirroring is left in the stretch
+	if rotation.Determinant() < 0.0 {
+		rotation = math.Scaling(-1.0, -1.0, -1.0).MulM(rotation)
+	}
+
+	d.rotation = quaternionFromMatrix(rotation)
+	d.stretch = rotation.Transpose().MulM(linear)
+	return d
+}
+
+func (d decomposedTransform) interpolate(other decomposedTransform, f float64) decomposedTransform {
+	return decomposedTransform{
+		translation: d.translation.Add(other.translation.Subtract(d.translation).Mul(f)),
+		rotation:    d.rotation.slerp(other.rotation, f),
+		stretch:     lerpMatrix(d.stretch, other.stretch, f),
+	}
+}
+
+func (d decomposedTransform) compose() math.Matrix {
+	linear := d.rotation.toMatrix().MulM(d.stretch)
+	t := d.translation
+	return math.Translation(t.X, t.Y, t.Z).MulM(linear)
+}
+
+func quaternionFromMatrix(m math.Matrix) quaternion {
+	var q quaternion
+	trace := m.Get(0, 0) + m.Get(1, 1) + m.Get(2, 2)
+	switch {
+	case trace > 0.0:
+		s := 0.5 / gomath.Sqrt(trace+1.0)
+		q = quaternion{w: 0.25 / s, x: (m.Get(2, 1) - m.Get(1, 2)) * s, y: (m.Get(0, 2) - m.Get(2, 0)) * s, z: (m.Get(1, 0) - m.Get(0, 1)) * s}
+	case m.Get(0, 0) > m.Get(1, 1) && m.Get(0, 0) > m.Get(2, 2):
+		s := 2.0 * gomath.Sqrt(1.0+m.Get(0, 0)-m.Get(1, 1)-m.Get(2, 2))
+		q = quaternion{w: (m.Get(2, 1) - m.Get(1, 2)) / s, x: 0.25 * s, y: (m.Get(0, 1) + m.Get(1, 0)) / s, z: (m.Get(0, 2) + m.Get(2, 0)) / s}
+	case m.Get(1, 1) > m.Get(2, 2):
+		s := 2.0 * gomath.Sqrt(1.0+m.Get(1, 1)-m.Get(0, 0)-m.Get(2, 2))
+		q = quaternion{w: (m.Get(0, 2) - m.Get(2, 0)) / s, x: (m.Get(0, 1) + m.Get(1, 0)) / s, y: 0.25 * s, z: (m.Get(1, 2) + m.Get(2, 1)) / s}
+	default:
+		s := 2.0 * gomath.Sqrt(1.0+m.Get(2, 2)-m.Get(0, 0)-m.Get(1, 1))
+		q = quaternion{w: (m.Get(1, 0) - m.Get(0, 1)) / s, x: (m.Get(0, 2) + m.Get(2, 0)) / s, y: (m.Get(1, 2) + m.Get(2, 1)) / s, z: 0.25 * s}
+	}
+	return q.normalize()
+}
+
+func (q quaternion) dot(other quaternion) float64 {
+	return q.w*other.w + q.x*other.x + q.y*other.y + q.z*other.z
+}
+
+func (q quaternion) normalize() quaternion {
+	length := gomath.Sqrt(q.dot(q))
+	return quaternion{w: q.w / length, x: q.x / length, y: q.y / length, z: q.z / length}
+}
+
+// slerp rotates along the shorter arc from q to other
+func (q quaternion) slerp(other quaternion, f float64) quaternion {
+	cos := q.dot(other)
+	if cos < 0.0 {
+		other = quaternion{w: -other.w, x: -other.x, y: -other.y, z: -other.z}
+		cos = -cos
+	}
+	// nearly identical rotations are interpolated linearly to avoid a division by almost zero
+	a, b := 1.0-f, f
+	if cos < 1.0-math.EPSILON {
+		angle := gomath.Acos(cos)
+		sin := gomath.Sin(angle)
+		a = gomath.Sin((1.0-f)*angle) / sin
+		b = gomath.Sin(f*angle) / sin
+	}
+	return quaternion{
+		w: a*q.w + b*other.w,
+		x: a*q.x + b*other.x,
+		y: a*q.y + b*other.y,
+		z: a*q.z + b*other.z,
+	}.normalize()
+}
+
+func (q quaternion) toMatrix() math.Matrix {
+	w, x, y, z := q.w, q.x, q.y, q.z
+	return math.CreateMatrixFlat([]float64{
+		1.0 - 2.0*(y*y+z*z), 2.0 * (x*y - w*z), 2.0 * (x*z + w*y), 0.0,
+		2.0 * (x*y + w*z), 1.0 - 2.0*(x*x+z*z), 2.0 * (y*z - w*x), 0.0,
+		2.0 * (x*z - w*y), 2.0 * (y*z + w*x), 1.0 - 2.0*(x*x+y*y), 0.0,
+		0.0, 0.0, 0.0, 1.0,
+	})
+}
@@ -0,0 +1,107 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestMotionInverseAt(t *testing.T) {
+	m := CreateMotion(math.Translation(4, 0, 0))
+	m.calculateInverses(math.IdentityMatrix())
+
+	assert.Assert(t, m.inverseAt(0.0).Equals(math.IdentityMatrix()))
+	assert.Assert(t, m.inverseAt(0.3).Equals(math.Translation(-1.2, 0, 0)))
+	assert.Assert(t, m.inverseAt(1.0).Equals(math.Translation(-4, 0, 0)))
+	// the shutter interval is clamped
+	assert.Assert(t, m.inverseAt(2.0).Equals(math.Translation(-4, 0, 0)))
+}
+
+func TestMotionWithHalfTurn(t *testing.T) {
+	s := CreateSphere()
+	s.SetTransform(math.Scaling(1, 2, 1))
+	s.SetMotion(CreateMotion(math.Translation(4, 0, 0).MulM(math.Rotation_Y(gomath.Pi)).MulM(math.Scaling(1, 2, 1))))
+	s.CalculateInverseTransform()
+
+	// interpolating the matrices entry by entry would flatten the sphere halfway through the shutter,
+	// instead it is a quarter turned and halfway translated
+	inverse := s.GetMotion().inverseAt(0.5)
+	assert.Assert(t, floatEquals(inverse.Determinant(), 0.5), "%v", inverse.Determinant())
+	center := inverse.Inverse().MulT(math.CreatePoint(0, 0, 0))
+	assert.Assert(t, center.Equals(math.CreatePoint(2, 0, 0)), "%v", center)
+
+	r := CreateRay(math.CreatePoint(2, 10, 0), math.CreateVector(0, -1, 0))
+	r.Time = 0.5
+	xs := s.Intersect(r)
+	assert.Assert(t, len(xs) == 2)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 8.0))
+	assert.Assert(t, floatEquals(xs[1].IntersectionAt, 12.0))
+
+	// the bounds contain every step of the motion
+	b := TransformedBounds(s)
+	assert.Assert(t, b.Minimum.X <= -1.0 && b.Minimum.Z <= -1.0, "%v", b.Minimum)
+	assert.Assert(t, b.Maximum.X >= 5.0 && b.Maximum.Z >= 1.0, "%v", b.Maximum)
+}
+
+func TestMotionInterpolatesScaling(t *testing.T) {
+	m := CreateMotion(math.Translation(2, 0, 0).MulM(math.Scaling(3, 3, 3)))
+	m.calculateInverses(math.IdentityMatrix())
+
+	expected := math.Translation(1, 0, 0).MulM(math.Scaling(2, 2, 2)).Inverse()
+	assert.Assert(t, m.inverseAt(0.5).Equals(expected))
+}
+
+func TestIntersectMovingSphere(t *testing.T) {
+	s := CreateSphere()
+	s.SetMotion(CreateMotion(math.Translation(0, 0, 2)))
+	s.CalculateInverseTransform()
+	r := CreateRay(math.CreatePoint(0, 0, -5), math.CreateVector(0, 0, 1))
+
+	xs := s.Intersect(r)
+	assert.Assert(t, xs[0].IntersectionAt == 4.0)
+
+	r.Time = 0.5
+	xs = s.Intersect(r)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 5.0))
+
+	r.Time = 1.0
+	xs = s.Intersect(r)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 6.0))
+	comps := xs[0].PrepareComputation(r, xs)
+	assert.Assert(t, comps.Time == 1.0)
+	assert.Assert(t, comps.Normalv.Equals(math.CreateVector(0, 0, -1)))
+}
+
+func TestNormalOfMovingGroupChild(t *testing.T) {
+	g := EmptyGroup()
+	g.SetMotion(CreateMotion(math.Rotation_Y(gomath.Pi / 2)))
+	s := CreateSphere()
+	s.SetTransform(math.Translation(5, 0, 0))
+	g.AddChild(s)
+	g.CalculateInverseTransform()
+
+	// when the shutter closes the sphere has been rotated to z = -5
+	p := math.CreatePoint(0, 0, -4)
+	hit := CreateIntersection(1.0, s)
+	hit.Time = 1.0
+
+	assert.Assert(t, WorldToObjectAt(s, p, 1.0).Equals(math.CreatePoint(-1, 0, 0)))
+	assert.Assert(t, s.NormalAt(p, hit).Equals(math.CreateVector(0, 0, 1)))
+	assert.Assert(t, RestPosition(s, p, 1.0).Equals(math.CreatePoint(4, 0, 0)))
+	assert.Assert(t, IsMoving(s))
+	assert.Assert(t, !IsMoving(CreateSphere()))
+}
+
+func TestBoundsContainMotion(t *testing.T) {
+	s := CreateSphere()
+	s.SetMotion(CreateMotion(math.Translation(3, 0, 0)))
+	g := EmptyGroup()
+	g.AddChild(s)
+
+	b := g.Bounds()
+
+	assert.Assert(t, b.Minimum.Equals(math.CreatePoint(-1, -1, -1)))
+	assert.Assert(t, b.Maximum.Equals(math.CreatePoint(4, 1, 1)))
+}
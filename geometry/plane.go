@@ -15,6 +15,7 @@ type Plane struct {
 	Material         Material
 	Parent           *Group
 	InverseTransform math.Matrix
+	Motion           *Motion
 }
 
 func CreatePlane() *Plane {
@@ -63,7 +64,7 @@ func (p *Plane) SetParent(g *Group) {
 
 func (p *Plane) NormalAt(point math.Point, hit Intersection) math.Vector {
 	objectNormal := p.localPlaneNormalAt()
-	return NormalToWorld(p, objectNormal)
+	return NormalToWorldAt(p, objectNormal, hit.Time)
 }
 
 func (p *Plane) localPlaneNormalAt() math.Vector {
@@ -71,7 +72,7 @@ func (p *Plane) localPlaneNormalAt() math.Vector {
 }
 
 func (p *Plane) Intersect(ray Ray) []Intersection {
-	transformedRay := ray.Transform(p.GetInverseTransform())
+	transformedRay := ray.Transform(InverseTransformAt(p, ray.Time))
 	return p.localPlaneIntersect(transformedRay)
 }
 
@@ -98,8 +99,17 @@ func (p *Plane) GetInverseTransform() math.Matrix {
 	return p.InverseTransform
 }
 
+func (p *Plane) SetMotion(m *Motion) {
+	p.Motion = m
+}
+
+func (p *Plane) GetMotion() *Motion {
+	return p.Motion
+}
+
 func (p *Plane) CalculateInverseTransform() {
 	p.InverseTransform = p.Transform.Inverse()
+	p.Motion.calculateInverses(p.Transform)
 }
 
 func (p *Plane) GetUvCoordinate(point math.Point, direction math.Vector) Texel {
@@ -0,0 +1,121 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// Quad is the square from (-1, 0, -1) to (1, 0, 1) in the xz plane and faces up,
+// like the top of a cube. Scale it to get other rectangles.
+type Quad struct {
+	Id               string
+	Transform        math.Matrix
+	Material         Material
+	Parent           *Group
+	InverseTransform math.Matrix
+	Motion           *Motion
+}
+
+func CreateQuad() *Quad {
+	return &Quad{
+		Id:        uuid.NewString(),
+		Transform: math.IdentityMatrix(),
+		Material:  DefaultMaterial(),
+		Parent:    nil,
+	}
+}
+
+func (q *Quad) SetTransform(m math.Matrix) {
+	q.Transform = m
+}
+
+func (q *Quad) GetId() string {
+	return q.Id
+}
+
+func (q *Quad) GetTransform() math.Matrix {
+	return q.Transform
+}
+
+func (q *Quad) GetMaterial() *Material {
+	return &q.Material
+}
+
+func (q *Quad) SetMaterial(m Material) {
+	q.Material = m
+}
+
+func (q *Quad) GetParent() *Group {
+	return q.Parent
+}
+
+func (q *Quad) SetParent(g *Group) {
+	q.Parent = g
+}
+
+func (q *Quad) Equals(other Shape) bool {
+	return reflect.TypeOf(q) == reflect.TypeOf(other) &&
+		q.Transform.Equals(other.GetTransform()) &&
+		q.Material.Equals(*other.GetMaterial()) &&
+		q.Parent == other.GetParent()
+}
+
+func (q *Quad) NormalAt(point math.Point, hit Intersection) math.Vector {
+	return NormalToWorldAt(q, math.CreateVector(0.0, 1.0, 0.0), hit.Time)
+}
+
+func (q *Quad) Intersect(ray Ray) []Intersection {
+	transformedRay := ray.Transform(InverseTransformAt(q, ray.Time))
+	return q.localQuadIntersect(transformedRay)
+}
+
+func (q *Quad) localQuadIntersect(localRay Ray) []Intersection {
+	xs := make([]Intersection, 0)
+	if gomath.Abs(localRay.Direction.Y) < math.EPSILON {
+		return xs
+	}
+
+	t := -localRay.Origin.Y / localRay.Direction.Y
+	p := localRay.Position(t)
+	if gomath.Abs(p.X) > 1.0 || gomath.Abs(p.Z) > 1.0 {
+		return xs
+	}
+	xs = append(xs, CreateIntersection(t, q))
+
+	return xs
+}
+
+func (q *Quad) Bounds() *Bounds {
+	return &Bounds{
+		Minimum: math.CreatePoint(-1.0, 0.0, -1.0),
+		Maximum: math.CreatePoint(1.0, 0.0, 1.0),
+	}
+}
+
+func (q *Quad) GetInverseTransform() math.Matrix {
+	return q.InverseTransform
+}
+
+func (q *Quad) SetMotion(m *Motion) {
+	q.Motion = m
+}
+
+func (q *Quad) GetMotion() *Motion {
+	return q.Motion
+}
+
+func (q *Quad) CalculateInverseTransform() {
+	q.InverseTransform = q.Transform.Inverse()
+	q.Motion.calculateInverses(q.Transform)
+}
+
+func (q *Quad) GetUvCoordinate(point math.Point, _ math.Vector) Texel {
+	return Texel{
+		U: (point.X + 1.0) / 2.0,
+		V: (point.Z + 1.0) / 2.0,
+		F: UNDEFINED,
+	}
+}
@@ -0,0 +1,45 @@
+package geometry
+
+import (
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIntersectQuad(t *testing.T) {
+	q := CreateQuad()
+	q.SetTransform(math.Scaling(2, 1, 1))
+	q.CalculateInverseTransform()
+
+	testCases := []struct {
+		origin math.Point
+		hits   bool
+	}{
+		{math.CreatePoint(0, 1, 0), true},
+		{math.CreatePoint(1.9, 1, 0.9), true},
+		{math.CreatePoint(-2, 1, -1), true},
+		{math.CreatePoint(1.5, 1, 1.1), false},
+		{math.CreatePoint(2.1, 1, 0), false},
+	}
+
+	for _, tc := range testCases {
+		xs := q.Intersect(CreateRay(tc.origin, math.CreateVector(0, -1, 0)))
+
+		assert.Assert(t, (len(xs) == 1) == tc.hits, "%v: %v", tc.origin, xs)
+	}
+}
+
+func TestQuadNormalBoundsAndUvCoordinate(t *testing.T) {
+	q := CreateQuad()
+	q.CalculateInverseTransform()
+
+	n := q.NormalAt(math.CreatePoint(0.5, 0, 0.5), CreateIntersection(0.0, q))
+	b := q.Bounds()
+	texel := q.GetUvCoordinate(math.CreatePoint(0.5, 0, -1), math.CreateVector(0, 1, 0))
+
+	assert.Assert(t, n.Equals(math.CreateVector(0, 1, 0)))
+	assert.Assert(t, b.Minimum.Equals(math.CreatePoint(-1, 0, -1)))
+	assert.Assert(t, b.Maximum.Equals(math.CreatePoint(1, 0, 1)))
+	assert.Assert(t, floatEquals(texel.U, 0.75) && floatEquals(texel.V, 0.0), "%v", texel)
+}
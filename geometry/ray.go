@@ -10,6 +10,7 @@ import (
 type Ray struct {
 	Origin    math.Point
 	Direction math.Vector
+	Time      float64 // when the ray is cast, 0 is the opening and 1 the closing of the shutter
 }
 
 type Intersection struct {
@@ -17,6 +18,7 @@ type Intersection struct {
 	Object         Shape
 	U              float64
 	V              float64
+	Time           float64 // of the ray, set by PrepareComputation
 }
 
 type IntersectionComputations struct {
@@ -31,6 +33,7 @@ type IntersectionComputations struct {
 	UnderPoint     math.Point
 	N1             float64
 	N2             float64
+	Time           float64
 }
 
 func CreateRay(origin math.Point, direction math.Vector) Ray {
@@ -93,7 +96,7 @@ func Hit(xs []Intersection) *Intersection {
 func (r Ray) Transform(transformMatrix math.Matrix) Ray {
 	newOrigin := transformMatrix.MulT(r.Origin)
 	newDirection := transformMatrix.MulT(r.Direction)
-	return CreateRay(newOrigin, newDirection)
+	return Ray{Origin: newOrigin, Direction: newDirection, Time: r.Time}
 }
 
 func SortIntersections(xs []Intersection) {
@@ -104,6 +107,8 @@ func SortIntersections(xs []Intersection) {
 
 func (i Intersection) PrepareComputation(r Ray, xs []Intersection) IntersectionComputations {
 	p := r.Position(i.IntersectionAt)
+	// moving shapes need the time of the ray to find their normal
+	i.Time = r.Time
 	normal := i.Object.NormalAt(p, i)
 	eye := r.Direction.Negate()
 
@@ -131,6 +136,7 @@ func (i Intersection) PrepareComputation(r Ray, xs []Intersection) IntersectionC
 		Reflectv:       reflect,
 		N1:             n1,
 		N2:             n2,
+		Time:           r.Time,
 	}
 }
 
@@ -0,0 +1,197 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// upper limit of distance evaluations per ray, rays that need more miss the rest of the surface
+	SDF_MAX_STEPS = 1000
+	// close to the surface the steps get tiny, this keeps the march going until it crosses
+	SDF_MIN_STEP = 1e-4
+	// bisection steps to locate a crossing of the surface
+	SDF_REFINE_STEPS = 32
+	// offset of the samples for the gradient
+	SDF_NORMAL_DELTA = 1e-6
+)
+
+// SDF is an implicit surface described by a signed distance field, it is intersected by sphere tracing
+type SDF struct {
+	Id               string
+	Transform        math.Matrix
+	Material         Material
+	Root             SdfNode
+	Parent           *Group
+	InverseTransform math.Matrix
+	Motion           *Motion
+	bounds           *Bounds
+}
+
+func CreateSDF(root SdfNode) *SDF {
+	return &SDF{
+		Id:        uuid.NewString(),
+		Transform: math.IdentityMatrix(),
+		Material:  DefaultMaterial(),
+		Root:      root,
+		Parent:    nil,
+		bounds:    root.Bounds(),
+	}
+}
+
+func (s *SDF) SetTransform(m math.Matrix) {
+	s.Transform = m
+}
+
+func (s *SDF) GetId() string {
+	return s.Id
+}
+
+func (s *SDF) GetTransform() math.Matrix {
+	return s.Transform
+}
+
+func (s *SDF) GetMaterial() *Material {
+	return &s.Material
+}
+
+func (s *SDF) SetMaterial(m Material) {
+	s.Material = m
+}
+
+func (s *SDF) GetParent() *Group {
+	return s.Parent
+}
+
+func (s *SDF) SetParent(g *Group) {
+	s.Parent = g
+}
+
+func (s *SDF) Equals(other Shape) bool {
+	if reflect.TypeOf(s) != reflect.TypeOf(other) {
+		return false
+	}
+	otherSdf := other.(*SDF)
+	return s.Transform.Equals(other.GetTransform()) &&
+		s.Material.Equals(*other.GetMaterial()) &&
+		reflect.DeepEqual(s.Root, otherSdf.Root) &&
+		s.Parent == other.GetParent()
+}
+
+func (s *SDF) Intersect(ray Ray) []Intersection {
+	transformedRay := ray.Transform(InverseTransformAt(s, ray.Time))
+	return s.localSdfIntersect(transformedRay)
+}
+
+// localSdfIntersect marches along the ray through the bounds of the field. Every step is as long as the distance
+// to the surface, so it cannot jump over it. Crossings of the surface are refined by bisection.
+func (s *SDF) localSdfIntersect(ray Ray) []Intersection {
+	xs := make([]Intersection, 0)
+	tmin, tmax := branchlessCheck(ray, s.bounds.Minimum, s.bounds.Maximum)
+	if tmax < tmin || gomath.IsInf(tmin, 0) || gomath.IsNaN(tmin) {
+		return xs
+	}
+
+	// march in units of length along the normalized direction
+	length := ray.Direction.Magnitude()
+	start := ray.Position(tmin)
+	dx, dy, dz := ray.Direction.X/length, ray.Direction.Y/length, ray.Direction.Z/length
+	distanceAt := func(u float64) float64 {
+		return s.Root.Distance(math.CreatePoint(start.X+dx*u, start.Y+dy*u, start.Z+dz*u))
+	}
+	end := (tmax - tmin) * length
+
+	// starting or ending inside means the bounds cut the field, they close the surface
+	// so that every entry has an exit
+	u := 0.0
+	distance := distanceAt(u)
+	if distance < 0.0 {
+		xs = append(xs, CreateIntersection(tmin, s))
+	}
+	for range SDF_MAX_STEPS {
+		if u >= end {
+			break
+		}
+		next := gomath.Min(u+gomath.Max(gomath.Abs(distance), SDF_MIN_STEP), end)
+		nextDistance := distanceAt(next)
+		if (distance < 0.0) != (nextDistance < 0.0) {
+			crossing := refineCrossing(distanceAt, u, next, distance < 0.0)
+			xs = append(xs, CreateIntersection(tmin+crossing/length, s))
+		}
+		u, distance = next, nextDistance
+	}
+	if distance < 0.0 {
+		xs = append(xs, CreateIntersection(tmin+u/length, s))
+	}
+
+	return xs
+}
+
+// refineCrossing bisects the interval from low to high that contains the surface
+func refineCrossing(distanceAt func(float64) float64, low float64, high float64, lowInside bool) float64 {
+	for range SDF_REFINE_STEPS {
+		mid := (low + high) / 2.0
+		if (distanceAt(mid) < 0.0) == lowInside {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2.0
+}
+
+func (s *SDF) NormalAt(point math.Point, hit Intersection) math.Vector {
+	objectSpace := WorldToObjectAt(s, point, hit.Time)
+	objectNormal := s.localSdfNormalAt(objectSpace)
+	return NormalToWorldAt(s, objectNormal, hit.Time)
+}
+
+// localSdfNormalAt is the gradient of the field from central differences
+func (s *SDF) localSdfNormalAt(p math.Point) math.Vector {
+	h := SDF_NORMAL_DELTA
+	gradient := math.CreateVector(
+		s.Root.Distance(math.CreatePoint(p.X+h, p.Y, p.Z))-s.Root.Distance(math.CreatePoint(p.X-h, p.Y, p.Z)),
+		s.Root.Distance(math.CreatePoint(p.X, p.Y+h, p.Z))-s.Root.Distance(math.CreatePoint(p.X, p.Y-h, p.Z)),
+		s.Root.Distance(math.CreatePoint(p.X, p.Y, p.Z+h))-s.Root.Distance(math.CreatePoint(p.X, p.Y, p.Z-h)))
+	return gradient.Normalize()
+}
+
+func (s *SDF) Bounds() *Bounds {
+	return s.bounds
+}
+
+func (s *SDF) GetInverseTransform() math.Matrix {
+	return s.InverseTransform
+}
+
+func (s *SDF) SetMotion(m *Motion) {
+	s.Motion = m
+}
+
+func (s *SDF) GetMotion() *Motion {
+	return s.Motion
+}
+
+func (s *SDF) CalculateInverseTransform() {
+	s.InverseTransform = s.Transform.Inverse()
+	s.Motion.calculateInverses(s.Transform)
+}
+
+// GetUvCoordinate projects the texture spherically from the center of the bounds,
+// u is the angle around the y axis and v the angle above the xz plane
+func (s *SDF) GetUvCoordinate(point math.Point, _ math.Vector) Texel {
+	center := s.bounds.Minimum.Add(s.bounds.Maximum).Div(2.0)
+	d := point.Subtract(center)
+	radius := d.Magnitude()
+	if radius == 0.0 {
+		return Texel{U: 0.5, V: 0.5, F: UNDEFINED}
+	}
+	return Texel{
+		U: 0.5 - gomath.Atan2(d.Z, d.X)/(2.0*gomath.Pi),
+		V: 0.5 + gomath.Asin(d.Y/radius)/gomath.Pi,
+		F: UNDEFINED,
+	}
+}
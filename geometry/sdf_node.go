@@ -0,0 +1,300 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+)
+
+// SdfNode is a node of a signed distance field expression tree. Distance is negative inside of the surface and
+// must not be larger than the distance to the surface, which makes it a safe step size for sphere tracing.
+type SdfNode interface {
+	Distance(p math.Point) float64
+	Bounds() *Bounds
+}
+
+type SdfSphere struct {
+	Center math.Point
+	Radius float64
+}
+
+func CreateSdfSphere(center math.Point, radius float64) *SdfSphere {
+	return &SdfSphere{Center: center, Radius: radius}
+}
+
+func (s *SdfSphere) Distance(p math.Point) float64 {
+	return length3(p.X-s.Center.X, p.Y-s.Center.Y, p.Z-s.Center.Z) - s.Radius
+}
+
+func (s *SdfSphere) Bounds() *Bounds {
+	return &Bounds{
+		Minimum: math.CreatePoint(s.Center.X-s.Radius, s.Center.Y-s.Radius, s.Center.Z-s.Radius),
+		Maximum: math.CreatePoint(s.Center.X+s.Radius, s.Center.Y+s.Radius, s.Center.Z+s.Radius),
+	}
+}
+
+// SdfBox is an axis aligned box that extends HalfSize from its Center in every direction
+type SdfBox struct {
+	Center   math.Point
+	HalfSize math.Vector
+}
+
+func CreateSdfBox(center math.Point, halfSize math.Vector) *SdfBox {
+	return &SdfBox{Center: center, HalfSize: halfSize}
+}
+
+func (b *SdfBox) Distance(p math.Point) float64 {
+	qx := gomath.Abs(p.X-b.Center.X) - b.HalfSize.X
+	qy := gomath.Abs(p.Y-b.Center.Y) - b.HalfSize.Y
+	qz := gomath.Abs(p.Z-b.Center.Z) - b.HalfSize.Z
+	outside := length3(gomath.Max(qx, 0.0), gomath.Max(qy, 0.0), gomath.Max(qz, 0.0))
+	inside := gomath.Min(gomath.Max(qx, gomath.Max(qy, qz)), 0.0)
+	return outside + inside
+}
+
+func (b *SdfBox) Bounds() *Bounds {
+	return &Bounds{
+		Minimum: math.CreatePoint(b.Center.X-b.HalfSize.X, b.Center.Y-b.HalfSize.Y, b.Center.Z-b.HalfSize.Z),
+		Maximum: math.CreatePoint(b.Center.X+b.HalfSize.X, b.Center.Y+b.HalfSize.Y, b.Center.Z+b.HalfSize.Z),
+	}
+}
+
+// SdfTorus lies in the xz plane like Torus
+type SdfTorus struct {
+	Center      math.Point
+	MajorRadius float64
+	MinorRadius float64
+}
+
+func CreateSdfTorus(center math.Point, majorRadius float64, minorRadius float64) *SdfTorus {
+	return &SdfTorus{Center: center, MajorRadius: majorRadius, MinorRadius: minorRadius}
+}
+
+func (t *SdfTorus) Distance(p math.Point) float64 {
+	x, y, z := p.X-t.Center.X, p.Y-t.Center.Y, p.Z-t.Center.Z
+	return gomath.Hypot(gomath.Hypot(x, z)-t.MajorRadius, y) - t.MinorRadius
+}
+
+func (t *SdfTorus) Bounds() *Bounds {
+	outer := t.MajorRadius + t.MinorRadius
+	return &Bounds{
+		Minimum: math.CreatePoint(t.Center.X-outer, t.Center.Y-t.MinorRadius, t.Center.Z-outer),
+		Maximum: math.CreatePoint(t.Center.X+outer, t.Center.Y+t.MinorRadius, t.Center.Z+outer),
+	}
+}
+
+// SdfCapsule contains every point that is at most Radius away from the line segment between A and B
+type SdfCapsule struct {
+	A      math.Point
+	B      math.Point
+	Radius float64
+}
+
+func CreateSdfCapsule(a math.Point, b math.Point, radius float64) *SdfCapsule {
+	return &SdfCapsule{A: a, B: b, Radius: radius}
+}
+
+func (c *SdfCapsule) Distance(p math.Point) float64 {
+	px, py, pz := p.X-c.A.X, p.Y-c.A.Y, p.Z-c.A.Z
+	bx, by, bz := c.B.X-c.A.X, c.B.Y-c.A.Y, c.B.Z-c.A.Z
+	h := 0.0
+	if segment := bx*bx + by*by + bz*bz; segment > 0.0 {
+		h = clamp((px*bx+py*by+pz*bz)/segment, 0.0, 1.0)
+	}
+	return length3(px-bx*h, py-by*h, pz-bz*h) - c.Radius
+}
+
+func (c *SdfCapsule) Bounds() *Bounds {
+	return &Bounds{
+		Minimum: math.CreatePoint(
+			gomath.Min(c.A.X, c.B.X)-c.Radius,
+			gomath.Min(c.A.Y, c.B.Y)-c.Radius,
+			gomath.Min(c.A.Z, c.B.Z)-c.Radius),
+		Maximum: math.CreatePoint(
+			gomath.Max(c.A.X, c.B.X)+c.Radius,
+			gomath.Max(c.A.Y, c.B.Y)+c.Radius,
+			gomath.Max(c.A.Z, c.B.Z)+c.Radius),
+	}
+}
+
+type SdfOperation int
+
+const (
+	SDF_UNION SdfOperation = iota
+	SDF_SUBTRACTION
+	SDF_INTERSECTION
+)
+
+// SdfCombination blends Left and Right over a distance of Smoothness, with a Smoothness of 0
+// it has hard edges like CSG. A subtraction removes Right from Left.
+type SdfCombination struct {
+	Operation  SdfOperation
+	Left       SdfNode
+	Right      SdfNode
+	Smoothness float64
+}
+
+func CreateSdfCombination(op SdfOperation, left SdfNode, right SdfNode, smoothness float64) *SdfCombination {
+	return &SdfCombination{Operation: op, Left: left, Right: right, Smoothness: smoothness}
+}
+
+func (c *SdfCombination) Distance(p math.Point) float64 {
+	a, b := c.Left.Distance(p), c.Right.Distance(p)
+	k := c.Smoothness
+
+	// polynomial smooth minimum and maximum, see https://iquilezles.org/articles/smin/
+	switch c.Operation {
+	case SDF_UNION:
+		if k <= 0.0 {
+			return gomath.Min(a, b)
+		}
+		h := clamp(0.5+0.5*(b-a)/k, 0.0, 1.0)
+		return mix(b, a, h) - k*h*(1.0-h)
+	case SDF_SUBTRACTION:
+		if k <= 0.0 {
+			return gomath.Max(a, -b)
+		}
+		h := clamp(0.5-0.5*(a+b)/k, 0.0, 1.0)
+		return mix(a, -b, h) + k*h*(1.0-h)
+	case SDF_INTERSECTION:
+		if k <= 0.0 {
+			return gomath.Max(a, b)
+		}
+		h := clamp(0.5-0.5*(b-a)/k, 0.0, 1.0)
+		return mix(b, a, h) + k*h*(1.0-h)
+	}
+	return gomath.Inf(1)
+}
+
+func (c *SdfCombination) Bounds() *Bounds {
+	left := c.Left.Bounds()
+	switch c.Operation {
+	case SDF_UNION:
+		// the smooth minimum is at most a quarter of the smoothness below the minimum
+		b := FindMinimalContainingBoundingBox([]*Bounds{left, c.Right.Bounds()})
+		pad := c.Smoothness / 4.0
+		return &Bounds{
+			Minimum: math.CreatePoint(b.Minimum.X-pad, b.Minimum.Y-pad, b.Minimum.Z-pad),
+			Maximum: math.CreatePoint(b.Maximum.X+pad, b.Maximum.Y+pad, b.Maximum.Z+pad),
+		}
+	case SDF_INTERSECTION:
+		right := c.Right.Bounds()
+		return &Bounds{
+			Minimum: math.CreatePoint(
+				gomath.Max(left.Minimum.X, right.Minimum.X),
+				gomath.Max(left.Minimum.Y, right.Minimum.Y),
+				gomath.Max(left.Minimum.Z, right.Minimum.Z)),
+			Maximum: math.CreatePoint(
+				gomath.Min(left.Maximum.X, right.Maximum.X),
+				gomath.Min(left.Maximum.Y, right.Maximum.Y),
+				gomath.Min(left.Maximum.Z, right.Maximum.Z)),
+		}
+	}
+	return left
+}
+
+// SdfRepetition places Count copies of Child along each axis, Spacing apart, starting at the original.
+// The distance is only correct if the child fits into a cell of Spacing around its center.
+type SdfRepetition struct {
+	Child   SdfNode
+	Spacing math.Vector
+	Count   [3]int
+	// center of the child, the cells are centered on the copies
+	center math.Point
+}
+
+func CreateSdfRepetition(child SdfNode, spacing math.Vector, count [3]int) *SdfRepetition {
+	b := child.Bounds()
+	return &SdfRepetition{
+		Child:   child,
+		Spacing: spacing,
+		Count:   count,
+		center: math.CreatePoint(
+			(b.Minimum.X+b.Maximum.X)/2.0,
+			(b.Minimum.Y+b.Maximum.Y)/2.0,
+			(b.Minimum.Z+b.Maximum.Z)/2.0),
+	}
+}
+
+func (r *SdfRepetition) Distance(p math.Point) float64 {
+	q := math.CreatePoint(
+		repeatAxis(p.X, r.center.X, r.Spacing.X, r.Count[0]),
+		repeatAxis(p.Y, r.center.Y, r.Spacing.Y, r.Count[1]),
+		repeatAxis(p.Z, r.center.Z, r.Spacing.Z, r.Count[2]))
+	return r.Child.Distance(q)
+}
+
+// repeatAxis moves x into the cell of the original from the cell of the closest copy
+func repeatAxis(x float64, center float64, spacing float64, count int) float64 {
+	if count <= 1 || spacing <= 0.0 {
+		return x
+	}
+	return x - spacing*clamp(gomath.Round((x-center)/spacing), 0.0, float64(count-1))
+}
+
+func (r *SdfRepetition) Bounds() *Bounds {
+	b := r.Child.Bounds()
+	extent := func(spacing float64, count int) float64 {
+		if count <= 1 {
+			return 0.0
+		}
+		return spacing * float64(count-1)
+	}
+	return &Bounds{
+		Minimum: b.Minimum,
+		Maximum: math.CreatePoint(
+			b.Maximum.X+extent(r.Spacing.X, r.Count[0]),
+			b.Maximum.Y+extent(r.Spacing.Y, r.Count[1]),
+			b.Maximum.Z+extent(r.Spacing.Z, r.Count[2])),
+	}
+}
+
+// SdfTwist rotates Child around the y axis by Rate radians per unit of height
+type SdfTwist struct {
+	Child SdfNode
+	Rate  float64
+	// radius of the child around the y axis
+	radius float64
+	// twisting stretches space, the distance of the child is scaled down by this to stay a safe step
+	lipschitz float64
+}
+
+func CreateSdfTwist(child SdfNode, rate float64) *SdfTwist {
+	radius := 0.0
+	for _, c := range CalculateBBCorners(*child.Bounds()) {
+		radius = gomath.Max(radius, gomath.Hypot(c.X, c.Z))
+	}
+	// largest singular value of a shear by rate * radius
+	shear := gomath.Abs(rate) * radius
+	return &SdfTwist{
+		Child:     child,
+		Rate:      rate,
+		radius:    radius,
+		lipschitz: (shear + gomath.Sqrt(shear*shear+4.0)) / 2.0,
+	}
+}
+
+func (t *SdfTwist) Distance(p math.Point) float64 {
+	sin, cos := gomath.Sincos(-t.Rate * p.Y)
+	q := math.CreatePoint(cos*p.X+sin*p.Z, p.Y, -sin*p.X+cos*p.Z)
+	return t.Child.Distance(q) / t.lipschitz
+}
+
+func (t *SdfTwist) Bounds() *Bounds {
+	b := t.Child.Bounds()
+	return &Bounds{
+		Minimum: math.CreatePoint(-t.radius, b.Minimum.Y, -t.radius),
+		Maximum: math.CreatePoint(t.radius, b.Maximum.Y, t.radius),
+	}
+}
+
+func length3(x float64, y float64, z float64) float64 {
+	return gomath.Sqrt(x*x + y*y + z*z)
+}
+
+func mix(a float64, b float64, h float64) float64 {
+	return a + (b-a)*h
+}
+
+func clamp(x float64, low float64, high float64) float64 {
+	return gomath.Max(low, gomath.Min(high, x))
+}
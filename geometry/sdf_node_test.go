@@ -0,0 +1,93 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSdfPrimitiveDistances(t *testing.T) {
+	testCases := []struct {
+		node     SdfNode
+		point    math.Point
+		expected float64
+	}{
+		{CreateSdfSphere(math.CreatePoint(1, 0, 0), 1), math.CreatePoint(4, 0, 0), 2},
+		{CreateSdfSphere(math.CreatePoint(1, 0, 0), 1), math.CreatePoint(1, 0, 0), -1},
+		{CreateSdfBox(math.CreatePoint(0, 0, 0), math.CreateVector(1, 2, 3)), math.CreatePoint(4, 6, 3), 5},
+		{CreateSdfBox(math.CreatePoint(0, 0, 0), math.CreateVector(1, 2, 3)), math.CreatePoint(0.5, 0, 0), -0.5},
+		{CreateSdfTorus(math.CreatePoint(0, 0, 0), 1, 0.25), math.CreatePoint(0, 1, 1), 0.75},
+		{CreateSdfTorus(math.CreatePoint(0, 0, 0), 1, 0.25), math.CreatePoint(-1, 0, 0), -0.25},
+		{CreateSdfCapsule(math.CreatePoint(0, 0, 0), math.CreatePoint(0, 2, 0), 0.5), math.CreatePoint(1, 1, 0), 0.5},
+		{CreateSdfCapsule(math.CreatePoint(0, 0, 0), math.CreatePoint(0, 2, 0), 0.5), math.CreatePoint(0, 3, 0), 0.5},
+		{CreateSdfCapsule(math.CreatePoint(0, 1, 0), math.CreatePoint(0, 1, 0), 0.5), math.CreatePoint(0, 1, 0), -0.5},
+	}
+
+	for i, tc := range testCases {
+		d := tc.node.Distance(tc.point)
+		assert.Assert(t, floatEquals(d, tc.expected), "%v: %v", i, d)
+	}
+}
+
+func TestSdfCombinations(t *testing.T) {
+	a := CreateSdfSphere(math.CreatePoint(-1, 0, 0), 1.5)
+	b := CreateSdfSphere(math.CreatePoint(1, 0, 0), 1.5)
+	p := math.CreatePoint(0, 2, 0)
+	// both spheres are sqrt(5) - 1.5 away from p
+	d := gomath.Sqrt(5) - 1.5
+
+	assert.Assert(t, floatEquals(CreateSdfCombination(SDF_UNION, a, b, 0).Distance(p), d))
+	assert.Assert(t, floatEquals(CreateSdfCombination(SDF_INTERSECTION, a, b, 0).Distance(p), d))
+	assert.Assert(t, floatEquals(CreateSdfCombination(SDF_SUBTRACTION, a, b, 0).Distance(math.CreatePoint(1, 0, 0)), 1.5))
+	// blending adds a quarter of the smoothness where both are equally far away
+	assert.Assert(t, floatEquals(CreateSdfCombination(SDF_UNION, a, b, 0.4).Distance(p), d-0.1))
+	assert.Assert(t, floatEquals(CreateSdfCombination(SDF_INTERSECTION, a, b, 0.4).Distance(p), d+0.1))
+	// far apart the blend has no effect
+	assert.Assert(t, floatEquals(CreateSdfCombination(SDF_UNION, a, b, 0.4).Distance(math.CreatePoint(-5, 0, 0)), 2.5))
+
+	union := CreateSdfCombination(SDF_UNION, a, b, 0.4).Bounds()
+	assert.Assert(t, union.Minimum.Equals(math.CreatePoint(-2.6, -1.6, -1.6)))
+	assert.Assert(t, union.Maximum.Equals(math.CreatePoint(2.6, 1.6, 1.6)))
+	intersection := CreateSdfCombination(SDF_INTERSECTION, a, b, 0).Bounds()
+	assert.Assert(t, intersection.Minimum.Equals(math.CreatePoint(-0.5, -1.5, -1.5)))
+	assert.Assert(t, intersection.Maximum.Equals(math.CreatePoint(0.5, 1.5, 1.5)))
+}
+
+func TestSdfRepetition(t *testing.T) {
+	r := CreateSdfRepetition(CreateSdfSphere(math.CreatePoint(0, 0, 0), 0.5), math.CreateVector(2, 0, 0), [3]int{3, 1, 1})
+
+	assert.Assert(t, floatEquals(r.Distance(math.CreatePoint(4, 0, 0)), -0.5))
+	assert.Assert(t, floatEquals(r.Distance(math.CreatePoint(3, 1, 0)), gomath.Sqrt(2)-0.5))
+	// only three copies
+	assert.Assert(t, floatEquals(r.Distance(math.CreatePoint(8, 0, 0)), 3.5))
+	assert.Assert(t, floatEquals(r.Distance(math.CreatePoint(-2, 0, 0)), 1.5))
+
+	// the cells are centered on the copies of a child that is not at the origin
+	offset := CreateSdfRepetition(CreateSdfSphere(math.CreatePoint(-0.4, 0, 0), 0.1), math.CreateVector(0.4, 0, 0), [3]int{3, 1, 1})
+	assert.Assert(t, floatEquals(offset.Distance(math.CreatePoint(0, 0, 0)), -0.1))
+	assert.Assert(t, floatEquals(offset.Distance(math.CreatePoint(0.4, 0, 0)), -0.1))
+	assert.Assert(t, floatEquals(offset.Distance(math.CreatePoint(0.7, 0, 0)), 0.2))
+
+	b := r.Bounds()
+	assert.Assert(t, b.Minimum.Equals(math.CreatePoint(-0.5, -0.5, -0.5)))
+	assert.Assert(t, b.Maximum.Equals(math.CreatePoint(4.5, 0.5, 0.5)))
+}
+
+func TestSdfTwist(t *testing.T) {
+	box := CreateSdfBox(math.CreatePoint(0, 0, 0), math.CreateVector(1, 2, 0.1))
+	twist := CreateSdfTwist(box, gomath.Pi/4)
+
+	// at y = 2 the box is rotated by 90 degrees
+	assert.Assert(t, twist.Distance(math.CreatePoint(0, 1.9, 0.9)) < 0.0)
+	assert.Assert(t, twist.Distance(math.CreatePoint(0.9, 1.9, 0)) > 0.0)
+	assert.Assert(t, twist.Distance(math.CreatePoint(0.9, 0, 0)) < 0.0)
+	// the distance is never more than the distance to the twisted surface
+	assert.Assert(t, twist.Distance(math.CreatePoint(3, 0, 0)) <= 2.0)
+
+	b := twist.Bounds()
+	radius := gomath.Hypot(1, 0.1)
+	assert.Assert(t, b.Minimum.Equals(math.CreatePoint(-radius, -2, -radius)))
+	assert.Assert(t, b.Maximum.Equals(math.CreatePoint(radius, 2, radius)))
+}
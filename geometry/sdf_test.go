@@ -0,0 +1,111 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIntersectSdf(t *testing.T) {
+	s := CreateSDF(CreateSdfSphere(math.CreatePoint(0, 0, 0), 1))
+	s.CalculateInverseTransform()
+
+	testCases := []struct {
+		origin    math.Point
+		direction math.Vector
+		expected  []float64
+	}{
+		{math.CreatePoint(0, 0, -5), math.CreateVector(0, 0, 1), []float64{4, 6}},
+		{math.CreatePoint(0, 0.5, -5), math.CreateVector(0, 0, 2), []float64{(5 - gomath.Sqrt(0.75)) / 2, (5 + gomath.Sqrt(0.75)) / 2}},
+		{math.CreatePoint(0, 2, -5), math.CreateVector(0, 0, 1), []float64{}},
+		{math.CreatePoint(0, 0, 0), math.CreateVector(0, 0, 1), []float64{-1, 1}},
+		{math.CreatePoint(0, 0, 5), math.CreateVector(0, 0, 1), []float64{-6, -4}},
+	}
+
+	for _, tc := range testCases {
+		xs := s.Intersect(CreateRay(tc.origin, tc.direction))
+
+		assert.Assert(t, len(xs) == len(tc.expected), "%v: %v", tc.origin, xs)
+		for i, expected := range tc.expected {
+			assert.Assert(t, floatEquals(xs[i].IntersectionAt, expected), "%v: %v", tc.origin, xs[i].IntersectionAt)
+		}
+	}
+}
+
+func TestIntersectSdfClippedByBounds(t *testing.T) {
+	// the intersection of a box with a thinner box gets closed by the bounds of the field
+	box := CreateSdfBox(math.CreatePoint(0, 0, 0), math.CreateVector(1, 1, 1))
+	s := CreateSDF(CreateSdfCombination(SDF_INTERSECTION, box, box, 0))
+	s.CalculateInverseTransform()
+
+	xs := s.Intersect(CreateRay(math.CreatePoint(0, 0, -5), math.CreateVector(0, 0, 1)))
+
+	assert.Assert(t, len(xs) == 2, "%v", xs)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt, 4))
+	assert.Assert(t, floatEquals(xs[1].IntersectionAt, 6))
+}
+
+func TestSdfNormal(t *testing.T) {
+	s := CreateSDF(CreateSdfBox(math.CreatePoint(0, 0, 0), math.CreateVector(1, 1, 1)))
+	s.SetTransform(math.Scaling(2, 2, 2))
+	s.CalculateInverseTransform()
+
+	testCases := []struct {
+		point    math.Point
+		expected math.Vector
+	}{
+		{math.CreatePoint(2, 0.5, 0.3), math.CreateVector(1, 0, 0)},
+		{math.CreatePoint(0.4, -2, 0), math.CreateVector(0, -1, 0)},
+		{math.CreatePoint(0, 1, 2), math.CreateVector(0, 0, 1)},
+	}
+
+	for _, tc := range testCases {
+		n := s.NormalAt(tc.point, CreateIntersection(0.0, s))
+		assert.Assert(t, n.Equals(tc.expected), "%v: %v", tc.point, n)
+	}
+
+	sphere := CreateSDF(CreateSdfSphere(math.CreatePoint(0, 0, 0), 1))
+	sphere.CalculateInverseTransform()
+	p := math.CreatePoint(gomath.Sqrt(3)/3, gomath.Sqrt(3)/3, gomath.Sqrt(3)/3)
+	n := sphere.NormalAt(p, CreateIntersection(0.0, sphere))
+	assert.Assert(t, n.Equals(math.CreateVector(p.X, p.Y, p.Z)), "%v", n)
+}
+
+func TestSdfUvCoordinate(t *testing.T) {
+	// the bounds of the sphere are centered at (0, 1, 0)
+	s := CreateSDF(CreateSdfSphere(math.CreatePoint(0, 1, 0), 1))
+	testCases := []struct {
+		point math.Point
+		u     float64
+		v     float64
+	}{
+		{math.CreatePoint(1, 1, 0), 0.5, 0.5},
+		{math.CreatePoint(0, 1, 1), 0.25, 0.5},
+		{math.CreatePoint(-1, 1, 0), 0.0, 0.5},
+		{math.CreatePoint(0, 2, 0), 0.5, 1.0},
+		{math.CreatePoint(0, 0, 0), 0.5, 0.0},
+	}
+
+	for _, tc := range testCases {
+		texel := s.GetUvCoordinate(tc.point, math.CreateVector(0, 0, 0))
+		assert.Assert(t, floatEquals(texel.U, tc.u) && floatEquals(texel.V, tc.v), "%v: %v", tc.point, texel)
+	}
+}
+
+func TestIntersectSmoothUnion(t *testing.T) {
+	// the blend fills the gap between two spheres that do not touch
+	a := CreateSdfSphere(math.CreatePoint(-1.1, 0, 0), 1)
+	b := CreateSdfSphere(math.CreatePoint(1.1, 0, 0), 1)
+	hard := CreateSDF(CreateSdfCombination(SDF_UNION, a, b, 0))
+	hard.CalculateInverseTransform()
+	smooth := CreateSDF(CreateSdfCombination(SDF_UNION, a, b, 0.5))
+	smooth.CalculateInverseTransform()
+	r := CreateRay(math.CreatePoint(0, 5, 0), math.CreateVector(0, -1, 0))
+
+	assert.Assert(t, len(hard.Intersect(r)) == 0)
+	xs := smooth.Intersect(r)
+	assert.Assert(t, len(xs) == 2, "%v", xs)
+	assert.Assert(t, floatEquals(xs[0].IntersectionAt+xs[1].IntersectionAt, 10))
+}
@@ -19,6 +19,8 @@ type Shape interface {
 	GetInverseTransform() math.Matrix
 	CalculateInverseTransform()
 	GetUvCoordinate(point math.Point, direction math.Vector) Texel
+	SetMotion(m *Motion)
+	GetMotion() *Motion // nil if the shape doesn't move while the shutter is open
 }
 
 func GetCenter(s Shape) math.Point {
@@ -27,19 +29,86 @@ func GetCenter(s Shape) math.Point {
 }
 
 func WorldToObject(s Shape, p math.Point) math.Point {
+	return WorldToObjectAt(s, p, 0.0)
+}
+
+// WorldToObjectAt converts p to object space at time in the shutter interval
+func WorldToObjectAt(s Shape, p math.Point, time float64) math.Point {
+	if h, ok := s.(instanceHit); ok {
+		return WorldToObjectAt(h.shape, WorldToObjectAt(h.instance, p, time), time)
+	}
 	if s.GetParent() != nil {
-		p = WorldToObject(s.GetParent(), p)
+		p = WorldToObjectAt(s.GetParent(), p, time)
 	}
-	return s.GetInverseTransform().MulT(p)
+	return InverseTransformAt(s, time).MulT(p)
 }
 
 func NormalToWorld(s Shape, normal math.Vector) math.Vector {
-	normal = s.GetInverseTransform().Transpose().MulT(normal)
+	return NormalToWorldAt(s, normal, 0.0)
+}
+
+// NormalToWorldAt converts the object space normal to world space at time in the shutter interval
+func NormalToWorldAt(s Shape, normal math.Vector, time float64) math.Vector {
+	if h, ok := s.(instanceHit); ok {
+		return NormalToWorldAt(h.instance, NormalToWorldAt(h.shape, normal, time), time)
+	}
+	normal = InverseTransformAt(s, time).Transpose().MulT(normal)
 	normal.W = 0
 	normal = normal.Normalize()
 
 	if s.GetParent() != nil {
-		normal = NormalToWorld(s.GetParent(), normal)
+		normal = NormalToWorldAt(s.GetParent(), normal, time)
 	}
 	return normal
 }
+
+// ObjectToWorld converts the object space point p to world space
+func ObjectToWorld(s Shape, p math.Point) math.Point {
+	if h, ok := s.(instanceHit); ok {
+		return ObjectToWorld(h.instance, ObjectToWorld(h.shape, p))
+	}
+	p = s.GetTransform().MulT(p)
+	if s.GetParent() != nil {
+		p = ObjectToWorld(s.GetParent(), p)
+	}
+	return p
+}
+
+// InverseTransformAt returns the inverse transform of s at time in the shutter interval
+func InverseTransformAt(s Shape, time float64) math.Matrix {
+	if m := s.GetMotion(); m != nil && time > 0.0 {
+		return m.inverseAt(time)
+	}
+	return s.GetInverseTransform()
+}
+
+// IsMoving reports whether s or any of its groups moves while the shutter is open
+func IsMoving(s Shape) bool {
+	if h, ok := s.(instanceHit); ok {
+		return IsMoving(h.shape) || IsMoving(h.instance)
+	}
+	if s.GetMotion() != nil {
+		return true
+	}
+	return s.GetParent() != nil && IsMoving(s.GetParent())
+}
+
+// RestPosition maps the world space point p on s at time back to where it was when the shutter opened.
+// Patterns and textures are looked up there, so that they move along with the shape.
+func RestPosition(s Shape, p math.Point, time float64) math.Point {
+	return ObjectToWorld(s, WorldToObjectAt(s, p, time))
+}
+
+// TransformedBounds returns the bounds of s in the space of its parent, they contain the whole motion of s
+func TransformedBounds(s Shape) *Bounds {
+	b := s.Bounds().ApplyTransform(s.GetTransform())
+	if m := s.GetMotion(); m != nil {
+		// rotations can leave the bounds of the start and the end, so every step of the motion is included
+		boxes := []*Bounds{b}
+		for _, tf := range m.steps(s.GetTransform()) {
+			boxes = append(boxes, s.Bounds().ApplyTransform(tf))
+		}
+		b = FindMinimalContainingBoundingBox(boxes)
+	}
+	return b
+}
@@ -14,6 +14,7 @@ type Sphere struct {
 	Material         Material
 	Parent           *Group
 	InverseTransform math.Matrix
+	Motion           *Motion
 }
 
 func CreateSphere() *Sphere {
@@ -74,7 +75,7 @@ func (s *Sphere) SetParent(g *Group) {
 func (sphere *Sphere) Intersect(ray Ray) []Intersection {
 	// the vector from the sphere's center, to the ray origin
 	// remember: the sphere is centered at the world origin
-	transformedRay := ray.Transform(sphere.GetInverseTransform())
+	transformedRay := ray.Transform(InverseTransformAt(sphere, ray.Time))
 	sphereToRay := transformedRay.Origin.Subtract(math.CreatePoint(0.0, 0.0, 0.0))
 
 	a := transformedRay.Direction.Dot(transformedRay.Direction)
@@ -94,9 +95,9 @@ func (sphere *Sphere) Intersect(ray Ray) []Intersection {
 }
 
 func (s *Sphere) NormalAt(p math.Point, hit Intersection) math.Vector {
-	objectSpace := WorldToObject(s, p)
+	objectSpace := WorldToObjectAt(s, p, hit.Time)
 	objectNormal := objectSpace.Subtract(math.CreatePoint(0.0, 0.0, 0.0))
-	return NormalToWorld(s, objectNormal)
+	return NormalToWorldAt(s, objectNormal, hit.Time)
 }
 
 func (s *Sphere) Bounds() *Bounds {
@@ -110,8 +111,17 @@ func (s *Sphere) GetInverseTransform() math.Matrix {
 	return s.InverseTransform
 }
 
+func (s *Sphere) SetMotion(m *Motion) {
+	s.Motion = m
+}
+
+func (s *Sphere) GetMotion() *Motion {
+	return s.Motion
+}
+
 func (s *Sphere) CalculateInverseTransform() {
 	s.InverseTransform = s.Transform.Inverse()
+	s.Motion.calculateInverses(s.Transform)
 }
 
 func (s *Sphere) GetUvCoordinate(_ math.Point, direction math.Vector) Texel {
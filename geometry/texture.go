@@ -64,17 +64,25 @@ func (f Face) Invert() Face {
 	return UNDEFINED
 }
 
-func (t *Texture) InitTexture(directory string) {
-	actualFile := fmt.Sprintf("%v%v", directory, t.File)
-	fileReader, err := os.Open(actualFile)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer fileReader.Close()
+// TextureError is returned when the image of a texture cannot be loaded
+type TextureError struct {
+	File string
+	Err  error
+}
 
-	i, _, err := image.Decode(fileReader)
+func (e *TextureError) Error() string {
+	return fmt.Sprintf("cannot load texture '%v': %v", e.File, e.Err)
+}
+
+func (e *TextureError) Unwrap() error {
+	return e.Err
+}
+
+func (t *Texture) InitTexture(directory string) error {
+	actualFile := fmt.Sprintf("%v%v", directory, t.File)
+	i, err := loadImage(actualFile)
 	if err != nil {
-		log.Fatal(err)
+		return &TextureError{File: actualFile, Err: err}
 	}
 
 	t.Data = &i
@@ -82,6 +90,19 @@ func (t *Texture) InitTexture(directory string) {
 	if t.Cubemap {
 		t.initCubeMapInfo()
 	}
+	return nil
+}
+
+// loadImage decodes a png or jpeg file
+func loadImage(file string) (image.Image, error) {
+	fileReader, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fileReader.Close()
+
+	i, _, err := image.Decode(fileReader)
+	return i, err
 }
 
 func (t *Texture) initCubeMapInfo() {
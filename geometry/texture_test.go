@@ -0,0 +1,34 @@
+package geometry
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestInitTextureMissingFile(t *testing.T) {
+	texture := Texture{File: "missing.png"}
+
+	err := texture.InitTexture(t.TempDir() + "/")
+
+	var textureErr *TextureError
+	assert.Assert(t, errors.As(err, &textureErr))
+	assert.Assert(t, errors.Is(err, fs.ErrNotExist))
+	assert.Assert(t, texture.Data == nil)
+}
+
+func TestInitTextureInvalidImage(t *testing.T) {
+	directory := t.TempDir() + "/"
+	assert.NilError(t, os.WriteFile(filepath.Join(directory, "broken.png"), []byte("no image"), 0644))
+	texture := Texture{File: "broken.png"}
+
+	err := texture.InitTexture(directory)
+
+	var textureErr *TextureError
+	assert.Assert(t, errors.As(err, &textureErr))
+	assert.Assert(t, textureErr.File == directory+"broken.png")
+}
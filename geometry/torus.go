@@ -0,0 +1,166 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"reflect"
+
+	"github.com/google/uuid"
+)
+
+// Torus lies in the xz plane around the origin. The center of the tube is MajorRadius away
+// from the origin, the tube itself has a radius of MinorRadius.
+type Torus struct {
+	Id               string
+	Transform        math.Matrix
+	Material         Material
+	MajorRadius      float64
+	MinorRadius      float64
+	Parent           *Group
+	InverseTransform math.Matrix
+	Motion           *Motion
+}
+
+func CreateTorus() *Torus {
+	return &Torus{
+		Id:          uuid.NewString(),
+		Transform:   math.IdentityMatrix(),
+		Material:    DefaultMaterial(),
+		MajorRadius: 1.0,
+		MinorRadius: 0.25,
+		Parent:      nil,
+	}
+}
+
+func (t *Torus) SetTransform(m math.Matrix) {
+	t.Transform = m
+}
+
+func (t *Torus) GetId() string {
+	return t.Id
+}
+
+func (t *Torus) GetTransform() math.Matrix {
+	return t.Transform
+}
+
+func (t *Torus) GetMaterial() *Material {
+	return &t.Material
+}
+
+func (t *Torus) SetMaterial(m Material) {
+	t.Material = m
+}
+
+func (t *Torus) GetParent() *Group {
+	return t.Parent
+}
+
+func (t *Torus) SetParent(g *Group) {
+	t.Parent = g
+}
+
+func (t *Torus) Equals(other Shape) bool {
+	if reflect.TypeOf(t) != reflect.TypeOf(other) {
+		return false
+	}
+	otherTorus := other.(*Torus)
+	return t.Transform.Equals(other.GetTransform()) &&
+		t.Material.Equals(*other.GetMaterial()) &&
+		t.MajorRadius == otherTorus.MajorRadius &&
+		t.MinorRadius == otherTorus.MinorRadius &&
+		t.Parent == other.GetParent()
+}
+
+func (t *Torus) Intersect(ray Ray) []Intersection {
+	transformedRay := ray.Transform(InverseTransformAt(t, ray.Time))
+	return t.localTorusIntersect(transformedRay)
+}
+
+// localTorusIntersect solves (|p|^2 + R^2 - r^2)^2 = 4R^2 (x^2 + z^2) for the points p on the ray
+func (t *Torus) localTorusIntersect(ray Ray) []Intersection {
+	xs := make([]Intersection, 0)
+
+	// the coefficients of the quartic grow with the distance to the torus and lose precision,
+	// the ray starts where it enters the bounds of the torus and is normalized instead
+	b := t.Bounds()
+	tmin, tmax := branchlessCheck(ray, b.Minimum, b.Maximum)
+	if tmax < tmin || gomath.IsInf(tmin, 0) || gomath.IsNaN(tmin) {
+		return xs
+	}
+	length := ray.Direction.Magnitude()
+	start := ray.Position(tmin)
+	ox, oy, oz := start.X, start.Y, start.Z
+	dx, dy, dz := ray.Direction.X/length, ray.Direction.Y/length, ray.Direction.Z/length
+
+	major2 := t.MajorRadius * t.MajorRadius
+	minor2 := t.MinorRadius * t.MinorRadius
+	f := ox*dx + oy*dy + oz*dz
+	e := ox*ox + oy*oy + oz*oz - major2 - minor2
+
+	roots := math.SolveQuartic(
+		1.0,
+		4.0*f,
+		2.0*e+4.0*f*f+4.0*major2*dy*dy,
+		4.0*f*e+8.0*major2*oy*dy,
+		e*e-4.0*major2*(minor2-oy*oy))
+
+	for _, s := range roots {
+		xs = append(xs, CreateIntersection(tmin+s/length, t))
+	}
+	return xs
+}
+
+func (t *Torus) NormalAt(point math.Point, hit Intersection) math.Vector {
+	objectSpace := WorldToObjectAt(t, point, hit.Time)
+	objectNormal := t.localTorusNormalAt(objectSpace)
+	return NormalToWorldAt(t, objectNormal, hit.Time)
+}
+
+// localTorusNormalAt points away from the closest point on the center circle of the tube
+func (t *Torus) localTorusNormalAt(point math.Point) math.Vector {
+	distance := gomath.Hypot(point.X, point.Z)
+	if distance == 0.0 {
+		return math.CreateVector(0.0, gomath.Copysign(1.0, point.Y), 0.0)
+	}
+	scale := t.MajorRadius / distance
+	return math.CreateVector(point.X-point.X*scale, point.Y, point.Z-point.Z*scale)
+}
+
+func (t *Torus) Bounds() *Bounds {
+	outer := t.MajorRadius + t.MinorRadius
+	return &Bounds{
+		Minimum: math.CreatePoint(-outer, -t.MinorRadius, -outer),
+		Maximum: math.CreatePoint(outer, t.MinorRadius, outer),
+	}
+}
+
+func (t *Torus) GetInverseTransform() math.Matrix {
+	return t.InverseTransform
+}
+
+func (t *Torus) SetMotion(m *Motion) {
+	t.Motion = m
+}
+
+func (t *Torus) GetMotion() *Motion {
+	return t.Motion
+}
+
+func (t *Torus) CalculateInverseTransform() {
+	t.InverseTransform = t.Transform.Inverse()
+	t.Motion.calculateInverses(t.Transform)
+}
+
+// GetUvCoordinate maps the angle around the y axis to u and the angle around the tube to v,
+// v = 0 and v = 1 are on the inside of the ring
+func (t *Torus) GetUvCoordinate(point math.Point, _ math.Vector) Texel {
+	u := 0.5 - gomath.Atan2(point.Z, point.X)/(2.0*gomath.Pi)
+	tubeAngle := gomath.Atan2(point.Y, gomath.Hypot(point.X, point.Z)-t.MajorRadius)
+	v := 0.5 + tubeAngle/(2.0*gomath.Pi)
+	return Texel{
+		U: u,
+		V: v,
+		F: UNDEFINED,
+	}
+}
@@ -0,0 +1,110 @@
+package geometry
+
+import (
+	gomath "math"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestIntersectTorus(t *testing.T) {
+	torus := CreateTorus()
+	torus.CalculateInverseTransform()
+
+	testCases := []struct {
+		origin    math.Point
+		direction math.Vector
+		expected  []float64
+	}{
+		// through both sides of the ring
+		{math.CreatePoint(-5, 0, 0), math.CreateVector(1, 0, 0), []float64{3.75, 4.25, 5.75, 6.25}},
+		// through the hole
+		{math.CreatePoint(0, 5, 0), math.CreateVector(0, -1, 0), []float64{}},
+		// from above onto the tube
+		{math.CreatePoint(1, 5, 0), math.CreateVector(0, -1, 0), []float64{4.75, 5.25}},
+		// from inside of the tube
+		{math.CreatePoint(0, 0, 1), math.CreateVector(0, 0, 1), []float64{-2.25, -1.75, -0.25, 0.25}},
+		// above the torus
+		{math.CreatePoint(-5, 0.3, 0), math.CreateVector(1, 0, 0), []float64{}},
+		// unnormalized direction
+		{math.CreatePoint(-5, 0, 0), math.CreateVector(2, 0, 0), []float64{1.875, 2.125, 2.875, 3.125}},
+	}
+
+	for _, tc := range testCases {
+		xs := torus.Intersect(CreateRay(tc.origin, tc.direction))
+
+		assert.Assert(t, len(xs) == len(tc.expected), "%v: %v", tc.origin, xs)
+		for i, expected := range tc.expected {
+			assert.Assert(t, floatEquals(xs[i].IntersectionAt, expected), "%v: %v", tc.origin, xs[i].IntersectionAt)
+		}
+	}
+}
+
+func TestIntersectDistantTorus(t *testing.T) {
+	// the quartic would lose precision without moving the ray close to the torus first
+	torus := CreateTorus()
+	torus.MinorRadius = 0.01
+	torus.SetTransform(math.Translation(10000, 0, 0))
+	torus.CalculateInverseTransform()
+	r := CreateRay(math.CreatePoint(0, 0, 0), math.CreateVector(1, 0, 0))
+
+	xs := torus.Intersect(r)
+
+	assert.Assert(t, len(xs) == 4, "%v", xs)
+	for i, expected := range []float64{9998.99, 9999.01, 10000.99, 10001.01} {
+		assert.Assert(t, gomath.Abs(xs[i].IntersectionAt-expected) < 1e-6, "%v", xs[i].IntersectionAt)
+	}
+}
+
+func TestTorusNormal(t *testing.T) {
+	torus := CreateTorus()
+	torus.SetTransform(math.Scaling(2, 2, 2))
+	torus.CalculateInverseTransform()
+
+	testCases := []struct {
+		point    math.Point
+		expected math.Vector
+	}{
+		{math.CreatePoint(2.5, 0, 0), math.CreateVector(1, 0, 0)},
+		{math.CreatePoint(1.5, 0, 0), math.CreateVector(-1, 0, 0)},
+		{math.CreatePoint(0, 0.5, 2), math.CreateVector(0, 1, 0)},
+		{math.CreatePoint(0, 0, -2.5), math.CreateVector(0, 0, -1)},
+	}
+
+	for _, tc := range testCases {
+		n := torus.NormalAt(tc.point, CreateIntersection(0.0, torus))
+		assert.Assert(t, n.Equals(tc.expected), "%v: %v", tc.point, n)
+	}
+}
+
+func TestTorusBounds(t *testing.T) {
+	torus := CreateTorus()
+	torus.MajorRadius = 2.0
+	torus.MinorRadius = 0.5
+
+	b := torus.Bounds()
+
+	assert.Assert(t, b.Minimum.Equals(math.CreatePoint(-2.5, -0.5, -2.5)))
+	assert.Assert(t, b.Maximum.Equals(math.CreatePoint(2.5, 0.5, 2.5)))
+}
+
+func TestTorusUvCoordinate(t *testing.T) {
+	torus := CreateTorus()
+	testCases := []struct {
+		point math.Point
+		u     float64
+		v     float64
+	}{
+		{math.CreatePoint(1.25, 0, 0), 0.5, 0.5},
+		{math.CreatePoint(1, 0.25, 0), 0.5, 0.75},
+		{math.CreatePoint(1, -0.25, 0), 0.5, 0.25},
+		{math.CreatePoint(0, 0, 1.25), 0.25, 0.5},
+		{math.CreatePoint(-1.25, 0, 0), 0.0, 0.5},
+	}
+
+	for _, tc := range testCases {
+		texel := torus.GetUvCoordinate(tc.point, math.CreateVector(0, 0, 0))
+		assert.Assert(t, floatEquals(texel.U, tc.u) && floatEquals(texel.V, tc.v), "%v: %v", tc.point, texel)
+	}
+}
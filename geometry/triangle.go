@@ -1,7 +1,6 @@
 package geometry
 
 import (
-	"log"
 	gomath "math"
 	"raygo/math"
 	"reflect"
@@ -22,6 +21,7 @@ type Triangle struct {
 	Parent           *Group
 	CachedBounds     *Bounds
 	InverseTransform math.Matrix
+	Motion           *Motion
 	// smooth triangle fields
 	N1     math.Vector
 	N2     math.Vector
@@ -141,9 +141,9 @@ func (t *Triangle) GetMaterial() *Material {
 
 func (t *Triangle) NormalAt(p math.Point, hit Intersection) math.Vector {
 	if t.Smooth {
-		return t.localTriangleNormalAt(hit).Normalize()
+		return NormalToWorldAt(t, t.localTriangleNormalAt(hit), hit.Time)
 	}
-	return t.Normal
+	return NormalToWorldAt(t, t.Normal, hit.Time)
 }
 
 func (t *Triangle) localTriangleNormalAt(hit Intersection) math.Vector {
@@ -182,7 +182,7 @@ func (t *Triangle) Bounds() *Bounds {
 }
 
 func (t *Triangle) Intersect(ray Ray) []Intersection {
-	tInverse := t.GetInverseTransform()
+	tInverse := InverseTransformAt(t, ray.Time)
 	localRay := ray.Transform(tInverse)
 	return t.localIntersect(localRay)
 }
@@ -224,11 +224,39 @@ func (t *Triangle) GetInverseTransform() math.Matrix {
 	return t.InverseTransform
 }
 
+func (t *Triangle) SetMotion(m *Motion) {
+	t.Motion = m
+}
+
+func (t *Triangle) GetMotion() *Motion {
+	return t.Motion
+}
+
 func (t *Triangle) CalculateInverseTransform() {
 	t.InverseTransform = t.Transform.Inverse()
+	t.Motion.calculateInverses(t.Transform)
 }
 
+// GetUvCoordinate interpolates the texture coordinates of the corners at the barycentric coordinates
+// of point. Coordinates outside of 0..1 repeat the texture.
 func (t *Triangle) GetUvCoordinate(point math.Point, direction math.Vector) Texel {
-	log.Fatal("GetUvCoordinate NOP")
-	return Texel{}
+	if !t.HasTexture {
+		return Texel{U: 0.0, V: 0.0, F: UNDEFINED}
+	}
+	w := point.Subtract(t.P1)
+	d00, d01, d11 := t.E1.Dot(t.E1), t.E1.Dot(t.E2), t.E2.Dot(t.E2)
+	d20, d21 := w.Dot(t.E1), w.Dot(t.E2)
+	denominator := d00*d11 - d01*d01
+	b1 := (d11*d20 - d01*d21) / denominator
+	b2 := (d00*d21 - d01*d20) / denominator
+	b0 := 1.0 - b1 - b2
+
+	u := b0*t.T1.X + b1*t.T2.X + b2*t.T3.X
+	v := b0*t.T1.Y + b1*t.T2.Y + b2*t.T3.Y
+	// v of obj files goes up from the bottom of the image
+	return Texel{
+		U: u - gomath.Floor(u),
+		V: gomath.Ceil(v) - v,
+		F: UNDEFINED,
+	}
 }
@@ -35,6 +35,7 @@ func TestNormalAt(t *testing.T) {
 	p3 := p(0.5, 0.25, 0.0)
 
 	tri := CreateTriangle(p(0.0, 1.0, 0.0), p(-1.0, 0.0, 0.0), p(1.0, 0.0, 0.0))
+	tri.CalculateInverseTransform()
 
 	assert.Assert(t, tri.Normal.Equals(tri.NormalAt(p1, Intersection{})))
 	assert.Assert(t, tri.Normal.Equals(tri.NormalAt(p2, Intersection{})))
@@ -98,6 +99,7 @@ func TestSmoothTriangleIntersectionUV(t *testing.T) {
 
 func TestSmoothTriangleInterpolatedNormal(t *testing.T) {
 	tri := DefaultSmoothTriangle()
+	tri.CalculateInverseTransform()
 	i := CreateIntersectionWithUV(1.0, tri, 0.45, 0.25)
 	n := tri.NormalAt(p(0.0, 0.0, 0.0), i)
 	fmt.Println(n)
@@ -123,3 +125,17 @@ func TestRandomEmptyInverseBug(t *testing.T) {
 	inv2 := tri.GetInverseTransform()
 	assert.Assert(t, inv2.Equals(math.IdentityMatrix()))
 }
+
+func TestTriangleUvCoordinate(t *testing.T) {
+	tri := CreateTriangle(p(0.0, 0.0, 0.0), p(2.0, 0.0, 0.0), p(0.0, 2.0, 0.0))
+	tri.AddTextureInformation(p(0.0, 0.0, 0.0), p(1.0, 0.0, 0.0), p(0.0, 2.0, 0.0))
+
+	corner := tri.GetUvCoordinate(p(0.0, 0.0, 0.0), v(0.0, 0.0, 1.0))
+	inside := tri.GetUvCoordinate(p(1.0, 0.5, 0.0), v(0.0, 0.0, 1.0))
+	repeated := tri.GetUvCoordinate(p(0.0, 1.5, 0.0), v(0.0, 0.0, 1.0))
+
+	// v is flipped because the rows of images go down
+	assert.Assert(t, floatEquals(corner.U, 0.0) && floatEquals(corner.V, 0.0))
+	assert.Assert(t, floatEquals(inside.U, 0.5) && floatEquals(inside.V, 0.5))
+	assert.Assert(t, floatEquals(repeated.U, 0.0) && floatEquals(repeated.V, 0.5))
+}
@@ -13,7 +13,7 @@ func TestHexagonCorner(t *testing.T) {
 
 	expected := geometry.Bounds{
 		Minimum: math.CreatePoint(-0.25, -0.25, -1.25),
-		Maximum: math.CreatePoint(0.25, 0.25, 0.0),
+		Maximum: math.CreatePoint(0.25, 0.25, -0.75),
 	}
 
 	actual := corner.Bounds().ApplyTransform(corner.Transform)
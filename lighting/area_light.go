@@ -0,0 +1,100 @@
+package lighting
+
+import (
+	gomath "math"
+	"math/rand/v2"
+	"raygo/math"
+	"reflect"
+)
+
+type AreaLight struct {
+	Position  math.Point // center of the light
+	Intensity math.Color
+	Corner    math.Point
+	Uvec      math.Vector // edge of a single cell in u direction
+	Usteps    int
+	Vvec      math.Vector // edge of a single cell in v direction
+	Vsteps    int
+	Jitter    bool
+	Seed      uint64 // jittered points only depend on Seed, the lit point and the cell
+}
+
+// fullUvec and fullVvec are the two edges of the rectangle starting at corner
+func CreateAreaLight(corner math.Point,
+	fullUvec math.Vector,
+	usteps int,
+	fullVvec math.Vector,
+	vsteps int,
+	intensity math.Color) *AreaLight {
+	center := corner.Add(fullUvec.Div(2.0)).Add(fullVvec.Div(2.0))
+	return &AreaLight{
+		Position:  center,
+		Intensity: intensity,
+		Corner:    corner,
+		Uvec:      fullUvec.Div(float64(usteps)),
+		Usteps:    usteps,
+		Vvec:      fullVvec.Div(float64(vsteps)),
+		Vsteps:    vsteps,
+		Jitter:    false,
+	}
+}
+
+func (l *AreaLight) GetIntensity() math.Color {
+	return l.Intensity
+}
+
+func (l *AreaLight) Attenuation(p math.Point) float64 {
+	return 1.0
+}
+
+// Samples returns one sample per cell of the light
+func (l *AreaLight) Samples(p math.Point) []LightSample {
+	samples := make([]LightSample, 0, l.Usteps*l.Vsteps)
+	for v := range l.Vsteps {
+		for u := range l.Usteps {
+			samples = append(samples, createSample(p, l.PointOnLight(p, u, v)))
+		}
+	}
+	return samples
+}
+
+// PointOnLight returns a point inside the cell (u, v) of the light as seen from p.
+// Without jitter this is always the center of the cell, with jitter the point is random
+// but the same for every render with the same seed.
+func (l *AreaLight) PointOnLight(p math.Point, u int, v int) math.Point {
+	uOffset, vOffset := 0.5, 0.5
+	if l.Jitter {
+		rng := rand.New(rand.NewPCG(l.Seed^hashPoint(p), uint64(u)<<32|uint64(uint32(v))))
+		uOffset = rng.Float64()
+		vOffset = rng.Float64()
+	}
+
+	return l.Corner.
+		Add(l.Uvec.Mul(float64(u) + uOffset)).
+		Add(l.Vvec.Mul(float64(v) + vOffset))
+}
+
+func (l *AreaLight) Equals(other Light) bool {
+	if reflect.TypeOf(l) == reflect.TypeOf(other) {
+		otherLight := other.(*AreaLight)
+		return l.Intensity.Equals(otherLight.Intensity) &&
+			l.Corner.Equals(otherLight.Corner) &&
+			l.Uvec.Equals(otherLight.Uvec) &&
+			l.Vvec.Equals(otherLight.Vvec) &&
+			l.Usteps == otherLight.Usteps &&
+			l.Vsteps == otherLight.Vsteps &&
+			l.Jitter == otherLight.Jitter &&
+			l.Seed == otherLight.Seed
+	}
+	return false
+}
+
+// hashPoint combines the bits of the coordinates of p, FNV-1a style
+func hashPoint(p math.Point) uint64 {
+	hash := uint64(14695981039346656037)
+	for _, c := range []float64{p.X, p.Y, p.Z} {
+		hash ^= gomath.Float64bits(c)
+		hash *= 1099511628211
+	}
+	return hash
+}
@@ -0,0 +1,45 @@
+package lighting
+
+import (
+	gomath "math"
+	"raygo/math"
+	"reflect"
+)
+
+// DirectionalLight is infinitely far away, all of its rays are parallel
+type DirectionalLight struct {
+	Direction math.Vector // direction the light travels in
+	Intensity math.Color
+}
+
+func CreateDirectionalLight(direction math.Vector, intensity math.Color) *DirectionalLight {
+	return &DirectionalLight{
+		Direction: direction.Normalize(),
+		Intensity: intensity,
+	}
+}
+
+func (l *DirectionalLight) GetIntensity() math.Color {
+	return l.Intensity
+}
+
+func (l *DirectionalLight) Attenuation(p math.Point) float64 {
+	return 1.0
+}
+
+func (l *DirectionalLight) Samples(p math.Point) []LightSample {
+	sample := LightSample{
+		Direction: l.Direction.Negate(),
+		Distance:  gomath.Inf(1),
+	}
+	return []LightSample{sample}
+}
+
+func (l *DirectionalLight) Equals(other Light) bool {
+	if reflect.TypeOf(l) == reflect.TypeOf(other) {
+		otherLight := other.(*DirectionalLight)
+		return l.Direction.Equals(otherLight.Direction) &&
+			l.Intensity.Equals(otherLight.Intensity)
+	}
+	return false
+}
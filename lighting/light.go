@@ -2,21 +2,61 @@ package lighting
 
 import (
 	"raygo/math"
+	"reflect"
 )
 
-type Light struct {
+type Light interface {
+	GetIntensity() math.Color
+	// Attenuation is the factor in [0, 1] that scales the light arriving at p,
+	// independent of any occluding objects
+	Attenuation(p math.Point) float64
+	// Samples returns the directions from p towards every sampled point of the light
+	Samples(p math.Point) []LightSample
+	Equals(other Light) bool
+}
+
+type LightSample struct {
+	Direction math.Vector // normalized, pointing from the surface towards the light
+	Distance  float64     // distance to the light, infinite for directional lights
+}
+
+type PointLight struct {
 	Position  math.Point
 	Intensity math.Color
 }
 
-func CreateLight(p math.Point, intensity math.Color) Light {
-	return Light{
+func CreateLight(p math.Point, intensity math.Color) *PointLight {
+	return &PointLight{
 		Position:  p,
 		Intensity: intensity,
 	}
 }
 
-func (l Light) Equals(other Light) bool {
-	return l.Position.Equals(other.Position) &&
-		l.Intensity.Equals(other.Intensity)
+func (l *PointLight) GetIntensity() math.Color {
+	return l.Intensity
+}
+
+func (l *PointLight) Attenuation(p math.Point) float64 {
+	return 1.0
+}
+
+func (l *PointLight) Samples(p math.Point) []LightSample {
+	return []LightSample{createSample(p, l.Position)}
+}
+
+func (l *PointLight) Equals(other Light) bool {
+	if reflect.TypeOf(l) == reflect.TypeOf(other) {
+		otherLight := other.(*PointLight)
+		return l.Position.Equals(otherLight.Position) &&
+			l.Intensity.Equals(otherLight.Intensity)
+	}
+	return false
+}
+
+func createSample(from math.Point, lightPoint math.Point) LightSample {
+	v := lightPoint.Subtract(from)
+	return LightSample{
+		Direction: v.Normalize(),
+		Distance:  v.Magnitude(),
+	}
 }
@@ -1,6 +1,7 @@
 package lighting
 
 import (
+	gomath "math"
 	"raygo/math"
 	"testing"
 
@@ -16,3 +17,114 @@ func TestCreateLight(t *testing.T) {
 	assert.Assert(t, p.Equals(light.Position))
 	assert.Assert(t, intensity.Equals(light.Intensity))
 }
+
+func TestCreateAreaLight(t *testing.T) {
+	corner := math.CreatePoint(0.0, 0.0, 0.0)
+	v1 := math.CreateVector(2.0, 0.0, 0.0)
+	v2 := math.CreateVector(0.0, 0.0, 1.0)
+
+	light := CreateAreaLight(corner, v1, 4, v2, 2, math.CreateColor(1.0, 1.0, 1.0))
+
+	assert.Assert(t, corner.Equals(light.Corner))
+	assert.Assert(t, math.CreateVector(0.5, 0.0, 0.0).Equals(light.Uvec))
+	assert.Assert(t, light.Usteps == 4)
+	assert.Assert(t, math.CreateVector(0.0, 0.0, 0.5).Equals(light.Vvec))
+	assert.Assert(t, light.Vsteps == 2)
+	assert.Assert(t, len(light.Samples(math.CreatePoint(0.0, 5.0, 0.0))) == 8)
+	assert.Assert(t, math.CreatePoint(1.0, 0.0, 0.5).Equals(light.Position))
+}
+
+func TestPointOnAreaLight(t *testing.T) {
+	corner := math.CreatePoint(0.0, 0.0, 0.0)
+	v1 := math.CreateVector(2.0, 0.0, 0.0)
+	v2 := math.CreateVector(0.0, 0.0, 1.0)
+	light := CreateAreaLight(corner, v1, 4, v2, 2, math.CreateColor(1.0, 1.0, 1.0))
+	p := math.CreatePoint(0.0, 5.0, 0.0)
+
+	assert.Assert(t, math.CreatePoint(0.25, 0.0, 0.25).Equals(light.PointOnLight(p, 0, 0)))
+	assert.Assert(t, math.CreatePoint(0.75, 0.0, 0.25).Equals(light.PointOnLight(p, 1, 0)))
+	assert.Assert(t, math.CreatePoint(0.25, 0.0, 0.75).Equals(light.PointOnLight(p, 0, 1)))
+	assert.Assert(t, math.CreatePoint(1.25, 0.0, 0.25).Equals(light.PointOnLight(p, 2, 0)))
+	assert.Assert(t, math.CreatePoint(1.75, 0.0, 0.75).Equals(light.PointOnLight(p, 3, 1)))
+}
+
+func TestPointOnJitteredAreaLightStaysInCell(t *testing.T) {
+	corner := math.CreatePoint(0.0, 0.0, 0.0)
+	v1 := math.CreateVector(2.0, 0.0, 0.0)
+	v2 := math.CreateVector(0.0, 0.0, 1.0)
+	light := CreateAreaLight(corner, v1, 4, v2, 2, math.CreateColor(1.0, 1.0, 1.0))
+	light.Jitter = true
+
+	for i := range 100 {
+		p := light.PointOnLight(math.CreatePoint(float64(i), 5.0, 0.0), 2, 1)
+		assert.Assert(t, p.X >= 1.0 && p.X <= 1.5)
+		assert.Assert(t, p.Z >= 0.5 && p.Z <= 1.0)
+	}
+}
+
+func TestPointOnJitteredAreaLightIsDeterministic(t *testing.T) {
+	corner := math.CreatePoint(0.0, 0.0, 0.0)
+	v1 := math.CreateVector(2.0, 0.0, 0.0)
+	v2 := math.CreateVector(0.0, 0.0, 1.0)
+	light := CreateAreaLight(corner, v1, 4, v2, 2, math.CreateColor(1.0, 1.0, 1.0))
+	light.Jitter = true
+	light.Seed = 7
+	p := math.CreatePoint(1.0, 5.0, 2.0)
+
+	first := light.PointOnLight(p, 2, 1)
+	assert.Assert(t, first.Equals(light.PointOnLight(p, 2, 1)))
+	assert.Assert(t, !first.Equals(light.PointOnLight(math.CreatePoint(1.0, 5.0, 3.0), 2, 1)))
+
+	light.Seed = 8
+	assert.Assert(t, !first.Equals(light.PointOnLight(p, 2, 1)))
+}
+
+func TestPointLightSamples(t *testing.T) {
+	p := math.CreatePoint(1.0, 2.0, 3.0)
+	light := CreateLight(p, math.CreateColor(1.0, 1.0, 1.0))
+
+	samples := light.Samples(math.CreatePoint(1.0, 2.0, 1.0))
+
+	assert.Assert(t, len(samples) == 1)
+	assert.Assert(t, math.CreateVector(0.0, 0.0, 1.0).Equals(samples[0].Direction))
+	assert.Assert(t, samples[0].Distance == 2.0)
+}
+
+func TestDirectionalLightSamples(t *testing.T) {
+	light := CreateDirectionalLight(math.CreateVector(0.0, -2.0, 0.0), math.CreateColor(1.0, 1.0, 1.0))
+
+	s1 := light.Samples(math.CreatePoint(0.0, 0.0, 0.0))
+	s2 := light.Samples(math.CreatePoint(100.0, -5.0, 3.0))
+
+	assert.Assert(t, len(s1) == 1)
+	assert.Assert(t, math.CreateVector(0.0, 1.0, 0.0).Equals(s1[0].Direction))
+	assert.Assert(t, s1[0].Direction.Equals(s2[0].Direction))
+	assert.Assert(t, gomath.IsInf(s1[0].Distance, 1))
+}
+
+func TestSpotLightAttenuation(t *testing.T) {
+	light := CreateSpotLight(math.CreatePoint(0.0, 10.0, 0.0),
+		math.CreateVector(0.0, -1.0, 0.0),
+		math.Radians(20.0),
+		math.Radians(30.0),
+		math.CreateColor(1.0, 1.0, 1.0))
+
+	// straight below the light
+	assert.Assert(t, light.Attenuation(math.CreatePoint(0.0, 0.0, 0.0)) == 1.0)
+	// 45 degrees off axis
+	assert.Assert(t, light.Attenuation(math.CreatePoint(10.0, 0.0, 0.0)) == 0.0)
+	// behind the light
+	assert.Assert(t, light.Attenuation(math.CreatePoint(0.0, 20.0, 0.0)) == 0.0)
+	// 25 degrees off axis lies between inner and outer cone
+	penumbra := light.Attenuation(math.CreatePoint(10.0*gomath.Tan(math.Radians(25.0)), 0.0, 0.0))
+	assert.Assert(t, penumbra > 0.0 && penumbra < 1.0)
+}
+
+func TestLightEquals(t *testing.T) {
+	p := CreateLight(math.CreatePoint(0.0, 1.0, 0.0), math.CreateColor(1.0, 1.0, 1.0))
+	d := CreateDirectionalLight(math.CreateVector(0.0, 1.0, 0.0), math.CreateColor(1.0, 1.0, 1.0))
+
+	assert.Assert(t, p.Equals(CreateLight(math.CreatePoint(0.0, 1.0, 0.0), math.CreateColor(1.0, 1.0, 1.0))))
+	assert.Assert(t, !p.Equals(d))
+	assert.Assert(t, !d.Equals(p))
+}
@@ -6,38 +6,46 @@ import (
 	"raygo/math"
 )
 
-func PhongLighting(m g.Material, obj g.Shape, light Light, position math.Point, eyev math.Vector, normalv math.Vector, inShadow bool) math.Color {
-	color := m.Color
-	if m.Texture.Exists() {
-		pointObjSpace := obj.GetInverseTransform().MulT(position)
-		texel := obj.GetUvCoordinate(pointObjSpace, normalv)
-		color = m.Texture.ColorAt(texel)
-	} else if m.Pattern != nil {
-		color = m.Pattern.ColorAtObject(position, obj)
-	}
+// intensity is the fraction of the light that reaches the position (0.0 = fully in shadow)
+func PhongLighting(m g.Material, obj g.Shape, light Light, position math.Point, eyev math.Vector, normalv math.Vector, intensity float64) math.Color {
+	return PhongLightingSamples(m, obj, light, light.Samples(position), position, eyev, normalv, intensity)
+}
 
-	// combine the surface color with the light's color/intensity
-	effectiveColor := color.Blend(light.Intensity)
+// PhongLightingSamples shades with the given samples of the light, that way the shadow test
+// and the shading can use the same points of a jittered area light
+func PhongLightingSamples(m g.Material, obj g.Shape, light Light, samples []LightSample, position math.Point, eyev math.Vector, normalv math.Vector, intensity float64) math.Color {
+	color := SurfaceColor(m, obj, position, normalv)
 
-	// find the direction to the light source
-	lightv := light.Position.Subtract(position).Normalize()
+	// combine the surface color with the light's color/intensity
+	lightIntensity := light.GetIntensity()
+	effectiveColor := color.Blend(lightIntensity)
 
 	// compute the ambient contribution
 	ambient := effectiveColor.Mul(m.Ambient)
 
-	if inShadow {
+	// spot lights only reach positions inside of their cone
+	intensity = intensity * light.Attenuation(position)
+	if intensity <= 0.0 {
 		return ambient
 	}
 
-	// lightDotNormal represents the cosine of the angle between the
-	// light vector and the normal vector. A negative number means the
-	// light is on the other side of the surface.
-	lightDotNormal := lightv.Dot(normalv)
+	// area lights are sampled once per cell, the results are averaged
 	diffuse := math.CreateColor(0.0, 0.0, 0.0)
 	specular := math.CreateColor(0.0, 0.0, 0.0)
-	if lightDotNormal >= 0 {
+	for _, sample := range samples {
+		// the direction to the light source
+		lightv := sample.Direction
+
+		// lightDotNormal represents the cosine of the angle between the
+		// light vector and the normal vector. A negative number means the
+		// light is on the other side of the surface.
+		lightDotNormal := lightv.Dot(normalv)
+		if lightDotNormal < 0 {
+			continue
+		}
+
 		// compute the diffuse contribution
-		diffuse = effectiveColor.Mul(m.Diffuse).Mul(lightDotNormal)
+		diffuse = diffuse.Add(effectiveColor.Mul(m.Diffuse).Mul(lightDotNormal))
 
 		// reflectDotEye represents the cosine of the angle between the
 		// reflection vector and the eye vector. A negative number means the
@@ -48,9 +56,25 @@ func PhongLighting(m g.Material, obj g.Shape, light Light, position math.Point,
 		if reflectDotEye > 0 {
 			// compute the specular contribution
 			factor := gomath.Pow(reflectDotEye, m.Shininess)
-			specular = light.Intensity.Mul(m.Specular).Mul(factor)
+			specular = specular.Add(lightIntensity.Mul(m.Specular).Mul(factor))
 		}
 	}
 
+	sampleCount := float64(len(samples))
+	diffuse = diffuse.Div(sampleCount).Mul(intensity)
+	specular = specular.Div(sampleCount).Mul(intensity)
+
 	return ambient.Add(diffuse).Add(specular)
 }
+
+// SurfaceColor is the color of the material at position, taken from its texture, its pattern or its plain color
+func SurfaceColor(m g.Material, obj g.Shape, position math.Point, normalv math.Vector) math.Color {
+	if m.Texture.Exists() {
+		pointObjSpace := g.WorldToObject(obj, position)
+		texel := obj.GetUvCoordinate(pointObjSpace, normalv)
+		return m.Texture.ColorAt(texel)
+	} else if m.Pattern != nil {
+		return m.Pattern.ColorAtObject(position, obj)
+	}
+	return m.Color
+}
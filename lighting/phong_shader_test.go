@@ -19,7 +19,7 @@ func TestLightingEyeBetweenLightAndSurface(t *testing.T) {
 	light := CreateLight(math.CreatePoint(0.0, 0.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
 	expected := math.CreateColor(1.9, 1.9, 1.9)
 
-	actual := PhongLighting(m, s, light, p, eyev, normalv, false)
+	actual := PhongLighting(m, s, light, p, eyev, normalv, 1.0)
 
 	assert.Assert(t, expected.Equals(actual))
 }
@@ -34,7 +34,7 @@ func TestLightingEyeOffsetBetweenLightAndSurface(t *testing.T) {
 	light := CreateLight(math.CreatePoint(0.0, 0.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
 	expected := math.CreateColor(1.0, 1.0, 1.0)
 
-	assert.Assert(t, expected.Equals(PhongLighting(m, s, light, p, eyev, normalv, false)))
+	assert.Assert(t, expected.Equals(PhongLighting(m, s, light, p, eyev, normalv, 1.0)))
 }
 
 func TestLightingEyeBetweenLightOffsetAndSurface(t *testing.T) {
@@ -47,7 +47,7 @@ func TestLightingEyeBetweenLightOffsetAndSurface(t *testing.T) {
 	light := CreateLight(math.CreatePoint(0.0, 10.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
 	expected := math.CreateColor(0.7364, 0.7364, 0.7364)
 
-	assert.Assert(t, expected.Equals(PhongLighting(m, s, light, p, eyev, normalv, false)))
+	assert.Assert(t, expected.Equals(PhongLighting(m, s, light, p, eyev, normalv, 1.0)))
 }
 
 func TestLightingEyeInReflectionVector(t *testing.T) {
@@ -60,7 +60,7 @@ func TestLightingEyeInReflectionVector(t *testing.T) {
 	light := CreateLight(math.CreatePoint(0.0, 10.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
 	expected := math.CreateColor(1.6364, 1.6364, 1.6364)
 
-	assert.Assert(t, expected.Equals(PhongLighting(m, s, light, p, eyev, normalv, false)))
+	assert.Assert(t, expected.Equals(PhongLighting(m, s, light, p, eyev, normalv, 1.0)))
 }
 
 func TestLightingLightBehindSurface(t *testing.T) {
@@ -73,7 +73,7 @@ func TestLightingLightBehindSurface(t *testing.T) {
 	light := CreateLight(math.CreatePoint(0.0, 10.0, 10.0), math.CreateColor(1.0, 1.0, 1.0))
 	expected := math.CreateColor(0.1, 0.1, 0.1)
 
-	assert.Assert(t, expected.Equals(PhongLighting(m, s, light, p, eyev, normalv, false)))
+	assert.Assert(t, expected.Equals(PhongLighting(m, s, light, p, eyev, normalv, 1.0)))
 }
 
 func TestLightingEyeBetweenLightAndSurfaceShadow(t *testing.T) {
@@ -86,7 +86,7 @@ func TestLightingEyeBetweenLightAndSurfaceShadow(t *testing.T) {
 	light := CreateLight(math.CreatePoint(0.0, 0.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
 	expected := math.CreateColor(0.1, 0.1, 0.1)
 
-	actual := PhongLighting(m, s, light, p, eyev, normalv, true)
+	actual := PhongLighting(m, s, light, p, eyev, normalv, 0.0)
 
 	assert.Assert(t, expected.Equals(actual))
 }
@@ -108,9 +108,89 @@ func TestMaterialWithPattern(t *testing.T) {
 	normalv := math.CreateVector(0.0, 0.0, -1.0)
 	light := CreateLight(math.CreatePoint(0.0, 0.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
 
-	c1 := PhongLighting(m, s, light, math.CreatePoint(0.9, 0.0, 0.0), eyev, normalv, false)
-	c2 := PhongLighting(m, s, light, math.CreatePoint(1.1, 0.0, 0.0), eyev, normalv, false)
+	c1 := PhongLighting(m, s, light, math.CreatePoint(0.9, 0.0, 0.0), eyev, normalv, 1.0)
+	c2 := PhongLighting(m, s, light, math.CreatePoint(1.1, 0.0, 0.0), eyev, normalv, 1.0)
 
 	assert.Assert(t, white.Equals(c1))
 	assert.Assert(t, black.Equals(c2))
 }
+
+func TestLightingSamplesAreaLight(t *testing.T) {
+	corner := math.CreatePoint(-0.5, -0.5, -5.0)
+	v1 := math.CreateVector(1.0, 0.0, 0.0)
+	v2 := math.CreateVector(0.0, 1.0, 0.0)
+	light := CreateAreaLight(corner, v1, 2, v2, 2, math.CreateColor(1.0, 1.0, 1.0))
+	s := g.CreateSphere()
+	s.CalculateInverseTransform()
+	m := g.DefaultMaterial()
+	m.SetAmbient(0.1)
+	m.SetDiffuse(0.9)
+	m.SetSpecular(0.0)
+	eye := math.CreatePoint(0.0, 0.0, -5.0)
+
+	testCases := []struct {
+		point    math.Point
+		expected math.Color
+	}{
+		{math.CreatePoint(0.0, 0.0, -1.0), math.CreateColor(0.9965, 0.9965, 0.9965)},
+		{math.CreatePoint(0.0, 0.7071, -0.7071), math.CreateColor(0.62318, 0.62318, 0.62318)},
+	}
+
+	for _, tc := range testCases {
+		eyev := eye.Subtract(tc.point).Normalize()
+		normalv := math.CreateVector(tc.point.X, tc.point.Y, tc.point.Z)
+		actual := PhongLighting(m, s, light, tc.point, eyev, normalv, 1.0)
+		assert.Assert(t, tc.expected.Equals(actual), "%v != %v", tc.expected, actual)
+	}
+}
+
+func TestLightingPartialIntensity(t *testing.T) {
+	s := g.CreateSphere()
+	s.CalculateInverseTransform()
+	eyev := math.CreateVector(0.0, 0.0, -1.0)
+	normalv := math.CreateVector(0.0, 0.0, -1.0)
+	m := g.DefaultMaterial()
+	p := math.CreatePoint(0.0, 0.0, 0.0)
+	light := CreateLight(math.CreatePoint(0.0, 0.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
+	// ambient 0.1 + half of diffuse 0.9 + half of specular 0.9
+	expected := math.CreateColor(1.0, 1.0, 1.0)
+
+	actual := PhongLighting(m, s, light, p, eyev, normalv, 0.5)
+
+	assert.Assert(t, expected.Equals(actual))
+}
+
+func TestLightingOutsideSpotLightCone(t *testing.T) {
+	s := g.CreateSphere()
+	s.CalculateInverseTransform()
+	eyev := math.CreateVector(0.0, 0.0, -1.0)
+	normalv := math.CreateVector(0.0, 0.0, -1.0)
+	m := g.DefaultMaterial()
+	p := math.CreatePoint(0.0, 0.0, 0.0)
+	// the spot light points away from the surface
+	light := CreateSpotLight(math.CreatePoint(0.0, 0.0, -10.0),
+		math.CreateVector(0.0, 0.0, -1.0),
+		math.Radians(10.0),
+		math.Radians(20.0),
+		math.CreateColor(1.0, 1.0, 1.0))
+	expected := math.CreateColor(0.1, 0.1, 0.1)
+
+	actual := PhongLighting(m, s, light, p, eyev, normalv, 1.0)
+
+	assert.Assert(t, expected.Equals(actual))
+}
+
+func TestLightingDirectionalLight(t *testing.T) {
+	s := g.CreateSphere()
+	s.CalculateInverseTransform()
+	eyev := math.CreateVector(0.0, 0.0, -1.0)
+	normalv := math.CreateVector(0.0, 0.0, -1.0)
+	m := g.DefaultMaterial()
+	p := math.CreatePoint(0.0, 0.0, 0.0)
+	light := CreateDirectionalLight(math.CreateVector(0.0, 0.0, 1.0), math.CreateColor(1.0, 1.0, 1.0))
+	expected := math.CreateColor(1.9, 1.9, 1.9)
+
+	actual := PhongLighting(m, s, light, p, eyev, normalv, 1.0)
+
+	assert.Assert(t, expected.Equals(actual))
+}
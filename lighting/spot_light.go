@@ -0,0 +1,69 @@
+package lighting
+
+import (
+	gomath "math"
+	"raygo/math"
+	"reflect"
+)
+
+// SpotLight is a point light that only shines into a cone.
+// Inside of InnerAngle the light has full intensity, beyond OuterAngle it has none.
+// In between the intensity falls off smoothly.
+type SpotLight struct {
+	Position   math.Point
+	Direction  math.Vector
+	Intensity  math.Color
+	InnerAngle float64 // radians, measured from Direction
+	OuterAngle float64 // radians, measured from Direction
+}
+
+func CreateSpotLight(p math.Point, direction math.Vector, innerAngle float64, outerAngle float64, intensity math.Color) *SpotLight {
+	return &SpotLight{
+		Position:   p,
+		Direction:  direction.Normalize(),
+		Intensity:  intensity,
+		InnerAngle: innerAngle,
+		OuterAngle: outerAngle,
+	}
+}
+
+func (l *SpotLight) GetIntensity() math.Color {
+	return l.Intensity
+}
+
+func (l *SpotLight) Attenuation(p math.Point) float64 {
+	cosAngle := p.Subtract(l.Position).Normalize().Dot(l.Direction)
+	cosInner := gomath.Cos(l.InnerAngle)
+	cosOuter := gomath.Cos(l.OuterAngle)
+
+	if cosAngle >= cosInner {
+		return 1.0
+	}
+	if cosAngle <= cosOuter {
+		return 0.0
+	}
+
+	// hermite interpolation between the cone borders
+	t := (cosAngle - cosOuter) / (cosInner - cosOuter)
+	return t * t * (3.0 - 2.0*t)
+}
+
+func (l *SpotLight) Samples(p math.Point) []LightSample {
+	return []LightSample{createSample(p, l.Position)}
+}
+
+func (l *SpotLight) Equals(other Light) bool {
+	if reflect.TypeOf(l) == reflect.TypeOf(other) {
+		otherLight := other.(*SpotLight)
+		return l.Position.Equals(otherLight.Position) &&
+			l.Direction.Equals(otherLight.Direction) &&
+			l.Intensity.Equals(otherLight.Intensity) &&
+			floatEquals(l.InnerAngle, otherLight.InnerAngle) &&
+			floatEquals(l.OuterAngle, otherLight.OuterAngle)
+	}
+	return false
+}
+
+func floatEquals(a float64, b float64) bool {
+	return gomath.Abs(a-b) < math.EPSILON
+}
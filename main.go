@@ -22,7 +22,7 @@ func main() {
 	// pprof.StartCPUProfile(f)
 	// defer pprof.StopCPUProfile()
 
-	app.Run(os.Args)
+	os.Exit(app.Run(os.Args))
 	// if fileFlagIndex := slices.Index(os.Args, "-f"); fileFlagIndex != -1 {
 	// 	if len(os.Args) <= fileFlagIndex+1 {
 	// 		panic("missing file path after -f flag")
@@ -85,7 +85,10 @@ func RenderGif() {
 
 func ReadOBJStats(path string) {
 	begin := time.Now()
-	teapot := obj.ParseFile(path)
+	teapot, err := obj.ParseFile(path)
+	if err != nil {
+		panic(err)
+	}
 	end := time.Now()
 	diff := end.Sub(begin)
 
@@ -0,0 +1,150 @@
+package math
+
+import (
+	gomath "math"
+	"slices"
+)
+
+// leading coefficients below this are treated as zero and the polynomial loses a degree
+const POLYNOMIAL_EPSILON = 1e-12
+
+// SolveQuadratic returns the real roots of a*x^2 + b*x + c in ascending order
+func SolveQuadratic(a float64, b float64, c float64) []float64 {
+	if gomath.Abs(a) < POLYNOMIAL_EPSILON {
+		if gomath.Abs(b) < POLYNOMIAL_EPSILON {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+
+	disc := b*b - 4.0*a*c
+	if disc < 0.0 {
+		return nil
+	}
+	if disc == 0.0 {
+		return []float64{-b / (2.0 * a)}
+	}
+
+	// avoids the cancellation of -b + sqrt(disc) when b is large
+	q := -0.5 * (b + gomath.Copysign(gomath.Sqrt(disc), b))
+	if q == 0.0 {
+		// b and c are zero
+		return []float64{0.0, 0.0}
+	}
+	roots := []float64{q / a, c / q}
+	slices.Sort(roots)
+	return roots
+}
+
+// SolveCubic returns the real roots of a*x^3 + b*x^2 + c*x + d in ascending order
+func SolveCubic(a float64, b float64, c float64, d float64) []float64 {
+	if gomath.Abs(a) < POLYNOMIAL_EPSILON {
+		return SolveQuadratic(b, c, d)
+	}
+
+	// x^3 + A*x^2 + B*x + C, substituting x = u - A/3 gives u^3 + p*u + q
+	A, B, C := b/a, c/a, d/a
+	shift := A / 3.0
+	p := B - A*A/3.0
+	q := 2.0*A*A*A/27.0 - A*B/3.0 + C
+
+	roots := make([]float64, 0, 3)
+	disc := q*q/4.0 + p*p*p/27.0
+	switch {
+	case gomath.Abs(p) < POLYNOMIAL_EPSILON && gomath.Abs(q) < POLYNOMIAL_EPSILON:
+		roots = append(roots, 0.0)
+	case disc > 0.0:
+		// one real root
+		sqrtDisc := gomath.Sqrt(disc)
+		roots = append(roots, gomath.Cbrt(-q/2.0+sqrtDisc)+gomath.Cbrt(-q/2.0-sqrtDisc))
+	case disc == 0.0:
+		// a single and a double root
+		roots = append(roots, 3.0*q/p, -3.0*q/(2.0*p))
+	default:
+		// three real roots, trigonometric solution
+		r := 2.0 * gomath.Sqrt(-p/3.0)
+		phi := gomath.Acos(clamp(3.0*q/(2.0*p)*gomath.Sqrt(-3.0/p), -1.0, 1.0)) / 3.0
+		for k := range 3 {
+			roots = append(roots, r*gomath.Cos(phi-2.0*gomath.Pi*float64(k)/3.0))
+		}
+	}
+
+	for i := range roots {
+		roots[i] -= shift
+	}
+	slices.Sort(roots)
+	return roots
+}
+
+// SolveQuartic returns the real roots of a*x^4 + b*x^3 + c*x^2 + d*x + e in ascending order.
+// The roots are found with Ferrari's method and refined with Newton's method,
+// which removes most of the error that the closed form accumulates.
+func SolveQuartic(a float64, b float64, c float64, d float64, e float64) []float64 {
+	if gomath.Abs(a) < POLYNOMIAL_EPSILON {
+		return SolveCubic(b, c, d, e)
+	}
+
+	// x^4 + A*x^3 + B*x^2 + C*x + D, substituting x = y - A/4 gives y^4 + p*y^2 + q*y + r
+	A, B, C, D := b/a, c/a, d/a, e/a
+	shift := A / 4.0
+	p := B - 3.0*A*A/8.0
+	q := C - A*B/2.0 + A*A*A/8.0
+	r := D - A*C/4.0 + A*A*B/16.0 - 3.0*A*A*A*A/256.0
+
+	roots := make([]float64, 0, 4)
+	if gomath.Abs(q) < POLYNOMIAL_EPSILON {
+		// biquadratic, solve for y^2
+		for _, z := range SolveQuadratic(1.0, p, r) {
+			if z >= 0.0 {
+				roots = append(roots, gomath.Sqrt(z), -gomath.Sqrt(z))
+			}
+		}
+	} else {
+		// m makes both sides of (y^2 + p/2 + m)^2 = 2m*y^2 - q*y + m^2 + m*p + p^2/4 - r perfect squares,
+		// it is a root of the resolvent cubic. The cubic is negative at 0, so there is always a positive root.
+		resolvent := SolveCubic(8.0, 8.0*p, 2.0*p*p-8.0*r, -q*q)
+		m := resolvent[len(resolvent)-1]
+		s := gomath.Sqrt(2.0 * m)
+		roots = append(roots, SolveQuadratic(1.0, -s, p/2.0+m+q/(2.0*s))...)
+		roots = append(roots, SolveQuadratic(1.0, s, p/2.0+m-q/(2.0*s))...)
+	}
+
+	for i := range roots {
+		roots[i] = polishRoot([]float64{1.0, A, B, C, D}, roots[i]-shift)
+	}
+	slices.Sort(roots)
+	return roots
+}
+
+// polishRoot improves an approximate root of the polynomial with a few Newton steps,
+// coefficients start with the highest degree
+func polishRoot(coefficients []float64, x float64) float64 {
+	value, derivative := evaluatePolynomial(coefficients, x)
+	for range 3 {
+		if derivative == 0.0 {
+			break
+		}
+		next := x - value/derivative
+		nextValue, nextDerivative := evaluatePolynomial(coefficients, next)
+		// close to double roots Newton's method can overshoot, a step has to get closer to zero
+		if !(gomath.Abs(nextValue) < gomath.Abs(value)) {
+			break
+		}
+		x, value, derivative = next, nextValue, nextDerivative
+	}
+	return x
+}
+
+// evaluatePolynomial returns the value and the derivative at x with Horner's method
+func evaluatePolynomial(coefficients []float64, x float64) (float64, float64) {
+	value, derivative := 0.0, 0.0
+	for _, c := range coefficients {
+		derivative = derivative*x + value
+		value = value*x + c
+	}
+	return value, derivative
+}
+
+func clamp(x float64, low float64, high float64) float64 {
+	return gomath.Max(low, gomath.Min(high, x))
+}
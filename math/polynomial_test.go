@@ -0,0 +1,69 @@
+package math
+
+import (
+	gomath "math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func rootsEqual(actual []float64, expected ...float64) bool {
+	if len(actual) != len(expected) {
+		return false
+	}
+	for i := range actual {
+		if gomath.Abs(actual[i]-expected[i]) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSolveQuadratic(t *testing.T) {
+	assert.Assert(t, rootsEqual(SolveQuadratic(1, -3, 2), 1, 2))
+	assert.Assert(t, rootsEqual(SolveQuadratic(1, 2, 1), -1))
+	assert.Assert(t, rootsEqual(SolveQuadratic(1, 0, 1)))
+	assert.Assert(t, rootsEqual(SolveQuadratic(0, 2, -4), 2))
+	// no cancellation for large b
+	roots := SolveQuadratic(1, 1e8, 1)
+	assert.Assert(t, gomath.Abs(roots[1]+1e-8) < 1e-20, "%v", roots)
+}
+
+func TestSolveCubic(t *testing.T) {
+	// (x - 1)(x - 2)(x - 3)
+	assert.Assert(t, rootsEqual(SolveCubic(1, -6, 11, -6), 1, 2, 3))
+	// (x - 2)(x^2 + 1)
+	assert.Assert(t, rootsEqual(SolveCubic(1, -2, 1, -2), 2))
+	// (x + 1)^2 (x - 2)
+	assert.Assert(t, rootsEqual(SolveCubic(1, 0, -3, -2), -1, 2))
+	assert.Assert(t, rootsEqual(SolveCubic(2, 0, 0, 0), 0))
+	assert.Assert(t, rootsEqual(SolveCubic(0, 1, -3, 2), 1, 2))
+}
+
+func TestSolveQuartic(t *testing.T) {
+	// (x - 1)(x - 2)(x - 3)(x - 4)
+	assert.Assert(t, rootsEqual(SolveQuartic(1, -10, 35, -50, 24), 1, 2, 3, 4))
+	// biquadratic (x^2 - 1)(x^2 - 4)
+	assert.Assert(t, rootsEqual(SolveQuartic(1, 0, -5, 0, 4), -2, -1, 1, 2))
+	// (x - 1)(x + 2)(x^2 + 1)
+	assert.Assert(t, rootsEqual(SolveQuartic(1, 1, -1, 1, -2), -2, 1))
+	// x^4 + 1 has no real roots
+	assert.Assert(t, rootsEqual(SolveQuartic(1, 0, 0, 0, 1)))
+	assert.Assert(t, rootsEqual(SolveQuartic(0, 1, -6, 11, -6), 1, 2, 3))
+}
+
+func TestSolveQuarticWithScaledCoefficients(t *testing.T) {
+	// roots that are far apart: (x - 0.001)(x - 1)(x - 10)(x - 1000)
+	a, b, c := 0.001, 1.0, 10.0
+	d := 1000.0
+	roots := SolveQuartic(1,
+		-(a + b + c + d),
+		a*b+a*c+a*d+b*c+b*d+c*d,
+		-(a*b*c + a*b*d + a*c*d + b*c*d),
+		a*b*c*d)
+
+	assert.Assert(t, len(roots) == 4, "%v", roots)
+	for i, expected := range []float64{a, b, c, d} {
+		assert.Assert(t, gomath.Abs(roots[i]-expected) < 1e-9*gomath.Max(1, expected), "%v", roots)
+	}
+}
@@ -0,0 +1,195 @@
+package obj
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"raygo/geometry"
+	"raygo/math"
+	"strconv"
+	"strings"
+)
+
+// mtlEntry collects the statements of a newmtl block, illum can come before the values it refers to,
+// so the material is only built once the block is complete
+type mtlEntry struct {
+	name       string
+	ambient    math.Color // Ka
+	diffuse    math.Color // Kd
+	specular   math.Color // Ks
+	exponent   float64    // Ns
+	dissolve   float64    // d, Tr is 1 - d
+	density    float64    // Ni
+	illum      int
+	diffuseMap string // map_Kd
+}
+
+// createMtlEntry starts with the values that turn into the default material
+func createMtlEntry(name string) *mtlEntry {
+	return &mtlEntry{
+		name:     name,
+		ambient:  math.CreateColor(0.1, 0.1, 0.1),
+		diffuse:  math.CreateColor(1.0, 1.0, 1.0),
+		specular: math.CreateColor(0.9, 0.9, 0.9),
+		exponent: 200.0,
+		dissolve: 1.0,
+		density:  1.0,
+		illum:    2,
+	}
+}
+
+// toMaterial maps the entry onto the phong model of raygo. Kd is the color of the material, Ka and Ks
+// only scale its ambient and specular part because they cannot have colors of their own.
+func (e *mtlEntry) toMaterial() geometry.Material {
+	m := geometry.DefaultMaterial()
+	m.Name = e.name
+	m.SetColor(e.diffuse)
+	m.SetAmbient(average(e.ambient))
+	m.SetSpecular(average(e.specular))
+	m.SetShininess(e.exponent)
+	m.SetTransparency(1.0 - e.dissolve)
+	m.SetRefractiveIndex(e.density)
+	m.Texture.File = e.diffuseMap
+
+	switch e.illum {
+	case 0:
+		// constant color without any shading
+		m.SetAmbient(1.0)
+		m.SetDiffuse(0.0)
+		m.SetSpecular(0.0)
+	case 1:
+		m.SetSpecular(0.0)
+	case 3, 4, 5, 6, 7:
+		// ray traced reflections of the strength of the specular color
+		m.SetReflective(average(e.specular))
+	}
+	return m
+}
+
+func average(c math.Color) float64 {
+	return (c.X + c.Y + c.Z) / 3.0
+}
+
+// ParseMtlFile reads the materials of a material library, textures are loaded relative to the library
+func ParseMtlFile(mtlPath string) (map[string]*geometry.Material, error) {
+	content, err := os.ReadFile(mtlPath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open mtl file: %w", err)
+	}
+
+	materials, err := ParseMtlData(string(content))
+	if err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			parseErr.File = mtlPath
+		}
+		return nil, err
+	}
+
+	directory := filepath.Dir(mtlPath) + string(filepath.Separator)
+	for _, m := range materials {
+		if m.Texture.Exists() {
+			if err := m.Texture.InitTexture(directory); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return materials, nil
+}
+
+// ParseMtlData reads the materials of a material library by name. The files of their textures are
+// not loaded.
+func ParseMtlData(data string) (map[string]*geometry.Material, error) {
+	entries := make([]*mtlEntry, 0)
+	var current *mtlEntry
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == "newmtl" {
+			if len(fields) != 2 {
+				return nil, &ParseError{Line: i + 1, Err: fmt.Errorf("a material needs a name: %v", fields)}
+			}
+			current = createMtlEntry(fields[1])
+			entries = append(entries, current)
+			continue
+		}
+		if current == nil {
+			return nil, &ParseError{Line: i + 1, Err: fmt.Errorf("'%v' before the first newmtl", fields[0])}
+		}
+		if err := processMtlStatement(current, fields); err != nil {
+			return nil, &ParseError{Line: i + 1, Err: err}
+		}
+	}
+
+	materials := make(map[string]*geometry.Material, len(entries))
+	for _, e := range entries {
+		m := e.toMaterial()
+		materials[e.name] = &m
+	}
+	return materials, nil
+}
+
+func processMtlStatement(e *mtlEntry, fields []string) error {
+	var err error
+	switch fields[0] {
+	case "Ka":
+		e.ambient, err = parseMtlColor(fields)
+	case "Kd":
+		e.diffuse, err = parseMtlColor(fields)
+	case "Ks":
+		e.specular, err = parseMtlColor(fields)
+	case "Ns":
+		e.exponent, err = parseMtlFloat(fields)
+	case "d":
+		e.dissolve, err = parseMtlFloat(fields)
+	case "Tr":
+		var transparency float64
+		transparency, err = parseMtlFloat(fields)
+		e.dissolve = 1.0 - transparency
+	case "Ni":
+		e.density, err = parseMtlFloat(fields)
+	case "illum":
+		if len(fields) != 2 {
+			return fmt.Errorf("illum needs a single value: %v", fields)
+		}
+		if e.illum, err = strconv.Atoi(fields[1]); err != nil {
+			return fmt.Errorf("invalid illumination model '%v': %w", fields[1], err)
+		}
+	case "map_Kd":
+		// options like -s come before the file, spaces in the file name are not supported
+		if len(fields) < 2 {
+			return fmt.Errorf("map_Kd needs a file: %v", fields)
+		}
+		e.diffuseMap = fields[len(fields)-1]
+	}
+	return err
+}
+
+// parseMtlColor accepts r g b or a single value for all three
+func parseMtlColor(fields []string) (math.Color, error) {
+	if len(fields) != 2 && len(fields) != 4 {
+		return math.Color{}, fmt.Errorf("%v needs 1 or 3 values: %v", fields[0], fields)
+	}
+	values, err := getStringsAsFloats(fields[1:])
+	if err != nil {
+		return math.Color{}, err
+	}
+	if len(values) == 1 {
+		return math.CreateColor(values[0], values[0], values[0]), nil
+	}
+	return math.CreateColor(values[0], values[1], values[2]), nil
+}
+
+func parseMtlFloat(fields []string) (float64, error) {
+	if len(fields) != 2 {
+		return 0.0, fmt.Errorf("%v needs a single value: %v", fields[0], fields)
+	}
+	values, err := getStringsAsFloats(fields[1:])
+	if err != nil {
+		return 0.0, err
+	}
+	return values[0], nil
+}
@@ -0,0 +1,170 @@
+package obj
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	gomath "math"
+	"os"
+	"path/filepath"
+	"raygo/geometry"
+	"raygo/math"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func floatEquals(a float64, b float64) bool {
+	return gomath.Abs(a-b) < math.EPSILON
+}
+
+func TestParseMtlData(t *testing.T) {
+	input := `
+# exported by hand
+newmtl paint
+	Ka 0.2 0.2 0.2
+	Kd 0.8 0.1 0.1
+	Ks 0.5 0.5 0.5
+	Ns 96
+	d 1.0
+	illum 2
+
+newmtl glass
+illum 4
+Kd 1
+Ks 0.9 0.9 0.9
+Tr 0.9
+Ni 1.5
+map_Kd -s 1 1 1 glass.png
+`
+	materials, err := ParseMtlData(input)
+	assert.NilError(t, err)
+	assert.Assert(t, len(materials) == 2)
+
+	paint := materials["paint"]
+	assert.Assert(t, paint.Name == "paint")
+	assert.Assert(t, paint.Color.Equals(math.CreateColor(0.8, 0.1, 0.1)))
+	assert.Assert(t, floatEquals(paint.Ambient, 0.2))
+	assert.Assert(t, floatEquals(paint.Specular, 0.5))
+	assert.Assert(t, paint.Shininess == 96.0)
+	assert.Assert(t, paint.Transparency == 0.0)
+	assert.Assert(t, paint.Reflective == 0.0)
+	assert.Assert(t, !paint.Texture.Exists())
+
+	glass := materials["glass"]
+	assert.Assert(t, glass.Color.Equals(math.CreateColor(1.0, 1.0, 1.0)))
+	assert.Assert(t, floatEquals(glass.Transparency, 0.9))
+	assert.Assert(t, glass.RefractiveIndex == 1.5)
+	assert.Assert(t, floatEquals(glass.Reflective, 0.9))
+	assert.Assert(t, glass.Texture.File == "glass.png")
+}
+
+func TestParseMtlDataDefaults(t *testing.T) {
+	materials, err := ParseMtlData("newmtl plain\n")
+	assert.NilError(t, err)
+
+	expected := geometry.DefaultMaterial()
+	assert.Assert(t, materials["plain"].Equals(expected))
+}
+
+func TestParseMtlDataIllumination(t *testing.T) {
+	materials, err := ParseMtlData("newmtl flat\nillum 0\nnewmtl matte\nillum 1\n")
+	assert.NilError(t, err)
+
+	flat := materials["flat"]
+	assert.Assert(t, flat.Ambient == 1.0 && flat.Diffuse == 0.0 && flat.Specular == 0.0)
+	matte := materials["matte"]
+	assert.Assert(t, matte.Specular == 0.0)
+	assert.Assert(t, matte.Diffuse == geometry.DefaultMaterial().Diffuse)
+}
+
+func TestParseMtlDataMalformedLines(t *testing.T) {
+	inputs := map[string]string{
+		"Kd 1 1 1\n":              "line 1: 'Kd' before the first newmtl",
+		"newmtl\n":                "line 1: a material needs a name",
+		"newmtl a\nKd 1 1\n":      "line 2: Kd needs 1 or 3 values",
+		"newmtl a\nNs x\n":        "line 2: invalid number 'x'",
+		"newmtl a\n\nillum two\n": "line 3: invalid illumination model 'two'",
+		"newmtl a\nmap_Kd\n":      "line 2: map_Kd needs a file",
+		"newmtl a\nd 0.5 0.5\n":   "line 2: d needs a single value",
+		"newmtl a\nKs 1 1 1 1\n":  "line 2: Ks needs 1 or 3 values",
+	}
+
+	for input, expected := range inputs {
+		_, err := ParseMtlData(input)
+
+		var parseErr *ParseError
+		assert.Assert(t, errors.As(err, &parseErr), "%q", input)
+		assert.Assert(t, strings.HasPrefix(err.Error(), expected), "%q: %v", input, err)
+	}
+}
+
+func TestParseFileWithMaterials(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	f, err := os.Create(filepath.Join(dir, "red.png"))
+	assert.NilError(t, err)
+	assert.NilError(t, png.Encode(f, img))
+	assert.NilError(t, f.Close())
+
+	mtl := "newmtl red\nKd 1 0 0\nnewmtl textured\nmap_Kd red.png\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "scene.mtl"), []byte(mtl), 0644))
+	objFile := `
+mtllib scene.mtl
+v 0 0 0
+v 1 0 0
+v 0 1 0
+f 1 2 3
+usemtl red
+f 1 2 3
+g textured part
+usemtl textured
+f 1 2 3
+usemtl unknown
+f 1 2 3
+`
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "scene.obj"), []byte(objFile), 0644))
+
+	objData, err := ParseFile(filepath.Join(dir, "scene.obj"))
+	assert.NilError(t, err)
+	assert.Assert(t, len(objData.MaterialLibraries) == 1)
+	assert.Assert(t, len(objData.Materials) == 2)
+	assert.Assert(t, objData.Materials["textured"].Texture.Data != nil)
+
+	object := objData.ToGroup(false)
+	plain := object.Children[0].(*geometry.Triangle)
+	red := object.Children[1].(*geometry.Triangle)
+	group := object.Children[2].(*geometry.Group)
+	textured := group.Children[0].(*geometry.Triangle)
+	unknown := group.Children[1].(*geometry.Triangle)
+	assert.Assert(t, plain.Material.Name == "")
+	assert.Assert(t, red.Material.Name == "red")
+	assert.Assert(t, red.Material.Color.Equals(math.CreateColor(1, 0, 0)))
+	assert.Assert(t, textured.Material.Name == "textured")
+	assert.Assert(t, textured.Material.Texture.Exists())
+	assert.Assert(t, unknown.Material.Equals(geometry.DefaultMaterial()))
+}
+
+func TestParseFileMissingMaterialLibrary(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "missing_library.obj")
+	objFile := "mtllib missing.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl red\nf 1 2 3\n"
+	assert.NilError(t, os.WriteFile(path, []byte(objFile), 0644))
+
+	objData, err := ParseFile(path)
+
+	assert.NilError(t, err)
+	assert.Assert(t, len(objData.MissingLibraries) == 1 && objData.MissingLibraries[0] == "missing.mtl")
+	assert.Assert(t, len(objData.Materials) == 0)
+	triangle := objData.ToGroup(false).Children[0].(*geometry.Triangle)
+	assert.Assert(t, triangle.Material.Equals(geometry.DefaultMaterial()))
+
+	// a library that exists has to be valid
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "missing.mtl"), []byte("Kd 1 0 0\n"), 0644))
+	_, err = ParseFile(path)
+	var parseErr *ParseError
+	assert.Assert(t, errors.As(err, &parseErr))
+}
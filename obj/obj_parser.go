@@ -1,10 +1,15 @@
 package obj
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
+	"path/filepath"
 	"raygo/geometry"
 	"raygo/math"
+	"raygo/progress"
 	"slices"
 	"strconv"
 	"strings"
@@ -15,8 +20,26 @@ const FACE_PREFIX = "f "
 const GROUP_PREFIX = "g "
 const NORMAL_PREFIX = "vn "
 const TEXTURE_PREFIX = "vt "
+const MTLLIB_PREFIX = "mtllib "
+const USEMTL_PREFIX = "usemtl "
+
+// ParseError describes a line of an obj file that could not be parsed
+type ParseError struct {
+	File string // empty if the data was not read from a file
+	Line int    // starting at 1
+	Err  error
+}
 
-var currentGroup *ObjGroup
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %v: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("%v:%v: %v", e.File, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
 
 type ObjData struct {
 	Vertices           []math.Point
@@ -24,13 +47,19 @@ type ObjData struct {
 	Normals            []math.Vector
 	TextureCoordinates []math.Point
 	Groups             []*ObjGroup
+	MaterialLibraries  []string                      // mtl files as referenced by the obj file
+	MissingLibraries   []string                      // mtl files that could not be read, their materials are the default material
+	Materials          map[string]*geometry.Material // from the material libraries by name
 	IgnoredLines       int
+	currentGroup       *ObjGroup // group that following faces belong to, nil for the root
+	currentMaterial    string    // material of the following faces, empty for the default material
 }
 
 type Face struct {
 	VertIndices    []int
 	TextureIndices []int
 	NormalIndices  []int
+	Material       string // name of the material set by usemtl
 }
 
 type ObjGroup struct {
@@ -55,6 +84,7 @@ func CreateObjData() *ObjData {
 		Faces:        make([]*Face, 0, 100),
 		Normals:      make([]math.Vector, 0, 100),
 		Groups:       make([]*ObjGroup, 0, 2),
+		Materials:    make(map[string]*geometry.Material),
 		IgnoredLines: 0,
 	}
 }
@@ -80,6 +110,10 @@ func (o *ObjData) PrintStats() {
 	fmt.Printf("Normals: %v\n", len(o.Normals))
 	fmt.Printf("Texture Coordinates: %v\n", len(o.TextureCoordinates))
 	fmt.Printf("Groups: %v\n", len(o.Groups))
+	fmt.Printf("Materials: %v\n", len(o.Materials))
+	if len(o.MissingLibraries) > 0 {
+		fmt.Printf("Missing material libraries: %v\n", strings.Join(o.MissingLibraries, ", "))
+	}
 	fmt.Printf("Bounds:\n")
 	fmt.Printf("\tMin: %v\n", objBounds.Minimum.ToString())
 	fmt.Printf("\tMax: %v\n", objBounds.Maximum.ToString())
@@ -102,9 +136,12 @@ func (o *ObjData) ToGroup(preCalcBB bool) *geometry.Group {
 				grp.AddChild(t)
 			}
 		}
+		grp.BuildBVH()
 		root.AddChild(grp)
 	}
 
+	root.BuildBVH()
+
 	if preCalcBB {
 		root.Bounds()
 	}
@@ -132,140 +169,211 @@ func (f *Face) ToTriangles(o *ObjData) []*geometry.Triangle {
 			t3 := o.GetT(f.TextureIndices[i+1])
 			triangle.AddTextureInformation(t1, t2, t3)
 		}
+		if m := o.Materials[f.Material]; m != nil {
+			triangle.Material = *m
+		}
 		triangles = append(triangles, triangle)
 	}
 	return triangles
 }
 
-func ParseFile(objPath string) *ObjData {
+func ParseFile(objPath string) (*ObjData, error) {
 	content, err := os.ReadFile(objPath)
 	if err != nil {
-		panic(fmt.Sprintf("cannot open obj file: '%v'", objPath))
+		return nil, fmt.Errorf("cannot open obj file: %w", err)
 	}
 
 	data := CreateObjData()
-	ParseData(data, string(content))
+	if err := ParseData(data, string(content)); err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			parseErr.File = objPath
+		}
+		return nil, err
+	}
+
+	// material libraries are relative to the obj file. Many obj files are shared without their
+	// libraries, so those are skipped, but a library that exists has to be valid.
+	directory := filepath.Dir(objPath)
+	for _, library := range data.MaterialLibraries {
+		materials, err := ParseMtlFile(filepath.Join(directory, library))
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, fs.ErrPermission) {
+			data.MissingLibraries = append(data.MissingLibraries, library)
+			progress.Warning(fmt.Sprintf("%v: skipping material library: %v", objPath, err))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(data.Materials, materials)
+	}
 
-	return data
+	return data, nil
 }
 
-func ParseData(objData *ObjData, data string) {
+func ParseData(objData *ObjData, data string) error {
 	lines := strings.Split(data, "\n")
-	for _, line := range lines {
-		ParseLine(objData, &line)
+	for i, line := range lines {
+		if err := ParseLine(objData, &line); err != nil {
+			return &ParseError{Line: i + 1, Err: err}
+		}
 	}
+	return nil
 }
 
-func ParseLine(objData *ObjData, line *string) {
+func ParseLine(objData *ObjData, line *string) error {
 	if strings.HasPrefix(*line, VERTEX_PREFIX) {
-		processVertex(objData, line)
+		return processVertex(objData, line)
 	} else if strings.HasPrefix(*line, FACE_PREFIX) {
-		processFace(objData, line, currentGroup)
+		return processFace(objData, line)
 	} else if strings.HasPrefix(*line, NORMAL_PREFIX) {
-		processNormal(objData, line)
+		return processNormal(objData, line)
 	} else if strings.HasPrefix(*line, TEXTURE_PREFIX) {
-		processTextureCoordinates(objData, line)
+		return processTextureCoordinates(objData, line)
 	} else if strings.HasPrefix(*line, GROUP_PREFIX) {
-		currentGroup = CreateObjGroup()
-		objData.Groups = append(objData.Groups, currentGroup)
+		objData.currentGroup = CreateObjGroup()
+		objData.Groups = append(objData.Groups, objData.currentGroup)
+	} else if strings.HasPrefix(*line, MTLLIB_PREFIX) {
+		libraries := strings.Fields(strings.TrimPrefix(*line, MTLLIB_PREFIX))
+		objData.MaterialLibraries = append(objData.MaterialLibraries, libraries...)
+	} else if strings.HasPrefix(*line, USEMTL_PREFIX) {
+		objData.currentMaterial = strings.TrimSpace(strings.TrimPrefix(*line, USEMTL_PREFIX))
 	} else {
 		objData.IgnoredLines += 1
 	}
+	return nil
 }
 
-func processNormal(objData *ObjData, line *string) {
+func processNormal(objData *ObjData, line *string) error {
 	normalComponents := strings.Split(*line, " ")
 	normalComponents = slices.DeleteFunc(normalComponents, isEmptyString)
-	normalx := getStringAsFloat(normalComponents[1])
-	normaly := getStringAsFloat(normalComponents[2])
-	normalz := getStringAsFloat(strings.TrimSuffix(normalComponents[3], "\r"))
+	if len(normalComponents) != 4 {
+		return fmt.Errorf("a normal line must consist of 4 elements: %v", normalComponents)
+	}
+
+	normal, err := getStringsAsFloats(normalComponents[1:])
+	if err != nil {
+		return err
+	}
 
 	objData.Normals = append(objData.Normals,
-		math.CreateVector(normalx, normaly, normalz))
+		math.CreateVector(normal[0], normal[1], normal[2]))
+	return nil
 }
 
-func processFace(objData *ObjData, line *string, currentGroup *ObjGroup) {
+func processFace(objData *ObjData, line *string) error {
 	faceComponents := strings.Split(*line, " ")
 	faceComponents = slices.DeleteFunc(faceComponents, isEmptyString)
 	face := CreateFace(len(faceComponents) - 1)
+	face.Material = objData.currentMaterial
 	for index := range faceComponents {
 		if index == 0 {
 			continue
 		}
-		vertexIndex, textureIndex, normalIndex := extractFaceIndices(faceComponents[index])
+		vertexIndex, textureIndex, normalIndex, err := extractFaceIndices(faceComponents[index])
+		if err != nil {
+			return err
+		}
+
+		if vertexIndex < 1 || vertexIndex > len(objData.Vertices) {
+			return fmt.Errorf("face references undefined vertex %v", vertexIndex)
+		}
 		face.VertIndices = append(face.VertIndices, vertexIndex)
 		if textureIndex != -1 {
+			if textureIndex < 1 || textureIndex > len(objData.TextureCoordinates) {
+				return fmt.Errorf("face references undefined texture coordinate %v", textureIndex)
+			}
 			face.TextureIndices = append(face.TextureIndices, textureIndex)
 		}
 		if normalIndex != -1 {
+			if normalIndex < 1 || normalIndex > len(objData.Normals) {
+				return fmt.Errorf("face references undefined normal %v", normalIndex)
+			}
 			face.NormalIndices = append(face.NormalIndices, normalIndex)
 		}
 	}
 
-	if currentGroup == nil {
+	if objData.currentGroup == nil {
 		objData.Faces = append(objData.Faces, face)
 	} else {
-		currentGroup.Faces = append(currentGroup.Faces, face)
+		objData.currentGroup.Faces = append(objData.currentGroup.Faces, face)
 	}
+	return nil
 }
 
-func processTextureCoordinates(objData *ObjData, line *string) {
+func processTextureCoordinates(objData *ObjData, line *string) error {
 	textureComponents := strings.Split(*line, " ")
 	textureComponents = slices.DeleteFunc(textureComponents, isEmptyString)
 	// only u is mandatory, v and w default to 0
-	textureU := getStringAsFloat(textureComponents[1])
-	textureV := 0.0
-	textureW := 0.0
-	if len(textureComponents) > 2 {
-		textureV = getStringAsFloat(textureComponents[2])
+	if len(textureComponents) < 2 || len(textureComponents) > 4 {
+		return fmt.Errorf("a texture coordinate line must consist of 2 to 4 elements: %v", textureComponents)
 	}
-	if len(textureComponents) > 3 {
-		textureW = getStringAsFloat(textureComponents[3])
+
+	coordinates, err := getStringsAsFloats(textureComponents[1:])
+	if err != nil {
+		return err
 	}
+	coordinates = append(coordinates, 0.0, 0.0)
 
-	objData.TextureCoordinates = append(objData.TextureCoordinates, math.CreatePoint(textureU, textureV, textureW))
+	objData.TextureCoordinates = append(objData.TextureCoordinates,
+		math.CreatePoint(coordinates[0], coordinates[1], coordinates[2]))
+	return nil
 }
 
 // input => 1/3/5
-func extractFaceIndices(face string) (int, int, int) {
+func extractFaceIndices(face string) (int, int, int, error) {
 	// face format: vertexIndex/textureIndex/vertexNormal
-	indices := strings.Split(face, "/")
+	indices := strings.Split(strings.TrimSuffix(face, "\r"), "/")
 	vertexIndex := -1
 	textureIndex := -1
 	normalIndex := -1
+	var err error
 	for i, stringIndex := range indices {
 		if i == 0 {
-			vertexIndex, _ = strconv.Atoi(stringIndex)
+			vertexIndex, err = strconv.Atoi(stringIndex)
 		} else if i == 1 && stringIndex != "" {
-			textureIndex, _ = strconv.Atoi(stringIndex)
+			textureIndex, err = strconv.Atoi(stringIndex)
 		} else if i == 2 {
-			normalIndex, _ = strconv.Atoi(stringIndex)
+			normalIndex, err = strconv.Atoi(stringIndex)
+		}
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("invalid face index '%v': %w", stringIndex, err)
 		}
 	}
-	return vertexIndex, textureIndex, normalIndex
+	return vertexIndex, textureIndex, normalIndex, nil
 }
 
 func isEmptyString(s string) bool {
 	return s == "" || s == "\r"
 }
 
-func processVertex(objData *ObjData, line *string) {
+func processVertex(objData *ObjData, line *string) error {
 	vertexComponents := strings.Split(*line, " ")
 	vertexComponents = slices.DeleteFunc(vertexComponents, isEmptyString)
 	if len(vertexComponents) != 4 {
-		panic(fmt.Sprintf("a vertex line must consist of 4 elements: %v", vertexComponents))
+		return fmt.Errorf("a vertex line must consist of 4 elements: %v", vertexComponents)
+	}
+
+	vertex, err := getStringsAsFloats(vertexComponents[1:])
+	if err != nil {
+		return err
 	}
 
-	vertx := getStringAsFloat(vertexComponents[1])
-	verty := getStringAsFloat(vertexComponents[2])
-	vertz := getStringAsFloat(strings.TrimSuffix(vertexComponents[3], "\r"))
 	objData.Vertices = append(objData.Vertices,
-		math.CreatePoint(vertx,
-			verty,
-			vertz))
+		math.CreatePoint(vertex[0],
+			vertex[1],
+			vertex[2]))
+	return nil
 }
 
-func getStringAsFloat(s string) float64 {
-	f, _ := strconv.ParseFloat(s, 64)
-	return f
+func getStringsAsFloats(components []string) ([]float64, error) {
+	floats := make([]float64, 0, len(components))
+	for _, component := range components {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(component, "\r"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%v': %w", component, err)
+		}
+		floats = append(floats, f)
+	}
+	return floats, nil
 }
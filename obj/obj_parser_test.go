@@ -1,8 +1,13 @@
 package obj
 
 import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"raygo/geometry"
 	"raygo/math"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -17,7 +22,7 @@ She set out one day
 in a relative way,
 and came back the previous night.`
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Vertices) == 0)
 	assert.Assert(t, objData.IgnoredLines == 5)
@@ -31,7 +36,7 @@ v 1 0 0
 v 1 1 0
 `
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Vertices) == 4)
 	assert.Assert(t, objData.IgnoredLines == 2)
@@ -50,7 +55,7 @@ v  1 0 0
 v  1 1 0
 `
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Vertices) == 4)
 	assert.Assert(t, objData.IgnoredLines == 3)
@@ -71,7 +76,7 @@ f 1 2 3
 f 1 3 4
 `
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Vertices) == 4)
 	assert.Assert(t, len(objData.Faces) == 2)
@@ -115,7 +120,7 @@ f 1/2/3 2/3/4 3/4/5
 f 1/2/3 3/4/5 4/5/6
 `
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Vertices) == 4)
 	assert.Assert(t, len(objData.Faces) == 2)
@@ -153,7 +158,7 @@ f 1//3 2//1 3//2
 f 1/1/3 2/3/1 3/4/2
 `
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Vertices) == 3)
 	assert.Assert(t, len(objData.Faces) == 2)
@@ -183,7 +188,7 @@ v 0 2 0
 f 1 2 3 4 5 
 `
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Vertices) == 5)
 	assert.Assert(t, len(objData.Faces) == 1)
@@ -220,7 +225,7 @@ g Second group
 f 1 3 4
 `
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Vertices) == 4)
 	assert.Assert(t, len(objData.Faces) == 1)
@@ -246,6 +251,62 @@ f 1 3 4
 	assert.Assert(t, t3.P3.Equals(objData.GetV(4)))
 }
 
+func TestParseDataGroupDoesNotLeakIntoNextFile(t *testing.T) {
+	grouped := `
+v -1 1 0
+v -1 0 0
+v 1 0 0
+g First group
+f 1 2 3
+`
+	ungrouped := `
+v -1 1 0
+v -1 0 0
+v 1 0 0
+f 1 2 3
+`
+	assert.NilError(t, ParseData(CreateObjData(), grouped))
+	objData := CreateObjData()
+	assert.NilError(t, ParseData(objData, ungrouped))
+
+	assert.Assert(t, len(objData.Faces) == 1)
+	assert.Assert(t, len(objData.Groups) == 0)
+}
+
+func TestParseDataMalformedLines(t *testing.T) {
+	inputs := map[string]string{
+		"v 1 2\n":              "line 1: a vertex line must consist of 4 elements",
+		"v 1 2 3\nv 1 x 3\n":   "line 2: invalid number 'x'",
+		"vn 0 1\n":             "line 1: a normal line must consist of 4 elements",
+		"vt \n":                "line 1: a texture coordinate line must consist of 2 to 4 elements",
+		"v 1 2 3\n\nf 1 1 4\n": "line 3: face references undefined vertex 4",
+		"v 1 2 3\nf 1/a 1 1\n": "line 2: invalid face index 'a'",
+	}
+
+	for input, expected := range inputs {
+		err := ParseData(CreateObjData(), input)
+
+		var parseErr *ParseError
+		assert.Assert(t, errors.As(err, &parseErr), "%q", input)
+		assert.Assert(t, strings.HasPrefix(err.Error(), expected), "%q: %v", input, err)
+	}
+}
+
+func TestParseFileErrors(t *testing.T) {
+	_, err := ParseFile("missing.obj")
+
+	assert.Assert(t, errors.Is(err, fs.ErrNotExist))
+
+	path := filepath.Join(t.TempDir(), "broken.obj")
+	assert.NilError(t, os.WriteFile(path, []byte("v 1 2 3\nv 1 2\n"), 0644))
+	_, err = ParseFile(path)
+
+	var parseErr *ParseError
+	assert.Assert(t, errors.As(err, &parseErr))
+	assert.Assert(t, parseErr.File == path)
+	assert.Assert(t, parseErr.Line == 2)
+}
+
 func TestNormals(t *testing.T) {
 	input := `
 vn 0 0 1
@@ -257,7 +318,7 @@ vn 1 2 3
 	expected3 := math.CreateVector(1.0, 2.0, 3.0)
 
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.Normals) == 3)
 	assert.Assert(t, objData.GetN(1).Equals(expected1))
@@ -280,7 +341,7 @@ vt 2.5
 	expected5 := math.CreatePoint(2.5, 0.0, 0.0)
 
 	objData := CreateObjData()
-	ParseData(objData, input)
+	assert.NilError(t, ParseData(objData, input))
 
 	assert.Assert(t, len(objData.TextureCoordinates) == 5)
 	assert.Assert(t, objData.GetT(1).Equals(expected1))
@@ -289,3 +350,51 @@ vt 2.5
 	assert.Assert(t, objData.GetT(4).Equals(expected4))
 	assert.Assert(t, objData.GetT(5).Equals(expected5))
 }
+
+func benchmarkTeapot(b *testing.B, teapot *geometry.Group) {
+	teapot.Bounds()
+	teapot.CalculateInverseTransform()
+	bounds := teapot.Bounds()
+
+	rays := make([]geometry.Ray, 0, 32*32)
+	for y := range 32 {
+		for x := range 32 {
+			px := bounds.Minimum.X + (bounds.Maximum.X-bounds.Minimum.X)*float64(x)/31.0
+			py := bounds.Minimum.Y + (bounds.Maximum.Y-bounds.Minimum.Y)*float64(y)/31.0
+			origin := p(px, py, bounds.Maximum.Z+10.0)
+			rays = append(rays, geometry.CreateRay(origin, math.CreateVector(0.0, 0.0, -1.0)))
+		}
+	}
+
+	b.ResetTimer()
+	for b.Loop() {
+		for _, r := range rays {
+			teapot.Intersect(r)
+		}
+	}
+}
+
+func BenchmarkTeapotFlat(b *testing.B) {
+	objData, err := ParseFile("../resources/teapot_high.obj")
+	assert.NilError(b, err)
+	teapot := geometry.EmptyGroup()
+	faces := objData.Faces
+	for _, g := range objData.Groups {
+		faces = append(faces, g.Faces...)
+	}
+	for _, face := range faces {
+		for _, t := range face.ToTriangles(objData) {
+			teapot.AddChild(t)
+		}
+	}
+
+	benchmarkTeapot(b, teapot)
+}
+
+func BenchmarkTeapotBVH(b *testing.B) {
+	objData, err := ParseFile("../resources/teapot_high.obj")
+	assert.NilError(b, err)
+	teapot := objData.ToGroup(true)
+
+	benchmarkTeapot(b, teapot)
+}
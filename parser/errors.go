@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// SyntaxError is returned when a scene description is not valid yaml
+type SyntaxError struct {
+	Position
+	Err error
+}
+
+func (e *SyntaxError) Error() string {
+	message := e.Err.Error()
+	var yamlErr yaml.Error
+	if errors.As(e.Err, &yamlErr) {
+		message = yamlErr.GetMessage()
+	}
+	return e.Position.format(fmt.Sprintf("could not unmarshal yaml: %v", message))
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError is a validation error that refers to a single node of the scene description.
+// Path is the YAMLPath of the node, e.g. $.scene.spheres[2].material. Errors returned by
+// SceneBuilder.Validate are located in the source, so that they also know their position.
+type FieldError struct {
+	Position
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Position.format(e.Err.Error())
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Position of a node in the yaml source, Line and Column start at 1
+type Position struct {
+	File    string
+	Line    int
+	Column  int
+	Snippet string // the surrounding lines with a caret pointing at the node
+}
+
+func (p Position) format(message string) string {
+	if p.Line == 0 {
+		return message
+	}
+	return fmt.Sprintf("%v: %v", p.location(), message)
+}
+
+func (p Position) location() string {
+	if p.File == "" {
+		return fmt.Sprintf("%v:%v", p.Line, p.Column)
+	}
+	return fmt.Sprintf("%v:%v:%v", p.File, p.Line, p.Column)
+}
+
+// ValidationError collects everything that is wrong with a scene description
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "scene description is invalid:")
+	for i, err := range e.Errors {
+		fmt.Fprintf(&b, "\n%v. %v", i, err)
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// DuplicateNameError is reported for the second definition of a name.
+// FirstPath is the path of the first definition and First its position, if it is known.
+type DuplicateNameError struct {
+	Kind      string // color, material, transform, pattern or scene object
+	Name      string
+	FirstPath string
+	First     Position
+}
+
+func (e *DuplicateNameError) Error() string {
+	message := fmt.Sprintf("%v '%v' is defined more than once", e.Kind, e.Name)
+	if e.First.Line == 0 {
+		return message
+	}
+	return fmt.Sprintf("%v, first definition at %v", message, e.First.location())
+}
+
+// LoadError is returned when a file that is referenced by the scene cannot be loaded
+type LoadError struct {
+	Name string // scene object or material that references the file
+	Err  error
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("cannot load '%v': %v", e.Name, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// FormatError returns the message of err followed by a source snippet
+// for every syntax or validation error with a known position
+func FormatError(err error) string {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "scene description is invalid:")
+		for i, err := range validationErr.Errors {
+			fmt.Fprintf(&b, "\n%v. %v", i, FormatError(err))
+		}
+		return b.String()
+	}
+
+	var syntaxErr *SyntaxError
+	if errors.As(err, &syntaxErr) && syntaxErr.Snippet != "" {
+		return fmt.Sprintf("%v\n%v", err, syntaxErr.Snippet)
+	}
+
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) && fieldErr.Snippet != "" {
+		return fmt.Sprintf("%v\n%v", err, fieldErr.Snippet)
+	}
+
+	return err.Error()
+}
+
+// atField attributes err to a field of the node that is validated
+func atField(field string, err error) error {
+	return &FieldError{Path: field, Err: err}
+}
+
+// atPath prefixes the paths of errs with the path of the node they were found in.
+// Errors without a path are attributed to the node itself.
+func atPath(path string, errs []error) []error {
+	result := make([]error, 0, len(errs))
+	for _, err := range errs {
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			result = append(result, &FieldError{Path: path + fieldErr.Path, Err: fieldErr.Err})
+		} else {
+			result = append(result, &FieldError{Path: path, Err: err})
+		}
+	}
+	return result
+}
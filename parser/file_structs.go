@@ -1,17 +1,34 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"raygo/math"
+	"slices"
+	"strings"
+)
 
 type YamlDescription struct {
-	Colors     []NamedColorModel     `yaml:"colors"`
-	Materials  []NamedMaterialModel  `yaml:"materials"`
-	Transforms []NamedTransformModel `yaml:"transforms"`
-	Patterns   PatternContainer      `yaml:"patterns"`
-	Scene      SceneContainer        `yaml:"scene"`
-	Light      LightModel            `yaml:"light"`
-	Camera     CameraModel           `yaml:"camera"`
-	Width      int                   `yaml:"width"`
-	Height     int                   `yaml:"height"`
+	Include           []string                `yaml:"include"` // files whose lists are merged into this description
+	Colors            []NamedColorModel       `yaml:"colors"`
+	Materials         []NamedMaterialModel    `yaml:"materials"`
+	Transforms        []NamedTransformModel   `yaml:"transforms"`
+	Patterns          PatternContainer        `yaml:"patterns"`
+	Scene             SceneContainer          `yaml:"scene"`
+	Light             *LightModel             `yaml:"light"`
+	Lights            []LightModel            `yaml:"lights"`
+	AreaLights        []AreaLightModel        `yaml:"areaLights"`
+	DirectionalLights []DirectionalLightModel `yaml:"directionalLights"`
+	SpotLights        []SpotLightModel        `yaml:"spotLights"`
+	Camera            CameraModel             `yaml:"camera"`
+	Render            *RenderModel            `yaml:"render"`
+	Animation         *AnimationModel         `yaml:"animation"` // timeline of the keyframes of scene objects
+	Width             int                     `yaml:"width"`
+	Height            int                     `yaml:"height"`
+
+	file   string // path of the yaml file, empty if the description wasn't read from a file
+	source []byte
+	// where the elements that were merged from included files come from, keyed by their path
+	origins map[string]origin
 }
 
 type ColorModel struct {
@@ -92,6 +109,44 @@ type MaterialModel struct {
 type NamedMaterialModel struct {
 	MaterialModel `yaml:",inline"`
 	Name          string `yaml:"name"`
+	Extends       string `yaml:"extends"` // name of a material whose values are used for all unset fields
+}
+
+// inherit returns the material with all unset fields taken from parent
+func (m MaterialModel) inherit(parent MaterialModel) MaterialModel {
+	// a named color takes precedence over a raw color, so both are inherited together
+	if m.Color == "" && m.RawColor == nil {
+		m.Color = parent.Color
+		m.RawColor = parent.RawColor
+	}
+	if m.Pattern == "" {
+		m.Pattern = parent.Pattern
+	}
+	if m.Texture == nil {
+		m.Texture = parent.Texture
+	}
+	if m.Ambient == nil {
+		m.Ambient = parent.Ambient
+	}
+	if m.Diffuse == nil {
+		m.Diffuse = parent.Diffuse
+	}
+	if m.Specular == nil {
+		m.Specular = parent.Specular
+	}
+	if m.Shininess == nil {
+		m.Shininess = parent.Shininess
+	}
+	if m.Reflective == nil {
+		m.Reflective = parent.Reflective
+	}
+	if m.Transparency == nil {
+		m.Transparency = parent.Transparency
+	}
+	if m.RefractiveIndex == nil {
+		m.RefractiveIndex = parent.RefractiveIndex
+	}
+	return m
 }
 
 type TextureModel struct {
@@ -110,21 +165,48 @@ type VectorModel struct {
 }
 
 type SceneContainer struct {
-	Planes    []PlaneModel    `yaml:"planes"`
-	Cubes     []CubeModel     `yaml:"cubes"`
-	Spheres   []SphereModel   `yaml:"spheres"`
-	Groups    []GroupModel    `yaml:"groups"`
-	Triangles []TriangleModel `yaml:"triangles"`
-	Cylinders []CylinderModel `yaml:"cylinders"`
-	Cones     []ConeModel     `yaml:"cones"`
-	Objects   []ObjectModel   `yaml:"objects"`
+	Planes       []PlaneModel       `yaml:"planes"`
+	Cubes        []CubeModel        `yaml:"cubes"`
+	Spheres      []SphereModel      `yaml:"spheres"`
+	Groups       []GroupModel       `yaml:"groups"`
+	Triangles    []TriangleModel    `yaml:"triangles"`
+	Cylinders    []CylinderModel    `yaml:"cylinders"`
+	Cones        []ConeModel        `yaml:"cones"`
+	Tori         []TorusModel       `yaml:"tori"`
+	Disks        []DiskModel        `yaml:"disks"`
+	Quads        []QuadModel        `yaml:"quads"`
+	SDFs         []SdfModel         `yaml:"sdfs"`
+	Heightfields []HeightfieldModel `yaml:"heightfields"`
+	Objects      []ObjectModel      `yaml:"objects"`
+	CSGs         []CsgModel         `yaml:"csg"`
 }
 
 type CommonSceneObject struct {
-	Name       string           `yaml:"name"`
-	Material   string           `yaml:"material"`
-	Transform  string           `yaml:"transform"`
-	Transforms []TransformModel `yaml:"transforms"`
+	Name          string           `yaml:"name"`
+	Material      string           `yaml:"material"`
+	Transform     string           `yaml:"transform"`
+	Transforms    []TransformModel `yaml:"transforms"`
+	EndTransforms []TransformModel `yaml:"endTransforms"` // reached when the shutter of the camera closes
+	Keyframes     []KeyframeModel  `yaml:"keyframes"`
+}
+
+// KeyframeModel animates a scene object, unset values are not animated.
+// Translations move the object in the space of its parent, rotations and scaling happen in object space.
+type KeyframeModel struct {
+	Time            *float64     `yaml:"time"`   // seconds
+	Easing          string       `yaml:"easing"` // linear, easeIn, easeOut or easeInOut, from the previous keyframe to this one
+	Translation     *VectorModel `yaml:"translation"`
+	Rotation        *VectorModel `yaml:"rotation"` // degrees around the x, y and z axis
+	Scale           *VectorModel `yaml:"scale"`
+	Color           string       `yaml:"color"`
+	RawColor        *ColorModel  `yaml:"rawColor"`
+	Ambient         *float64     `yaml:"ambient"`
+	Diffuse         *float64     `yaml:"diffuse"`
+	Specular        *float64     `yaml:"specular"`
+	Shininess       *float64     `yaml:"shininess"`
+	Reflective      *float64     `yaml:"reflective"`
+	Transparency    *float64     `yaml:"transparency"`
+	RefractiveIndex *float64     `yaml:"refractiveIndex"`
 }
 
 type PlaneModel struct {
@@ -142,6 +224,80 @@ type CubeModel struct {
 type GroupModel struct {
 	CommonSceneObject `yaml:",inline"`
 	Children          []string `yaml:"children"`
+	Bvh               bool     `yaml:"bvh"` // subdivide the children into a bounding volume hierarchy
+}
+
+type DiskModel struct {
+	CommonSceneObject `yaml:",inline"`
+	Radius            *float64 `yaml:"radius"`      // defaults to 1
+	InnerRadius       *float64 `yaml:"innerRadius"` // radius of the hole in the middle, defaults to 0
+}
+
+type QuadModel struct {
+	CommonSceneObject `yaml:",inline"`
+}
+
+type SdfModel struct {
+	CommonSceneObject `yaml:",inline"`
+	Shape             *SdfNodeModel `yaml:"shape"`
+}
+
+// SdfNodeModel is a node of a signed distance field, exactly one of its fields has to be set
+type SdfNodeModel struct {
+	Sphere       *SdfSphereModel      `yaml:"sphere"`
+	Box          *SdfBoxModel         `yaml:"box"`
+	Torus        *SdfTorusModel       `yaml:"torus"`
+	Capsule      *SdfCapsuleModel     `yaml:"capsule"`
+	Union        *SdfCombinationModel `yaml:"union"`
+	Subtraction  *SdfCombinationModel `yaml:"subtraction"` // removes every following shape from the first one
+	Intersection *SdfCombinationModel `yaml:"intersection"`
+	Repeat       *SdfRepeatModel      `yaml:"repeat"`
+	Twist        *SdfTwistModel       `yaml:"twist"`
+}
+
+type SdfSphereModel struct {
+	Center *PointModel `yaml:"center"` // defaults to the origin
+	Radius *float64    `yaml:"radius"` // defaults to 1
+}
+
+type SdfBoxModel struct {
+	Center *PointModel  `yaml:"center"` // defaults to the origin
+	Size   *VectorModel `yaml:"size"`   // edge lengths along x, y and z
+}
+
+type SdfTorusModel struct {
+	Center      *PointModel `yaml:"center"`      // defaults to the origin
+	MajorRadius *float64    `yaml:"majorRadius"` // defaults to 1
+	MinorRadius *float64    `yaml:"minorRadius"` // defaults to 0.25
+}
+
+type SdfCapsuleModel struct {
+	A      *PointModel `yaml:"a"`
+	B      *PointModel `yaml:"b"`
+	Radius *float64    `yaml:"radius"`
+}
+
+type SdfCombinationModel struct {
+	Smoothness float64        `yaml:"smoothness"` // distance over which the shapes blend, 0 gives hard edges
+	Shapes     []SdfNodeModel `yaml:"shapes"`
+}
+
+type SdfRepeatModel struct {
+	Spacing *VectorModel   `yaml:"spacing"`
+	Count   *SdfCountModel `yaml:"count"`
+	Shape   *SdfNodeModel  `yaml:"shape"`
+}
+
+// SdfCountModel is the number of copies along each axis, axes that are not set are not repeated
+type SdfCountModel struct {
+	X int `yaml:"x"`
+	Y int `yaml:"y"`
+	Z int `yaml:"z"`
+}
+
+type SdfTwistModel struct {
+	Angle *float64      `yaml:"angle"` // degrees of rotation around the y axis per unit of height
+	Shape *SdfNodeModel `yaml:"shape"`
 }
 
 type TriangleModel struct {
@@ -162,9 +318,29 @@ type ConeModel struct {
 	CylinderModel `yaml:",inline"`
 }
 
+type TorusModel struct {
+	CommonSceneObject `yaml:",inline"`
+	MajorRadius       *float64 `yaml:"majorRadius"` // distance from the center to the middle of the tube, defaults to 1
+	MinorRadius       *float64 `yaml:"minorRadius"` // radius of the tube, defaults to 0.25
+}
+
+type CsgModel struct {
+	CommonSceneObject `yaml:",inline"`
+	Operation         string `yaml:"operation"` // union, intersection or difference
+	Left              string `yaml:"left"`
+	Right             string `yaml:"right"`
+}
+
 type ObjectModel struct {
 	CommonSceneObject `yaml:",inline"`
-	File              string `yaml:"file"`
+	File              string            `yaml:"file"`
+	Materials         map[string]string `yaml:"materials"` // replaces materials of the mtl files by name
+}
+
+type HeightfieldModel struct {
+	CommonSceneObject `yaml:",inline"`
+	File              string       `yaml:"file"`  // grayscale png or jpeg, white is the highest point
+	Scale             *VectorModel `yaml:"scale"` // width, height and depth of the terrain, defaults to 1
 }
 
 type LightModel struct {
@@ -172,18 +348,80 @@ type LightModel struct {
 	Intensity *ColorModel `yaml:"intensity"`
 }
 
+type AreaLightModel struct {
+	Corner    *PointModel  `yaml:"corner"`
+	U         *VectorModel `yaml:"u"`
+	V         *VectorModel `yaml:"v"`
+	USteps    int          `yaml:"uSteps"`
+	VSteps    int          `yaml:"vSteps"`
+	Jitter    bool         `yaml:"jitter"`
+	Intensity *ColorModel  `yaml:"intensity"`
+}
+
+type DirectionalLightModel struct {
+	Direction *VectorModel `yaml:"direction"`
+	Intensity *ColorModel  `yaml:"intensity"`
+}
+
+type SpotLightModel struct {
+	Position   *PointModel  `yaml:"p"`
+	Direction  *VectorModel `yaml:"direction"`
+	InnerAngle float64      `yaml:"innerAngle"`
+	OuterAngle float64      `yaml:"outerAngle"`
+	Intensity  *ColorModel  `yaml:"intensity"`
+}
+
+// GetLights merges the legacy single 'light' entry with the 'lights' list
+func (yml *YamlDescription) GetLights() []LightModel {
+	lights := make([]LightModel, 0, len(yml.Lights)+1)
+	if yml.Light != nil {
+		lights = append(lights, *yml.Light)
+	}
+	lights = append(lights, yml.Lights...)
+	return lights
+}
+
+type RenderModel struct {
+	Samples   int      `yaml:"samples"` // per axis, a pixel is sampled samples x samples times
+	Filter    string   `yaml:"filter"`  // box, tent or gaussian
+	Seed      uint64   `yaml:"seed"`
+	Adaptive  bool     `yaml:"adaptive"`
+	Threshold *float64 `yaml:"threshold"`
+	MaxDepth  *int     `yaml:"maxDepth"`
+}
+
+type AnimationModel struct {
+	Time float64 `yaml:"timeSec"`
+	Fps  float64 `yaml:"fps"`
+}
+
 type CircularCameraAnimation struct {
-	Degrees float64 `yaml:"degrees"`
-	Time    float64 `yaml:"timeSec"`
-	Fps     float64 `yaml:"fps"`
+	Degrees float64          `yaml:"degrees"`
+	Time    float64          `yaml:"timeSec"`
+	Fps     float64          `yaml:"fps"`
+	Path    *CameraPathModel `yaml:"path"` // replaces the circular motion
+}
+
+// CameraPathModel moves the camera along splines through the control points
+type CameraPathModel struct {
+	Spline string       `yaml:"spline"` // catmullRom (default) or bezier
+	From   []PointModel `yaml:"from"`
+	To     []PointModel `yaml:"to"`
+	Fov    []float64    `yaml:"fov"` // degrees, evenly spread over the animation
 }
 
 type CameraModel struct {
-	From      *PointModel              `yaml:"from"`
-	To        *PointModel              `yaml:"to"`
-	LookAt    string                   `yaml:"lookAt"`
-	Up        *VectorModel             `yaml:"up"`
-	Animation *CircularCameraAnimation `yaml:"animation"`
+	From          *PointModel              `yaml:"from"`
+	To            *PointModel              `yaml:"to"`
+	LookAt        string                   `yaml:"lookAt"`
+	Up            *VectorModel             `yaml:"up"`
+	Fov           *float64                 `yaml:"fov"` // degrees
+	Aperture      *float64                 `yaml:"aperture"`
+	Shutter       *float64                 `yaml:"shutter"`       // fraction of a frame the shutter stays open
+	FocalDistance *float64                 `yaml:"focalDistance"` // defaults to the distance between from and to
+	Projection    string                   `yaml:"projection"`    // perspective, orthographic, fisheye or equirectangular
+	ViewWidth     *float64                 `yaml:"viewWidth"`     // required for orthographic projection
+	Animation     *CircularCameraAnimation `yaml:"animation"`
 }
 
 // validation
@@ -191,20 +429,115 @@ type CameraModel struct {
 func (c *CameraModel) validate() []error {
 	valResult := make([]error, 0)
 
-	if c.From == nil {
-		valResult = append(valResult, fmt.Errorf("camera requires a 'from' position"))
+	path := c.path()
+	if c.From == nil && (path == nil || len(path.From) == 0) {
+		valResult = append(valResult, atField(".from", fmt.Errorf("camera requires a 'from' position")))
+	}
+
+	if c.To == nil && c.LookAt == "" && (path == nil || len(path.To) == 0) {
+		valResult = append(valResult, atField(".to", fmt.Errorf("camera requires either a valid 'to' or 'lookAt' reference")))
 	}
 
-	if c.To == nil && c.LookAt == "" {
-		valResult = append(valResult, fmt.Errorf("camera requires either a valid 'to' or 'lookAt' reference"))
+	if c.LookAt != "" && path != nil && len(path.To) > 0 {
+		err := fmt.Errorf("camera cannot follow a 'to' path and look at '%v' at the same time", c.LookAt)
+		valResult = append(valResult, atField(".lookAt", err))
 	}
 
 	if c.Up == nil {
-		valResult = append(valResult, fmt.Errorf("camera requires an 'up' vector"))
+		valResult = append(valResult, atField(".up", fmt.Errorf("camera requires an 'up' vector")))
+	} else if c.Up.X == 0.0 && c.Up.Y == 0.0 && c.Up.Z == 0.0 {
+		valResult = append(valResult, atField(".up", fmt.Errorf("the 'up' vector of the camera must not be a zero vector")))
+	} else if c.From != nil && c.To != nil {
+		forward := math.CreatePoint(c.To.X, c.To.Y, c.To.Z).Subtract(math.CreatePoint(c.From.X, c.From.Y, c.From.Z))
+		up := math.CreateVector(c.Up.X, c.Up.Y, c.Up.Z)
+		if forward.Magnitude() < math.EPSILON {
+			valResult = append(valResult, atField(".to", fmt.Errorf("camera 'from' and 'to' must not be the same point")))
+		} else if forward.Cross(up).Magnitude() < math.EPSILON {
+			valResult = append(valResult, atField(".up", fmt.Errorf("the 'up' vector of the camera must not be parallel to its viewing direction")))
+		}
+	}
+
+	if c.Projection != "" && !slices.Contains([]string{"perspective", "orthographic", "fisheye", "equirectangular"}, c.Projection) {
+		err := fmt.Errorf("camera has unknown projection '%v', expected perspective, orthographic, fisheye or equirectangular", c.Projection)
+		valResult = append(valResult, atField(".projection", err))
+	}
+
+	if c.Fov != nil {
+		valResult = append(valResult, c.validateFov(".fov", *c.Fov)...)
+	}
+	if path != nil {
+		for i, fov := range path.Fov {
+			valResult = append(valResult, c.validateFov(fmt.Sprintf(".animation.path.fov[%d]", i), fov)...)
+		}
+	}
+
+	if c.Projection == "orthographic" && c.ViewWidth == nil {
+		valResult = append(valResult, atField(".viewWidth", fmt.Errorf("orthographic camera requires a 'viewWidth'")))
+	}
+
+	if c.ViewWidth != nil && *c.ViewWidth <= 0.0 {
+		valResult = append(valResult, atField(".viewWidth", fmt.Errorf("camera 'viewWidth'(%v) must be greater than 0", *c.ViewWidth)))
+	}
+
+	if c.Aperture != nil && *c.Aperture < 0.0 {
+		valResult = append(valResult, atField(".aperture", fmt.Errorf("camera 'aperture'(%v) must not be negative", *c.Aperture)))
+	}
+
+	if c.Shutter != nil && (*c.Shutter < 0.0 || *c.Shutter > 1.0) {
+		valResult = append(valResult, atField(".shutter", fmt.Errorf("camera 'shutter'(%v) must be between 0 and 1", *c.Shutter)))
+	}
+
+	if c.FocalDistance != nil && *c.FocalDistance <= 0.0 {
+		valResult = append(valResult, atField(".focalDistance", fmt.Errorf("camera 'focalDistance'(%v) must be greater than 0", *c.FocalDistance)))
 	}
 
 	if c.Animation != nil {
-		valResult = append(valResult, c.Animation.validate()...)
+		valResult = append(valResult, atPath(".animation", c.Animation.validate())...)
+	}
+
+	return valResult
+}
+
+func (c *CameraModel) validateFov(field string, fov float64) []error {
+	valResult := make([]error, 0)
+
+	if c.Projection == "fisheye" {
+		if fov <= 0.0 || fov > 360.0 {
+			valResult = append(valResult, atField(field, fmt.Errorf("fisheye camera 'fov'(%v) must be between 0 and 360 degrees", fov)))
+		}
+	} else if fov <= 0.0 || fov >= 180.0 {
+		valResult = append(valResult, atField(field, fmt.Errorf("camera 'fov'(%v) must be between 0 and 180 degrees", fov)))
+	}
+
+	return valResult
+}
+
+// path returns the spline path of the camera animation, nil if there is none
+func (c *CameraModel) path() *CameraPathModel {
+	if c.Animation == nil {
+		return nil
+	}
+	return c.Animation.Path
+}
+
+func (r *RenderModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if r.Samples < 0 {
+		valResult = append(valResult, atField(".samples", fmt.Errorf("render 'samples' must not be negative")))
+	}
+
+	if r.Filter != "" && !slices.Contains([]string{"box", "tent", "gaussian"}, r.Filter) {
+		err := fmt.Errorf("render has unknown filter '%v', expected box, tent or gaussian", r.Filter)
+		valResult = append(valResult, atField(".filter", err))
+	}
+
+	if r.Threshold != nil && *r.Threshold < 0.0 {
+		valResult = append(valResult, atField(".threshold", fmt.Errorf("render 'threshold' must not be negative")))
+	}
+
+	if r.MaxDepth != nil && *r.MaxDepth < 0 {
+		valResult = append(valResult, atField(".maxDepth", fmt.Errorf("render 'maxDepth' must not be negative")))
 	}
 
 	return valResult
@@ -214,7 +547,54 @@ func (anim *CircularCameraAnimation) validate() []error {
 	valResult := make([]error, 0)
 
 	if anim.Time <= 0.0 {
-		valResult = append(valResult, fmt.Errorf("the camera animation requires a 'time' value of > 0.0"))
+		valResult = append(valResult, atField(".timeSec", fmt.Errorf("the camera animation requires a 'time' value of > 0.0")))
+	}
+
+	if anim.Path != nil {
+		if anim.Degrees != 0.0 {
+			valResult = append(valResult, atField(".degrees", fmt.Errorf("the camera animation cannot combine 'degrees' with a 'path'")))
+		}
+		valResult = append(valResult, atPath(".path", anim.Path.validate())...)
+	}
+
+	return valResult
+}
+
+func (p *CameraPathModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if p.Spline != "" && !slices.Contains([]string{"catmullRom", "bezier"}, p.Spline) {
+		err := fmt.Errorf("camera path has unknown spline '%v', expected catmullRom or bezier", p.Spline)
+		valResult = append(valResult, atField(".spline", err))
+	}
+
+	if len(p.From) == 0 && len(p.To) == 0 && len(p.Fov) == 0 {
+		valResult = append(valResult, fmt.Errorf("camera path requires control points for 'from' or 'to', or 'fov' values"))
+	}
+
+	if p.Spline == "bezier" {
+		if len(p.From) > 1 && (len(p.From)-1)%3 != 0 {
+			err := fmt.Errorf("bezier path requires 3n+1 'from' points, got %v", len(p.From))
+			valResult = append(valResult, atField(".from", err))
+		}
+		if len(p.To) > 1 && (len(p.To)-1)%3 != 0 {
+			err := fmt.Errorf("bezier path requires 3n+1 'to' points, got %v", len(p.To))
+			valResult = append(valResult, atField(".to", err))
+		}
+	}
+
+	return valResult
+}
+
+func (anim *AnimationModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if anim.Time <= 0.0 {
+		valResult = append(valResult, atField(".timeSec", fmt.Errorf("the animation requires a 'timeSec' value of > 0.0")))
+	}
+
+	if anim.Fps <= 0.0 {
+		valResult = append(valResult, atField(".fps", fmt.Errorf("the animation requires a 'fps' value of > 0.0")))
 	}
 
 	return valResult
@@ -224,11 +604,77 @@ func (l *LightModel) validate() []error {
 	valResult := make([]error, 0)
 
 	if l.Position == nil {
-		valResult = append(valResult, fmt.Errorf("light requires a 'p' (position) value"))
+		valResult = append(valResult, atField(".p", fmt.Errorf("light requires a 'p' (position) value")))
 	}
 
 	if l.Intensity == nil {
-		valResult = append(valResult, fmt.Errorf("light requires an 'intensity' field for its color"))
+		valResult = append(valResult, atField(".intensity", fmt.Errorf("light requires an 'intensity' field for its color")))
+	}
+
+	return valResult
+}
+
+func (l *AreaLightModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if l.Corner == nil {
+		valResult = append(valResult, atField(".corner", fmt.Errorf("area light requires a 'corner' position")))
+	}
+
+	if l.U == nil || l.V == nil {
+		valResult = append(valResult, atField(".u", fmt.Errorf("area light requires the edge vectors 'u' and 'v'")))
+	}
+
+	if l.USteps <= 0 || l.VSteps <= 0 {
+		valResult = append(valResult, atField(".uSteps", fmt.Errorf("area light requires 'uSteps' and 'vSteps' values greater than 0")))
+	}
+
+	if l.Intensity == nil {
+		valResult = append(valResult, atField(".intensity", fmt.Errorf("area light requires an 'intensity' field for its color")))
+	}
+
+	return valResult
+}
+
+func (l *DirectionalLightModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if l.Direction == nil {
+		valResult = append(valResult, atField(".direction", fmt.Errorf("directional light requires a 'direction' vector")))
+	} else if l.Direction.X == 0.0 && l.Direction.Y == 0.0 && l.Direction.Z == 0.0 {
+		valResult = append(valResult, atField(".direction", fmt.Errorf("the 'direction' of a directional light must not be a zero vector")))
+	}
+
+	if l.Intensity == nil {
+		valResult = append(valResult, atField(".intensity", fmt.Errorf("directional light requires an 'intensity' field for its color")))
+	}
+
+	return valResult
+}
+
+func (l *SpotLightModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if l.Position == nil {
+		valResult = append(valResult, atField(".p", fmt.Errorf("spot light requires a 'p' (position) value")))
+	}
+
+	if l.Direction == nil {
+		valResult = append(valResult, atField(".direction", fmt.Errorf("spot light requires a 'direction' vector")))
+	} else if l.Direction.X == 0.0 && l.Direction.Y == 0.0 && l.Direction.Z == 0.0 {
+		valResult = append(valResult, atField(".direction", fmt.Errorf("the 'direction' of a spot light must not be a zero vector")))
+	}
+
+	if l.OuterAngle <= 0.0 || l.OuterAngle >= 180.0 {
+		valResult = append(valResult, atField(".outerAngle", fmt.Errorf("spot light requires an 'outerAngle' between 0 and 180 degrees")))
+	}
+
+	if l.InnerAngle < 0.0 || l.InnerAngle > l.OuterAngle {
+		valResult = append(valResult, atField(".innerAngle", fmt.Errorf("the 'innerAngle'(%v) of a spot light must be between 0 and its 'outerAngle'(%v)", l.InnerAngle, l.OuterAngle)))
+	}
+
+	if l.Intensity == nil {
+		valResult = append(valResult, atField(".intensity", fmt.Errorf("spot light requires an 'intensity' field for its color")))
 	}
 
 	return valResult
@@ -238,8 +684,49 @@ func (sceneObject *CommonSceneObject) validate() []error {
 	valResult := make([]error, 0)
 
 	if sceneObject.Name == "" {
-		// I would need yaml line to be more specific
-		valResult = append(valResult, fmt.Errorf("scene objects require a non empty 'name' field"))
+		valResult = append(valResult, atField(".name", fmt.Errorf("scene objects require a non empty 'name' field")))
+	}
+
+	for i, t := range sceneObject.Transforms {
+		valResult = append(valResult, atPath(fmt.Sprintf(".transforms[%d]", i), t.validate())...)
+	}
+
+	for i, t := range sceneObject.EndTransforms {
+		valResult = append(valResult, atPath(fmt.Sprintf(".endTransforms[%d]", i), t.validate())...)
+	}
+
+	// keyframes that move the object decide where it is when the shutter closes
+	if len(sceneObject.EndTransforms) > 0 && slices.ContainsFunc(sceneObject.Keyframes, func(k KeyframeModel) bool {
+		return k.Translation != nil || k.Rotation != nil || k.Scale != nil
+	}) {
+		err := fmt.Errorf("scene object '%v' cannot combine 'endTransforms' with keyframes that move it", sceneObject.Name)
+		valResult = append(valResult, atField(".endTransforms", err))
+	}
+
+	for i, k := range sceneObject.Keyframes {
+		path := fmt.Sprintf(".keyframes[%d]", i)
+		valResult = append(valResult, atPath(path, k.validate())...)
+		if i > 0 && k.Time != nil && sceneObject.Keyframes[i-1].Time != nil && *k.Time <= *sceneObject.Keyframes[i-1].Time {
+			err := fmt.Errorf("keyframes of scene object '%v' have to be sorted by 'time'", sceneObject.Name)
+			valResult = append(valResult, atField(path+".time", err))
+		}
+	}
+
+	return valResult
+}
+
+func (k *KeyframeModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if k.Time == nil {
+		valResult = append(valResult, atField(".time", fmt.Errorf("keyframes require a 'time'")))
+	} else if *k.Time < 0.0 {
+		valResult = append(valResult, atField(".time", fmt.Errorf("keyframe 'time'(%v) must not be negative", *k.Time)))
+	}
+
+	if k.Easing != "" && !slices.Contains([]string{"linear", "easeIn", "easeOut", "easeInOut"}, k.Easing) {
+		err := fmt.Errorf("keyframe has unknown easing '%v', expected linear, easeIn, easeOut or easeInOut", k.Easing)
+		valResult = append(valResult, atField(".easing", err))
 	}
 
 	return valResult
@@ -249,7 +736,7 @@ func (obj *ObjectModel) validate() []error {
 	valResult := make([]error, 0)
 
 	if obj.File == "" {
-		valResult = append(valResult, fmt.Errorf("object '%v' requires a 'file' field from which to load the OBJ", obj.Name))
+		valResult = append(valResult, atField(".file", fmt.Errorf("object '%v' requires a 'file' field from which to load the OBJ", obj.Name)))
 	}
 
 	valResult = append(valResult, obj.CommonSceneObject.validate()...)
@@ -257,6 +744,22 @@ func (obj *ObjectModel) validate() []error {
 	return valResult
 }
 
+func (h *HeightfieldModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if h.File == "" {
+		valResult = append(valResult, atField(".file", fmt.Errorf("heightfield '%v' requires a 'file' field from which to load the heights", h.Name)))
+	}
+	if h.Scale != nil && (h.Scale.X <= 0.0 || h.Scale.Y <= 0.0 || h.Scale.Z <= 0.0) {
+		err := fmt.Errorf("scale(%v, %v, %v) of heightfield '%v' must be greater than 0", h.Scale.X, h.Scale.Y, h.Scale.Z, h.Name)
+		valResult = append(valResult, atField(".scale", err))
+	}
+
+	valResult = append(valResult, h.CommonSceneObject.validate()...)
+
+	return valResult
+}
+
 func (c *CylinderModel) validate() []error {
 	valResult := make([]error, 0)
 
@@ -266,12 +769,12 @@ func (c *CylinderModel) validate() []error {
 
 	if *c.Minimum == *c.Maximum {
 		err := fmt.Errorf("min of scene object '%v' is equal to max", c.Name)
-		valResult = append(valResult, err)
+		valResult = append(valResult, atField(".max", err))
 	}
 
 	if *c.Minimum > *c.Maximum {
 		err := fmt.Errorf("min(%v) of scene object '%v' is greater than max(%v)", c.Minimum, c.Name, c.Maximum)
-		valResult = append(valResult, err)
+		valResult = append(valResult, atField(".min", err))
 	}
 
 	valResult = append(valResult, c.CommonSceneObject.validate()...)
@@ -283,19 +786,47 @@ func (c *ConeModel) validate() []error {
 	return c.CylinderModel.validate()
 }
 
+func (t *TorusModel) validate() []error {
+	valResult := make([]error, 0)
+
+	major, minor := 1.0, 0.25
+	if t.MajorRadius != nil {
+		major = *t.MajorRadius
+		if major <= 0.0 {
+			err := fmt.Errorf("majorRadius(%v) of torus '%v' must be greater than 0", major, t.Name)
+			valResult = append(valResult, atField(".majorRadius", err))
+		}
+	}
+	if t.MinorRadius != nil {
+		minor = *t.MinorRadius
+		if minor <= 0.0 {
+			err := fmt.Errorf("minorRadius(%v) of torus '%v' must be greater than 0", minor, t.Name)
+			valResult = append(valResult, atField(".minorRadius", err))
+		}
+	}
+	if major > 0.0 && minor > 0.0 && minor >= major {
+		err := fmt.Errorf("minorRadius(%v) of torus '%v' must be smaller than its majorRadius(%v)", minor, t.Name, major)
+		valResult = append(valResult, atField(".minorRadius", err))
+	}
+
+	valResult = append(valResult, t.CommonSceneObject.validate()...)
+
+	return valResult
+}
+
 func (t *TriangleModel) validate() []error {
 	valResult := make([]error, 0)
 
 	if t.P1 == nil {
-		valResult = append(valResult, fmt.Errorf("triangle '%v' requires field 'p1'", t.Name))
+		valResult = append(valResult, atField(".p1", fmt.Errorf("triangle '%v' requires field 'p1'", t.Name)))
 	}
 
 	if t.P2 == nil {
-		valResult = append(valResult, fmt.Errorf("triangle '%v' requires field 'p2'", t.Name))
+		valResult = append(valResult, atField(".p2", fmt.Errorf("triangle '%v' requires field 'p2'", t.Name)))
 	}
 
 	if t.P3 == nil {
-		valResult = append(valResult, fmt.Errorf("triangle '%v' requires field 'p3'", t.Name))
+		valResult = append(valResult, atField(".p3", fmt.Errorf("triangle '%v' requires field 'p3'", t.Name)))
 	}
 
 	valResult = append(valResult, t.CommonSceneObject.validate()...)
@@ -307,7 +838,7 @@ func (g *GroupModel) validate() []error {
 	valResult := make([]error, 0)
 
 	if len(g.Children) == 0 {
-		valResult = append(valResult, fmt.Errorf("group '%v' requires children", g.Name))
+		valResult = append(valResult, atField(".children", fmt.Errorf("group '%v' requires children", g.Name)))
 	}
 
 	valResult = append(valResult, g.CommonSceneObject.validate()...)
@@ -315,6 +846,212 @@ func (g *GroupModel) validate() []error {
 	return valResult
 }
 
+func (c *CsgModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if !slices.Contains([]string{"union", "intersection", "difference"}, c.Operation) {
+		err := fmt.Errorf("csg '%v' has unknown operation '%v', expected union, intersection or difference",
+			c.Name, c.Operation)
+		valResult = append(valResult, atField(".operation", err))
+	}
+
+	if c.Left == "" || c.Right == "" {
+		valResult = append(valResult, atField(".left", fmt.Errorf("csg '%v' requires a left and a right operand", c.Name)))
+	} else if c.Left == c.Right {
+		valResult = append(valResult, atField(".right", fmt.Errorf("csg '%v' cannot use '%v' as both operands", c.Name, c.Left)))
+	}
+
+	valResult = append(valResult, c.CommonSceneObject.validate()...)
+
+	return valResult
+}
+
+func (d *DiskModel) validate() []error {
+	valResult := make([]error, 0)
+
+	radius, inner := 1.0, 0.0
+	if d.Radius != nil {
+		radius = *d.Radius
+		if radius <= 0.0 {
+			err := fmt.Errorf("radius(%v) of disk '%v' must be greater than 0", radius, d.Name)
+			valResult = append(valResult, atField(".radius", err))
+		}
+	}
+	if d.InnerRadius != nil {
+		inner = *d.InnerRadius
+		if inner < 0.0 {
+			err := fmt.Errorf("innerRadius(%v) of disk '%v' must not be negative", inner, d.Name)
+			valResult = append(valResult, atField(".innerRadius", err))
+		}
+	}
+	if radius > 0.0 && inner >= radius {
+		err := fmt.Errorf("innerRadius(%v) of disk '%v' must be smaller than its radius(%v)", inner, d.Name, radius)
+		valResult = append(valResult, atField(".innerRadius", err))
+	}
+
+	valResult = append(valResult, d.CommonSceneObject.validate()...)
+
+	return valResult
+}
+
+func (s *SdfModel) validate() []error {
+	valResult := make([]error, 0)
+
+	if s.Shape == nil {
+		valResult = append(valResult, fmt.Errorf("sdf '%v' requires a 'shape'", s.Name))
+	} else {
+		valResult = append(valResult, atPath(".shape", s.Shape.validate(s.Name))...)
+	}
+
+	valResult = append(valResult, s.CommonSceneObject.validate()...)
+
+	return valResult
+}
+
+func (n *SdfNodeModel) validate(name string) []error {
+	valResult := make([]error, 0)
+
+	kinds := make([]string, 0)
+	add := func(kind string, set bool, validate func() []error) {
+		if set {
+			kinds = append(kinds, kind)
+			valResult = append(valResult, atPath("."+kind, validate())...)
+		}
+	}
+	add("sphere", n.Sphere != nil, func() []error { return n.Sphere.validate(name) })
+	add("box", n.Box != nil, func() []error { return n.Box.validate(name) })
+	add("torus", n.Torus != nil, func() []error { return n.Torus.validate(name) })
+	add("capsule", n.Capsule != nil, func() []error { return n.Capsule.validate(name) })
+	add("union", n.Union != nil, func() []error { return n.Union.validate(name) })
+	add("subtraction", n.Subtraction != nil, func() []error { return n.Subtraction.validate(name) })
+	add("intersection", n.Intersection != nil, func() []error { return n.Intersection.validate(name) })
+	add("repeat", n.Repeat != nil, func() []error { return n.Repeat.validate(name) })
+	add("twist", n.Twist != nil, func() []error { return n.Twist.validate(name) })
+
+	switch len(kinds) {
+	case 0:
+		valResult = append(valResult, fmt.Errorf("sdf '%v' has a node without a shape, expected sphere, box, torus, capsule, union, subtraction, intersection, repeat or twist", name))
+	case 1:
+	default:
+		valResult = append(valResult, fmt.Errorf("sdf '%v' has a node with more than one shape: %v", name, strings.Join(kinds, ", ")))
+	}
+
+	return valResult
+}
+
+func (s *SdfSphereModel) validate(name string) []error {
+	valResult := make([]error, 0)
+	if s.Radius != nil && *s.Radius <= 0.0 {
+		err := fmt.Errorf("sphere radius(%v) of sdf '%v' must be greater than 0", *s.Radius, name)
+		valResult = append(valResult, atField(".radius", err))
+	}
+	return valResult
+}
+
+func (b *SdfBoxModel) validate(name string) []error {
+	valResult := make([]error, 0)
+	if b.Size == nil {
+		valResult = append(valResult, fmt.Errorf("box of sdf '%v' requires a 'size'", name))
+	} else if b.Size.X <= 0.0 || b.Size.Y <= 0.0 || b.Size.Z <= 0.0 {
+		err := fmt.Errorf("box size(%v, %v, %v) of sdf '%v' must be greater than 0", b.Size.X, b.Size.Y, b.Size.Z, name)
+		valResult = append(valResult, atField(".size", err))
+	}
+	return valResult
+}
+
+func (t *SdfTorusModel) validate(name string) []error {
+	valResult := make([]error, 0)
+
+	major, minor := 1.0, 0.25
+	if t.MajorRadius != nil {
+		major = *t.MajorRadius
+		if major <= 0.0 {
+			err := fmt.Errorf("torus majorRadius(%v) of sdf '%v' must be greater than 0", major, name)
+			valResult = append(valResult, atField(".majorRadius", err))
+		}
+	}
+	if t.MinorRadius != nil {
+		minor = *t.MinorRadius
+		if minor <= 0.0 {
+			err := fmt.Errorf("torus minorRadius(%v) of sdf '%v' must be greater than 0", minor, name)
+			valResult = append(valResult, atField(".minorRadius", err))
+		}
+	}
+	if major > 0.0 && minor > 0.0 && minor >= major {
+		err := fmt.Errorf("torus minorRadius(%v) of sdf '%v' must be smaller than its majorRadius(%v)", minor, name, major)
+		valResult = append(valResult, atField(".minorRadius", err))
+	}
+
+	return valResult
+}
+
+func (c *SdfCapsuleModel) validate(name string) []error {
+	valResult := make([]error, 0)
+	if c.A == nil || c.B == nil {
+		valResult = append(valResult, fmt.Errorf("capsule of sdf '%v' requires the end points 'a' and 'b'", name))
+	}
+	if c.Radius == nil {
+		valResult = append(valResult, fmt.Errorf("capsule of sdf '%v' requires a 'radius'", name))
+	} else if *c.Radius <= 0.0 {
+		err := fmt.Errorf("capsule radius(%v) of sdf '%v' must be greater than 0", *c.Radius, name)
+		valResult = append(valResult, atField(".radius", err))
+	}
+	return valResult
+}
+
+func (c *SdfCombinationModel) validate(name string) []error {
+	valResult := make([]error, 0)
+	if c.Smoothness < 0.0 {
+		err := fmt.Errorf("smoothness(%v) of sdf '%v' must not be negative", c.Smoothness, name)
+		valResult = append(valResult, atField(".smoothness", err))
+	}
+	if len(c.Shapes) < 2 {
+		err := fmt.Errorf("combination of sdf '%v' requires at least 2 shapes, got %v", name, len(c.Shapes))
+		valResult = append(valResult, atField(".shapes", err))
+	}
+	for i, shape := range c.Shapes {
+		valResult = append(valResult, atPath(fmt.Sprintf(".shapes[%d]", i), shape.validate(name))...)
+	}
+	return valResult
+}
+
+func (r *SdfRepeatModel) validate(name string) []error {
+	valResult := make([]error, 0)
+	if r.Spacing == nil {
+		valResult = append(valResult, fmt.Errorf("repeat of sdf '%v' requires a 'spacing'", name))
+	} else if r.Spacing.X < 0.0 || r.Spacing.Y < 0.0 || r.Spacing.Z < 0.0 {
+		err := fmt.Errorf("repeat spacing(%v, %v, %v) of sdf '%v' must not be negative", r.Spacing.X, r.Spacing.Y, r.Spacing.Z, name)
+		valResult = append(valResult, atField(".spacing", err))
+	}
+	if r.Count != nil && (r.Count.X < 0 || r.Count.Y < 0 || r.Count.Z < 0) {
+		err := fmt.Errorf("repeat count(%v, %v, %v) of sdf '%v' must not be negative", r.Count.X, r.Count.Y, r.Count.Z, name)
+		valResult = append(valResult, atField(".count", err))
+	}
+	if r.Shape == nil {
+		valResult = append(valResult, fmt.Errorf("repeat of sdf '%v' requires a 'shape'", name))
+	} else {
+		valResult = append(valResult, atPath(".shape", r.Shape.validate(name))...)
+	}
+	return valResult
+}
+
+func (t *SdfTwistModel) validate(name string) []error {
+	valResult := make([]error, 0)
+	if t.Angle == nil {
+		valResult = append(valResult, fmt.Errorf("twist of sdf '%v' requires an 'angle'", name))
+	}
+	if t.Shape == nil {
+		valResult = append(valResult, fmt.Errorf("twist of sdf '%v' requires a 'shape'", name))
+	} else {
+		valResult = append(valResult, atPath(".shape", t.Shape.validate(name))...)
+	}
+	return valResult
+}
+
+func (q *QuadModel) validate() []error {
+	return q.CommonSceneObject.validate()
+}
+
 func (c *CubeModel) validate() []error {
 	return c.CommonSceneObject.validate()
 }
@@ -327,39 +1064,117 @@ func (p *PlaneModel) validate() []error {
 	return p.CommonSceneObject.validate()
 }
 
+// sceneObjects returns the common part of every scene object
+func (scene *SceneContainer) sceneObjects() []CommonSceneObject {
+	objects := make([]CommonSceneObject, 0)
+	for _, p := range scene.Planes {
+		objects = append(objects, p.CommonSceneObject)
+	}
+	for _, c := range scene.Cubes {
+		objects = append(objects, c.CommonSceneObject)
+	}
+	for _, s := range scene.Spheres {
+		objects = append(objects, s.CommonSceneObject)
+	}
+	for _, g := range scene.Groups {
+		objects = append(objects, g.CommonSceneObject)
+	}
+	for _, t := range scene.Triangles {
+		objects = append(objects, t.CommonSceneObject)
+	}
+	for _, c := range scene.Cylinders {
+		objects = append(objects, c.CommonSceneObject)
+	}
+	for _, c := range scene.Cones {
+		objects = append(objects, c.CommonSceneObject)
+	}
+	for _, t := range scene.Tori {
+		objects = append(objects, t.CommonSceneObject)
+	}
+	for _, d := range scene.Disks {
+		objects = append(objects, d.CommonSceneObject)
+	}
+	for _, q := range scene.Quads {
+		objects = append(objects, q.CommonSceneObject)
+	}
+	for _, s := range scene.SDFs {
+		objects = append(objects, s.CommonSceneObject)
+	}
+	for _, h := range scene.Heightfields {
+		objects = append(objects, h.CommonSceneObject)
+	}
+	for _, o := range scene.Objects {
+		objects = append(objects, o.CommonSceneObject)
+	}
+	for _, c := range scene.CSGs {
+		objects = append(objects, c.CommonSceneObject)
+	}
+	return objects
+}
+
+func (scene *SceneContainer) hasKeyframes() bool {
+	return slices.ContainsFunc(scene.sceneObjects(), func(o CommonSceneObject) bool {
+		return len(o.Keyframes) > 0
+	})
+}
+
 func (scene *SceneContainer) validate() []error {
 	valResult := make([]error, 0)
 
-	for _, p := range scene.Planes {
-		valResult = append(valResult, p.validate()...)
+	for i, p := range scene.Planes {
+		valResult = append(valResult, atPath(fmt.Sprintf(".planes[%d]", i), p.validate())...)
 	}
 
-	for _, c := range scene.Cubes {
-		valResult = append(valResult, c.validate()...)
+	for i, c := range scene.Cubes {
+		valResult = append(valResult, atPath(fmt.Sprintf(".cubes[%d]", i), c.validate())...)
 	}
 
-	for _, s := range scene.Spheres {
-		valResult = append(valResult, s.validate()...)
+	for i, s := range scene.Spheres {
+		valResult = append(valResult, atPath(fmt.Sprintf(".spheres[%d]", i), s.validate())...)
 	}
 
-	for _, g := range scene.Groups {
-		valResult = append(valResult, g.validate()...)
+	for i, g := range scene.Groups {
+		valResult = append(valResult, atPath(fmt.Sprintf(".groups[%d]", i), g.validate())...)
 	}
 
-	for _, t := range scene.Triangles {
-		valResult = append(valResult, t.validate()...)
+	for i, t := range scene.Triangles {
+		valResult = append(valResult, atPath(fmt.Sprintf(".triangles[%d]", i), t.validate())...)
 	}
 
-	for _, c := range scene.Cylinders {
-		valResult = append(valResult, c.validate()...)
+	for i, c := range scene.Cylinders {
+		valResult = append(valResult, atPath(fmt.Sprintf(".cylinders[%d]", i), c.validate())...)
 	}
 
-	for _, c := range scene.Cones {
-		valResult = append(valResult, c.validate()...)
+	for i, c := range scene.Cones {
+		valResult = append(valResult, atPath(fmt.Sprintf(".cones[%d]", i), c.validate())...)
 	}
 
-	for _, o := range scene.Objects {
-		valResult = append(valResult, o.validate()...)
+	for i, t := range scene.Tori {
+		valResult = append(valResult, atPath(fmt.Sprintf(".tori[%d]", i), t.validate())...)
+	}
+
+	for i, d := range scene.Disks {
+		valResult = append(valResult, atPath(fmt.Sprintf(".disks[%d]", i), d.validate())...)
+	}
+
+	for i, q := range scene.Quads {
+		valResult = append(valResult, atPath(fmt.Sprintf(".quads[%d]", i), q.validate())...)
+	}
+
+	for i, s := range scene.SDFs {
+		valResult = append(valResult, atPath(fmt.Sprintf(".sdfs[%d]", i), s.validate())...)
+	}
+
+	for i, h := range scene.Heightfields {
+		valResult = append(valResult, atPath(fmt.Sprintf(".heightfields[%d]", i), h.validate())...)
+	}
+
+	for i, o := range scene.Objects {
+		valResult = append(valResult, atPath(fmt.Sprintf(".objects[%d]", i), o.validate())...)
+	}
+
+	for i, c := range scene.CSGs {
+		valResult = append(valResult, atPath(fmt.Sprintf(".csg[%d]", i), c.validate())...)
 	}
 
 	return valResult
@@ -369,8 +1184,7 @@ func (m *NamedMaterialModel) validate() []error {
 	valResult := make([]error, 0)
 
 	if m.Name == "" {
-		// I would need yaml line to be more specific
-		valResult = append(valResult, fmt.Errorf("named materials require a non empty 'name' field"))
+		valResult = append(valResult, atField(".name", fmt.Errorf("named materials require a non empty 'name' field")))
 	}
 
 	return valResult
@@ -380,8 +1194,10 @@ func (t *TransformModel) validate() []error {
 	valResult := make([]error, 0)
 
 	if t.Type == "" {
-		// I would need yaml line to be more specific
-		valResult = append(valResult, fmt.Errorf("transforms require a non empty 'type' field"))
+		valResult = append(valResult, atField(".type", fmt.Errorf("transforms require a non empty 'type' field")))
+	} else if !slices.Contains([]string{SCALING_TF, TRANSLATION_TF, SHEARING_TF, ROTATION_TF}, t.Type) {
+		err := fmt.Errorf("transform has unknown type '%v', expected scaling, translation, shearing or rotation", t.Type)
+		valResult = append(valResult, atField(".type", err))
 	}
 
 	return valResult
@@ -391,8 +1207,7 @@ func (t *NamedTransformModel) validate() []error {
 	valResult := make([]error, 0)
 
 	if t.Name == "" {
-		// I would need yaml line to be more specific
-		valResult = append(valResult, fmt.Errorf("named transforms require a non empty 'name' field"))
+		valResult = append(valResult, atField(".name", fmt.Errorf("named transforms require a non empty 'name' field")))
 	}
 
 	valResult = append(valResult, t.TransformModel.validate()...)
@@ -404,8 +1219,7 @@ func (c *NamedColorModel) validate() []error {
 	valResult := make([]error, 0)
 
 	if c.Name == "" {
-		// I would need yaml line to be more specific
-		valResult = append(valResult, fmt.Errorf("named colors require a non empty 'name' field"))
+		valResult = append(valResult, atField(".name", fmt.Errorf("named colors require a non empty 'name' field")))
 	}
 
 	return valResult
@@ -415,20 +1229,19 @@ func (p *DualColorPattern) validate() []error {
 	valResult := make([]error, 0)
 
 	if p.Name == "" {
-		// I would need yaml line to be more specific
-		valResult = append(valResult, fmt.Errorf("patterns require a non empty 'name' field"))
+		valResult = append(valResult, atField(".name", fmt.Errorf("patterns require a non empty 'name' field")))
 	}
 
 	if p.ColorA == "" {
-		valResult = append(valResult, fmt.Errorf("pattern '%v' requires a non empty 'colorA' field", p.Name))
+		valResult = append(valResult, atField(".colorA", fmt.Errorf("pattern '%v' requires a non empty 'colorA' field", p.Name)))
 	}
 
 	if p.ColorB == "" {
-		valResult = append(valResult, fmt.Errorf("pattern '%v' requires a non empty 'colorB' field", p.Name))
+		valResult = append(valResult, atField(".colorB", fmt.Errorf("pattern '%v' requires a non empty 'colorB' field", p.Name)))
 	}
 
-	for _, t := range p.Transforms {
-		valResult = append(valResult, t.validate()...)
+	for i, t := range p.Transforms {
+		valResult = append(valResult, atPath(fmt.Sprintf(".transforms[%d]", i), t.validate())...)
 	}
 
 	return valResult
@@ -453,20 +1266,42 @@ func (p *RingPatternModel) validate() []error {
 func (patterns *PatternContainer) validate() []error {
 	valResult := make([]error, 0)
 
-	for _, p := range patterns.Checker {
-		valResult = append(valResult, p.validate()...)
+	for i, p := range patterns.Checker {
+		valResult = append(valResult, atPath(fmt.Sprintf(".checker[%d]", i), p.validate())...)
 	}
 
-	for _, p := range patterns.Ring {
-		valResult = append(valResult, p.validate()...)
+	for i, p := range patterns.Ring {
+		valResult = append(valResult, atPath(fmt.Sprintf(".ring[%d]", i), p.validate())...)
 	}
 
-	for _, p := range patterns.Gradient {
-		valResult = append(valResult, p.validate()...)
+	for i, p := range patterns.Gradient {
+		valResult = append(valResult, atPath(fmt.Sprintf(".gradient[%d]", i), p.validate())...)
 	}
 
-	for _, p := range patterns.Stripe {
-		valResult = append(valResult, p.validate()...)
+	for i, p := range patterns.Stripe {
+		valResult = append(valResult, atPath(fmt.Sprintf(".stripe[%d]", i), p.validate())...)
+	}
+
+	return valResult
+}
+
+// validateAnimation checks the timeline of keyframes, it is either the animation or the camera animation
+func (yml *YamlDescription) validateAnimation() []error {
+	valResult := make([]error, 0)
+
+	if yml.Animation != nil {
+		valResult = append(valResult, atPath(".animation", yml.Animation.validate())...)
+	}
+
+	camera := yml.Camera.Animation
+	if yml.Animation == nil && camera == nil && yml.Scene.hasKeyframes() {
+		err := fmt.Errorf("scene objects with keyframes require an 'animation' with 'timeSec' and 'fps'")
+		valResult = append(valResult, atField(".animation", err))
+	}
+
+	if yml.Animation != nil && camera != nil && (yml.Animation.Time != camera.Time || yml.Animation.Fps != camera.Fps) {
+		err := fmt.Errorf("'animation' and the camera animation require the same 'timeSec' and 'fps'")
+		valResult = append(valResult, atField(".animation", err))
 	}
 
 	return valResult
@@ -476,29 +1311,57 @@ func (yml *YamlDescription) Validate() []error {
 	valResult := make([]error, 0)
 
 	if yml.Width <= 0 {
-		valResult = append(valResult, fmt.Errorf("field 'width' is required with a value greater than 0"))
+		valResult = append(valResult, atField(".width", fmt.Errorf("field 'width' is required with a value greater than 0")))
 	}
 
 	if yml.Height <= 0 {
-		valResult = append(valResult, fmt.Errorf("field 'height' is required with a value greater than 0"))
+		valResult = append(valResult, atField(".height", fmt.Errorf("field 'height' is required with a value greater than 0")))
 	}
 
-	for _, c := range yml.Colors {
-		valResult = append(valResult, c.validate()...)
+	for i, c := range yml.Colors {
+		valResult = append(valResult, atPath(fmt.Sprintf(".colors[%d]", i), c.validate())...)
 	}
 
-	for _, m := range yml.Materials {
-		valResult = append(valResult, m.validate()...)
+	for i, m := range yml.Materials {
+		valResult = append(valResult, atPath(fmt.Sprintf(".materials[%d]", i), m.validate())...)
 	}
 
-	for _, t := range yml.Transforms {
-		valResult = append(valResult, t.validate()...)
+	for i, t := range yml.Transforms {
+		valResult = append(valResult, atPath(fmt.Sprintf(".transforms[%d]", i), t.validate())...)
 	}
 
-	valResult = append(valResult, yml.Patterns.validate()...)
-	valResult = append(valResult, yml.Scene.validate()...)
-	valResult = append(valResult, yml.Light.validate()...)
-	valResult = append(valResult, yml.Camera.validate()...)
+	valResult = append(valResult, atPath(".patterns", yml.Patterns.validate())...)
+	valResult = append(valResult, atPath(".scene", yml.Scene.validate())...)
+	if len(yml.GetLights()) == 0 && len(yml.AreaLights) == 0 && len(yml.DirectionalLights) == 0 && len(yml.SpotLights) == 0 {
+		valResult = append(valResult, fmt.Errorf("scene requires at least one light in 'light', 'lights', 'areaLights', 'directionalLights' or 'spotLights'"))
+	}
 
-	return valResult
+	if yml.Light != nil {
+		valResult = append(valResult, atPath(".light", yml.Light.validate())...)
+	}
+
+	for i, l := range yml.Lights {
+		valResult = append(valResult, atPath(fmt.Sprintf(".lights[%d]", i), l.validate())...)
+	}
+
+	for i, l := range yml.AreaLights {
+		valResult = append(valResult, atPath(fmt.Sprintf(".areaLights[%d]", i), l.validate())...)
+	}
+
+	for i, l := range yml.DirectionalLights {
+		valResult = append(valResult, atPath(fmt.Sprintf(".directionalLights[%d]", i), l.validate())...)
+	}
+
+	for i, l := range yml.SpotLights {
+		valResult = append(valResult, atPath(fmt.Sprintf(".spotLights[%d]", i), l.validate())...)
+	}
+	valResult = append(valResult, atPath(".camera", yml.Camera.validate())...)
+
+	if yml.Render != nil {
+		valResult = append(valResult, atPath(".render", yml.Render.validate())...)
+	}
+
+	valResult = append(valResult, yml.validateAnimation()...)
+
+	return atPath("$", valResult)
 }
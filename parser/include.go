@@ -0,0 +1,193 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Included files are merged into the description of the including scene before it is validated.
+// Their lists are appended to the lists of the scene, everything else (width, camera, render, ...)
+// only comes from the scene itself. Every file is merged once, even if it is included several times.
+
+// origin of a list element that was merged from an included file
+type origin struct {
+	file   string
+	source []byte
+	path   string // path of the element in its own file
+}
+
+type includeLoader struct {
+	root   *YamlDescription
+	loaded map[string]bool // absolute paths of all files that were read so far
+	stack  []string        // absolute paths of the files that are currently being included
+}
+
+func (yml *YamlDescription) resolveIncludes() error {
+	if len(yml.Include) == 0 {
+		return nil
+	}
+	loader := includeLoader{root: yml, loaded: map[string]bool{}}
+	if yml.file != "" {
+		path, err := filepath.Abs(yml.file)
+		if err != nil {
+			return err
+		}
+		loader.loaded[path] = true
+		loader.stack = append(loader.stack, path)
+	}
+	return loader.includeAll(yml)
+}
+
+func (l *includeLoader) includeAll(yml *YamlDescription) error {
+	for i, include := range yml.Include {
+		// errors of the include list itself point at the entry in the including file
+		at := func(err error) error {
+			fieldErr := &FieldError{Path: fmt.Sprintf("$.include[%d]", i), Err: err}
+			return yml.locate([]error{fieldErr})[0]
+		}
+
+		if include == "" {
+			return &ValidationError{Errors: []error{at(errors.New("include requires a file name"))}}
+		}
+		path := include
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(yml.file), include)
+		}
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return at(fmt.Errorf("cannot include '%v': %w", include, err))
+		}
+
+		if cycleStart := slices.Index(l.stack, absPath); cycleStart >= 0 {
+			cycle := append(slices.Clone(l.stack[cycleStart:]), absPath)
+			err := fmt.Errorf("include cycle %v", strings.Join(cycle, " -> "))
+			return &ValidationError{Errors: []error{at(err)}}
+		}
+		if l.loaded[absPath] {
+			continue
+		}
+		l.loaded[absPath] = true
+
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return at(fmt.Errorf("cannot include '%v': %w", include, err))
+		}
+		included, err := unmarshalYaml(path, source)
+		if err != nil {
+			return err
+		}
+
+		l.stack = append(l.stack, absPath)
+		err = l.includeAll(included)
+		l.stack = l.stack[:len(l.stack)-1]
+		if err != nil {
+			return err
+		}
+
+		if err := l.root.merge(included); err != nil {
+			return at(err)
+		}
+	}
+	return nil
+}
+
+// merge appends the lists of an included description
+func (yml *YamlDescription) merge(included *YamlDescription) error {
+	relocate, err := yml.relocation(included)
+	if err != nil {
+		return err
+	}
+	for i := range included.Materials {
+		if texture := included.Materials[i].Texture; texture != nil {
+			texture.File = relocate(texture.File)
+		}
+	}
+	for i := range included.Scene.Objects {
+		included.Scene.Objects[i].File = relocate(included.Scene.Objects[i].File)
+	}
+	for i := range included.Scene.Heightfields {
+		included.Scene.Heightfields[i].File = relocate(included.Scene.Heightfields[i].File)
+	}
+
+	mergeList(yml, &yml.Colors, included, included.Colors, "$.colors")
+	mergeList(yml, &yml.Materials, included, included.Materials, "$.materials")
+	mergeList(yml, &yml.Transforms, included, included.Transforms, "$.transforms")
+
+	mergeList(yml, &yml.Patterns.Checker, included, included.Patterns.Checker, "$.patterns.checker")
+	mergeList(yml, &yml.Patterns.Ring, included, included.Patterns.Ring, "$.patterns.ring")
+	mergeList(yml, &yml.Patterns.Gradient, included, included.Patterns.Gradient, "$.patterns.gradient")
+	mergeList(yml, &yml.Patterns.Stripe, included, included.Patterns.Stripe, "$.patterns.stripe")
+
+	mergeList(yml, &yml.Scene.Planes, included, included.Scene.Planes, "$.scene.planes")
+	mergeList(yml, &yml.Scene.Cubes, included, included.Scene.Cubes, "$.scene.cubes")
+	mergeList(yml, &yml.Scene.Spheres, included, included.Scene.Spheres, "$.scene.spheres")
+	mergeList(yml, &yml.Scene.Groups, included, included.Scene.Groups, "$.scene.groups")
+	mergeList(yml, &yml.Scene.Triangles, included, included.Scene.Triangles, "$.scene.triangles")
+	mergeList(yml, &yml.Scene.Cylinders, included, included.Scene.Cylinders, "$.scene.cylinders")
+	mergeList(yml, &yml.Scene.Cones, included, included.Scene.Cones, "$.scene.cones")
+	mergeList(yml, &yml.Scene.Tori, included, included.Scene.Tori, "$.scene.tori")
+	mergeList(yml, &yml.Scene.Disks, included, included.Scene.Disks, "$.scene.disks")
+	mergeList(yml, &yml.Scene.Quads, included, included.Scene.Quads, "$.scene.quads")
+	mergeList(yml, &yml.Scene.SDFs, included, included.Scene.SDFs, "$.scene.sdfs")
+	mergeList(yml, &yml.Scene.Heightfields, included, included.Scene.Heightfields, "$.scene.heightfields")
+	mergeList(yml, &yml.Scene.Objects, included, included.Scene.Objects, "$.scene.objects")
+	mergeList(yml, &yml.Scene.CSGs, included, included.Scene.CSGs, "$.scene.csg")
+
+	mergeList(yml, &yml.Lights, included, included.Lights, "$.lights")
+	mergeList(yml, &yml.AreaLights, included, included.AreaLights, "$.areaLights")
+	mergeList(yml, &yml.DirectionalLights, included, included.DirectionalLights, "$.directionalLights")
+	mergeList(yml, &yml.SpotLights, included, included.SpotLights, "$.spotLights")
+	return nil
+}
+
+func mergeList[T any](yml *YamlDescription, list *[]T, included *YamlDescription, items []T, path string) {
+	if yml.origins == nil {
+		yml.origins = map[string]origin{}
+	}
+	for i, item := range items {
+		yml.origins[fmt.Sprintf("%v[%d]", path, len(*list))] = origin{
+			file:   included.file,
+			source: included.source,
+			path:   fmt.Sprintf("%v[%d]", path, i),
+		}
+		*list = append(*list, item)
+	}
+}
+
+// relocation returns a function that turns file paths which are relative to the included file
+// into paths that are relative to this description, obj files and textures are loaded from there
+func (yml *YamlDescription) relocation(included *YamlDescription) (func(string) string, error) {
+	rootDir, err := filepath.Abs(filepath.Dir(yml.file))
+	if err != nil {
+		return nil, err
+	}
+	includedDir, err := filepath.Abs(filepath.Dir(included.file))
+	if err != nil {
+		return nil, err
+	}
+	relativeDir, err := filepath.Rel(rootDir, includedDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(relativeDir, path)
+	}, nil
+}
+
+// origin returns the file that defines the node at path and the path of the node in that file
+func (yml *YamlDescription) origin(path string) origin {
+	for p := path; p != "$" && p != ""; p = parentPath(p) {
+		if o, ok := yml.origins[p]; ok {
+			return origin{file: o.file, source: o.source, path: o.path + path[len(p):]}
+		}
+	}
+	return origin{file: yml.file, source: yml.source, path: path}
+}
@@ -0,0 +1,155 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	yamlparser "github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/printer"
+	"github.com/goccy/go-yaml/token"
+)
+
+// sourceLocator finds the nodes that FieldErrors refer to in the yaml source
+type sourceLocator struct {
+	file string
+	ast  *ast.File
+}
+
+func createSourceLocator(file string, source []byte) *sourceLocator {
+	astFile, err := yamlparser.ParseBytes(source, 0)
+	if err != nil {
+		return nil
+	}
+	return &sourceLocator{file: file, ast: astFile}
+}
+
+// locate returns errs with the positions of all FieldErrors filled in.
+// Elements that were merged from included files are located in the file that defines them.
+func (yml *YamlDescription) locate(errs []error) []error {
+	locators := map[string]*sourceLocator{}
+	find := func(path string) Position {
+		o := yml.origin(path)
+		locator, ok := locators[o.file]
+		if !ok {
+			locator = createSourceLocator(o.file, o.source)
+			locators[o.file] = locator
+		}
+		return locator.find(o.path)
+	}
+
+	located := make([]error, 0, len(errs))
+	for _, err := range errs {
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) {
+			located = append(located, err)
+			continue
+		}
+		locatedErr := *fieldErr
+		locatedErr.Position = find(fieldErr.Path)
+
+		var duplicateErr *DuplicateNameError
+		if errors.As(fieldErr.Err, &duplicateErr) {
+			locatedDuplicate := *duplicateErr
+			locatedDuplicate.First = find(duplicateErr.FirstPath)
+			locatedErr.Err = &locatedDuplicate
+		}
+		located = append(located, &locatedErr)
+	}
+	return located
+}
+
+// find returns the position of the node at path, it is empty if the node cannot be found
+func (l *sourceLocator) find(path string) Position {
+	if l == nil {
+		return Position{}
+	}
+	if tk := l.token(path); tk != nil {
+		return l.position(tk)
+	}
+	return Position{}
+}
+
+func (l *sourceLocator) position(tk *token.Token) Position {
+	var p printer.Printer
+	return Position{
+		File:    l.file,
+		Line:    tk.Position.Line,
+		Column:  tk.Position.Column,
+		Snippet: strings.TrimRight(p.PrintErrorToken(tk, false), " \n"),
+	}
+}
+
+// token returns the token of the node at path. Missing nodes, e.g. a required field that
+// is not set, are attributed to their closest parent. Errors of the whole document have no token.
+func (l *sourceLocator) token(path string) *token.Token {
+	for ; path != "$" && path != ""; path = parentPath(path) {
+		node := l.filter(path)
+		if node == nil {
+			continue
+		}
+
+		switch node.(type) {
+		case *ast.MappingNode, *ast.MappingValueNode, *ast.SequenceNode:
+			// point at the key of a collection instead of its content
+			if key, ok := strings.CutPrefix(path[len(parentPath(path)):], "."); ok {
+				if tk := mappingKeyToken(l.filter(parentPath(path)), key); tk != nil {
+					return tk
+				}
+			}
+			// or at the first key of a list entry
+			if values := mappingValues(node); len(values) > 0 {
+				return values[0].Key.GetToken()
+			}
+		}
+		return node.GetToken()
+	}
+	return nil
+}
+
+func (l *sourceLocator) filter(path string) ast.Node {
+	if path == "$" {
+		if len(l.ast.Docs) == 0 {
+			return nil
+		}
+		return l.ast.Docs[0].Body
+	}
+	yamlPath, err := yaml.PathString(path)
+	if err != nil {
+		return nil
+	}
+	node, err := yamlPath.FilterFile(l.ast)
+	if err != nil {
+		return nil
+	}
+	return node
+}
+
+// parentPath removes the last key or index from path
+func parentPath(path string) string {
+	index := strings.LastIndexAny(path, ".[")
+	if index <= 0 {
+		return "$"
+	}
+	return path[:index]
+}
+
+func mappingKeyToken(node ast.Node, key string) *token.Token {
+	for _, value := range mappingValues(node) {
+		if value.Key.GetToken().Value == key {
+			return value.Key.GetToken()
+		}
+	}
+	return nil
+}
+
+func mappingValues(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	}
+	return nil
+}
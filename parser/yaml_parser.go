@@ -1,15 +1,18 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"maps"
 	gomath "math"
+	"os"
 	"raygo/geometry"
 	"raygo/lighting"
 	"raygo/math"
 	"raygo/obj"
 	"raygo/scene"
 	"slices"
+	"strings"
 	"sync"
 
 	"github.com/goccy/go-yaml"
@@ -20,326 +23,806 @@ const TRANSLATION_TF = "translation"
 const SCALING_TF = "scaling"
 const ROTATION_TF = "rotation"
 
-var yamlColors map[string]*NamedColorModel
-var yamlTransforms map[string]*NamedTransformModel
-var yamlMaterials map[string]*NamedMaterialModel
-var yamlCubes map[string]*CubeModel
-var yamlPlanes map[string]*PlaneModel
-var yamlSpheres map[string]*SphereModel
-var yamlCylinders map[string]*CylinderModel
-var yamlCones map[string]*ConeModel
-var yamlTriangles map[string]*TriangleModel
-var yamlObjects map[string]*ObjectModel
-var yamlGroups map[string]*GroupModel
-var yamlStripePatterns map[string]*StripePatternModel
-var yamlGradientPatterns map[string]*GradientPatternModel
-var yamlRingPatterns map[string]*RingPatternModel
-var yamlCheckerPatterns map[string]*CheckerPatternModel
+const DEFAULT_FOV = gomath.Pi / 3.0
+
+// defaults for adaptive antialiasing
+const DEFAULT_AA_THRESHOLD = 0.05
+const DEFAULT_AA_DEPTH = 3
+
+// SceneBuilder owns the lookup tables that are needed to turn a single yaml description into a scene.
+// Builders don't share any state, so different scenes can be parsed concurrently.
+type SceneBuilder struct {
+	yml       *YamlDescription
+	directory string // prefix for the paths of obj files and textures
+
+	yamlColors           map[string]*NamedColorModel
+	yamlTransforms       map[string]*NamedTransformModel
+	yamlMaterials        map[string]*NamedMaterialModel
+	yamlCubes            map[string]*CubeModel
+	yamlPlanes           map[string]*PlaneModel
+	yamlSpheres          map[string]*SphereModel
+	yamlCylinders        map[string]*CylinderModel
+	yamlCones            map[string]*ConeModel
+	yamlTori             map[string]*TorusModel
+	yamlDisks            map[string]*DiskModel
+	yamlQuads            map[string]*QuadModel
+	yamlSDFs             map[string]*SdfModel
+	yamlHeightfields     map[string]*HeightfieldModel
+	yamlTriangles        map[string]*TriangleModel
+	yamlObjects          map[string]*ObjectModel
+	yamlGroups           map[string]*GroupModel
+	yamlCsgs             map[string]*CsgModel
+	yamlStripePatterns   map[string]*StripePatternModel
+	yamlGradientPatterns map[string]*GradientPatternModel
+	yamlRingPatterns     map[string]*RingPatternModel
+	yamlCheckerPatterns  map[string]*CheckerPatternModel
+
+	raygoColors     map[string]*math.Color
+	raygoMaterials  map[string]*geometry.Material
+	raygoTransforms map[string]math.Matrix
+	raygoPatterns   map[string]geometry.Pattern
+	raygoShapes     map[string]geometry.Shape
+
+	// every obj file is parsed once, objects that use the same file are instances of its prototype
+	objPrototypes map[string]*geometry.Group
+
+	// we need to keep track of shapes that are children of groups or csgs
+	// because we only want to add the parent to the scene
+	childrenObjects map[string]struct{}
+}
 
-var raygoColors map[string]*math.Color
-var raygoMaterials map[string]*geometry.Material
-var raygoTransforms map[string]math.Matrix
-var raygoPatterns map[string]geometry.Pattern
-var raygoShapes map[string]geometry.Shape
+func CreateSceneBuilder(yml *YamlDescription, directory string) *SceneBuilder {
+	b := &SceneBuilder{
+		yml:       yml,
+		directory: directory,
+	}
+	b.initReferences()
+	return b
+}
 
-// we need to keep track of shapes that are children of groups
-// because we only want to add the parent to the scene
-var childrenObjects map[string]struct{}
+func ParseYaml(content string) (*YamlDescription, error) {
+	return parseYaml("", []byte(content))
+}
 
-func ParseYaml(content string) *YamlDescription {
-	description := YamlDescription{}
-	if err := yaml.Unmarshal([]byte(content), &description); err != nil {
-		log.Fatalf("could not unmarshal yaml: %v", err)
+// ParseYamlFile parses the scene description in the given file,
+// errors refer to their position in the file
+func ParseYamlFile(path string) (*YamlDescription, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read scene file: %w", err)
 	}
-	return &description
+	return parseYaml(path, content)
 }
 
-func initReferences(yml *YamlDescription) {
-	yamlColors = make(map[string]*NamedColorModel, 0)
-	for _, c := range yml.Colors {
-		yamlColors[c.Name] = &c
+func parseYaml(file string, source []byte) (*YamlDescription, error) {
+	description, err := unmarshalYaml(file, source)
+	if err != nil {
+		return nil, err
+	}
+	if err := description.resolveIncludes(); err != nil {
+		return nil, err
 	}
+	return description, nil
+}
 
-	yamlMaterials = make(map[string]*NamedMaterialModel, 0)
-	for _, m := range yml.Materials {
-		yamlMaterials[m.Name] = &m
+func unmarshalYaml(file string, source []byte) (*YamlDescription, error) {
+	description := YamlDescription{file: file, source: source}
+	if err := yaml.Unmarshal(source, &description); err != nil {
+		syntaxErr := &SyntaxError{Err: err}
+		var yamlErr yaml.Error
+		if errors.As(err, &yamlErr) && yamlErr.GetToken() != nil {
+			syntaxErr.Position = (&sourceLocator{file: file}).position(yamlErr.GetToken())
+		}
+		return nil, syntaxErr
 	}
+	return &description, nil
+}
 
-	yamlTransforms = make(map[string]*NamedTransformModel, 0)
-	for _, t := range yml.Transforms {
-		yamlTransforms[t.Name] = &t
+func (b *SceneBuilder) initReferences() {
+	b.yamlColors = make(map[string]*NamedColorModel, 0)
+	for _, c := range b.yml.Colors {
+		b.yamlColors[c.Name] = &c
 	}
 
-	yamlPlanes = make(map[string]*PlaneModel, 0)
-	for _, p := range yml.Scene.Planes {
-		yamlPlanes[p.Name] = &p
+	b.yamlMaterials = make(map[string]*NamedMaterialModel, 0)
+	for _, m := range b.yml.Materials {
+		b.yamlMaterials[m.Name] = &m
 	}
 
-	yamlSpheres = make(map[string]*SphereModel, 0)
-	for _, s := range yml.Scene.Spheres {
-		yamlSpheres[s.Name] = &s
+	b.yamlTransforms = make(map[string]*NamedTransformModel, 0)
+	for _, t := range b.yml.Transforms {
+		b.yamlTransforms[t.Name] = &t
 	}
 
-	yamlCubes = make(map[string]*CubeModel, 0)
-	for _, c := range yml.Scene.Cubes {
-		yamlCubes[c.Name] = &c
+	b.yamlPlanes = make(map[string]*PlaneModel, 0)
+	for _, p := range b.yml.Scene.Planes {
+		b.yamlPlanes[p.Name] = &p
 	}
 
-	yamlCylinders = make(map[string]*CylinderModel, 0)
-	for _, c := range yml.Scene.Cylinders {
-		yamlCylinders[c.Name] = &c
+	b.yamlSpheres = make(map[string]*SphereModel, 0)
+	for _, s := range b.yml.Scene.Spheres {
+		b.yamlSpheres[s.Name] = &s
 	}
 
-	yamlCones = make(map[string]*ConeModel, 0)
-	for _, c := range yml.Scene.Cones {
-		yamlCones[c.Name] = &c
+	b.yamlCubes = make(map[string]*CubeModel, 0)
+	for _, c := range b.yml.Scene.Cubes {
+		b.yamlCubes[c.Name] = &c
 	}
 
-	yamlTriangles = make(map[string]*TriangleModel, 0)
-	for _, t := range yml.Scene.Triangles {
-		yamlTriangles[t.Name] = &t
+	b.yamlCylinders = make(map[string]*CylinderModel, 0)
+	for _, c := range b.yml.Scene.Cylinders {
+		b.yamlCylinders[c.Name] = &c
 	}
 
-	yamlGroups = make(map[string]*GroupModel, 0)
-	for _, g := range yml.Scene.Groups {
-		yamlGroups[g.Name] = &g
+	b.yamlCones = make(map[string]*ConeModel, 0)
+	for _, c := range b.yml.Scene.Cones {
+		b.yamlCones[c.Name] = &c
 	}
 
-	yamlObjects = make(map[string]*ObjectModel, 0)
-	for _, o := range yml.Scene.Objects {
-		yamlObjects[o.Name] = &o
+	b.yamlTori = make(map[string]*TorusModel, 0)
+	for _, t := range b.yml.Scene.Tori {
+		b.yamlTori[t.Name] = &t
 	}
 
-	yamlStripePatterns = make(map[string]*StripePatternModel, 0)
-	for _, p := range yml.Patterns.Stripe {
-		yamlStripePatterns[p.Name] = &p
+	b.yamlDisks = make(map[string]*DiskModel, 0)
+	for _, d := range b.yml.Scene.Disks {
+		b.yamlDisks[d.Name] = &d
 	}
 
-	yamlGradientPatterns = make(map[string]*GradientPatternModel, 0)
-	for _, p := range yml.Patterns.Gradient {
-		yamlGradientPatterns[p.Name] = &p
+	b.yamlQuads = make(map[string]*QuadModel, 0)
+	for _, q := range b.yml.Scene.Quads {
+		b.yamlQuads[q.Name] = &q
 	}
 
-	yamlRingPatterns = make(map[string]*RingPatternModel, 0)
-	for _, p := range yml.Patterns.Ring {
-		yamlRingPatterns[p.Name] = &p
+	b.yamlSDFs = make(map[string]*SdfModel, 0)
+	for _, s := range b.yml.Scene.SDFs {
+		b.yamlSDFs[s.Name] = &s
 	}
 
-	yamlCheckerPatterns = make(map[string]*CheckerPatternModel, 0)
-	for _, p := range yml.Patterns.Checker {
-		yamlCheckerPatterns[p.Name] = &p
+	b.yamlHeightfields = make(map[string]*HeightfieldModel, 0)
+	for _, h := range b.yml.Scene.Heightfields {
+		b.yamlHeightfields[h.Name] = &h
 	}
+
+	b.yamlTriangles = make(map[string]*TriangleModel, 0)
+	for _, t := range b.yml.Scene.Triangles {
+		b.yamlTriangles[t.Name] = &t
+	}
+
+	b.yamlGroups = make(map[string]*GroupModel, 0)
+	for _, g := range b.yml.Scene.Groups {
+		b.yamlGroups[g.Name] = &g
+	}
+
+	b.yamlCsgs = make(map[string]*CsgModel, 0)
+	for _, c := range b.yml.Scene.CSGs {
+		b.yamlCsgs[c.Name] = &c
+	}
+
+	b.yamlObjects = make(map[string]*ObjectModel, 0)
+	for _, o := range b.yml.Scene.Objects {
+		b.yamlObjects[o.Name] = &o
+	}
+
+	b.yamlStripePatterns = make(map[string]*StripePatternModel, 0)
+	for _, p := range b.yml.Patterns.Stripe {
+		b.yamlStripePatterns[p.Name] = &p
+	}
+
+	b.yamlGradientPatterns = make(map[string]*GradientPatternModel, 0)
+	for _, p := range b.yml.Patterns.Gradient {
+		b.yamlGradientPatterns[p.Name] = &p
+	}
+
+	b.yamlRingPatterns = make(map[string]*RingPatternModel, 0)
+	for _, p := range b.yml.Patterns.Ring {
+		b.yamlRingPatterns[p.Name] = &p
+	}
+
+	b.yamlCheckerPatterns = make(map[string]*CheckerPatternModel, 0)
+	for _, p := range b.yml.Patterns.Checker {
+		b.yamlCheckerPatterns[p.Name] = &p
+	}
+}
+
+// Validate checks the description itself and all references between its elements
+func (b *SceneBuilder) Validate() error {
+	validationResult := b.yml.Validate()
+	validationResult = append(validationResult, b.ValidateReferences()...)
+	if len(validationResult) != 0 {
+		return &ValidationError{Errors: b.yml.locate(validationResult)}
+	}
+	return nil
 }
 
-func ValidateReferences(yml *YamlDescription) []error {
+func (b *SceneBuilder) ValidateReferences() []error {
 	validationResult := make([]error, 0)
-	initReferences(yml)
 
-	validationResult = append(validationResult, validatePatternReferences(yml)...)
-	validationResult = append(validationResult, validateMaterialReferences(yml)...)
-	validationResult = append(validationResult, validateSceneObjectReferences(yml)...)
-	validationResult = append(validationResult, validateCameraReferences(yml)...)
+	validationResult = append(validationResult, b.validateUniqueNames()...)
+	validationResult = append(validationResult, b.validatePatternReferences()...)
+	validationResult = append(validationResult, b.validateMaterialReferences()...)
+	validationResult = append(validationResult, b.validateSceneObjectReferences()...)
+	validationResult = append(validationResult, b.validateCameraReferences()...)
 
 	return validationResult
 }
 
-func validatePatternReferences(yml *YamlDescription) []error {
+// validateUniqueNames reports every name that is defined more than once.
+// All patterns share one namespace, the same goes for all scene objects.
+func (b *SceneBuilder) validateUniqueNames() []error {
 	validationResult := make([]error, 0)
 
-	for _, p := range yml.Patterns.Checker {
-		if yamlColors[p.ColorA] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorA, p.Name))
+	definitions := make(map[string]map[string]string) // kind -> name -> path of the first definition
+	define := func(kind string, name string, path string) {
+		if name == "" {
+			return
+		}
+		if definitions[kind] == nil {
+			definitions[kind] = make(map[string]string)
 		}
-		if yamlColors[p.ColorB] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorB, p.Name))
+		path += ".name"
+		if first, ok := definitions[kind][name]; ok {
+			err := &DuplicateNameError{Kind: kind, Name: name, FirstPath: first}
+			validationResult = append(validationResult, &FieldError{Path: path, Err: err})
+			return
 		}
+		definitions[kind][name] = path
 	}
 
-	for _, p := range yml.Patterns.Ring {
-		if yamlColors[p.ColorA] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorA, p.Name))
-		}
-		if yamlColors[p.ColorB] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorB, p.Name))
-		}
+	for i, c := range b.yml.Colors {
+		define("color", c.Name, fmt.Sprintf("$.colors[%d]", i))
+	}
+	for i, m := range b.yml.Materials {
+		define("material", m.Name, fmt.Sprintf("$.materials[%d]", i))
+	}
+	for i, t := range b.yml.Transforms {
+		define("transform", t.Name, fmt.Sprintf("$.transforms[%d]", i))
 	}
 
-	for _, p := range yml.Patterns.Gradient {
-		if yamlColors[p.ColorA] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorA, p.Name))
-		}
-		if yamlColors[p.ColorB] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorB, p.Name))
-		}
+	for i, p := range b.yml.Patterns.Checker {
+		define("pattern", p.Name, fmt.Sprintf("$.patterns.checker[%d]", i))
+	}
+	for i, p := range b.yml.Patterns.Ring {
+		define("pattern", p.Name, fmt.Sprintf("$.patterns.ring[%d]", i))
+	}
+	for i, p := range b.yml.Patterns.Gradient {
+		define("pattern", p.Name, fmt.Sprintf("$.patterns.gradient[%d]", i))
+	}
+	for i, p := range b.yml.Patterns.Stripe {
+		define("pattern", p.Name, fmt.Sprintf("$.patterns.stripe[%d]", i))
 	}
 
-	for _, p := range yml.Patterns.Stripe {
-		if yamlColors[p.ColorA] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorA, p.Name))
-		}
-		if yamlColors[p.ColorB] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorB, p.Name))
-		}
+	for i, o := range b.yml.Scene.Planes {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.planes[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Cubes {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.cubes[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Spheres {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.spheres[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Groups {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.groups[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Triangles {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.triangles[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Cylinders {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.cylinders[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Cones {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.cones[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Tori {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.tori[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Disks {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.disks[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Quads {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.quads[%d]", i))
+	}
+	for i, o := range b.yml.Scene.SDFs {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.sdfs[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Heightfields {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.heightfields[%d]", i))
+	}
+	for i, o := range b.yml.Scene.Objects {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.objects[%d]", i))
+	}
+	for i, o := range b.yml.Scene.CSGs {
+		define("scene object", o.Name, fmt.Sprintf("$.scene.csg[%d]", i))
 	}
 
 	return validationResult
 }
 
-func validateMaterialReferences(yml *YamlDescription) []error {
+func (b *SceneBuilder) validatePatternReferences() []error {
 	validationResult := make([]error, 0)
 
-	for _, m := range yml.Materials {
-		if m.Color != "" && yamlColors[m.Color] == nil {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve color '%v' for material '%v'", m.Color, m.Name))
+	for i, p := range b.yml.Patterns.Checker {
+		path := fmt.Sprintf("$.patterns.checker[%d]", i)
+		validationResult = append(validationResult, b.validateDualColorPatternReferences(path, p.DualColorPattern)...)
+	}
+
+	for i, p := range b.yml.Patterns.Ring {
+		path := fmt.Sprintf("$.patterns.ring[%d]", i)
+		validationResult = append(validationResult, b.validateDualColorPatternReferences(path, p.DualColorPattern)...)
+	}
+
+	for i, p := range b.yml.Patterns.Gradient {
+		path := fmt.Sprintf("$.patterns.gradient[%d]", i)
+		validationResult = append(validationResult, b.validateDualColorPatternReferences(path, p.DualColorPattern)...)
+	}
+
+	for i, p := range b.yml.Patterns.Stripe {
+		path := fmt.Sprintf("$.patterns.stripe[%d]", i)
+		validationResult = append(validationResult, b.validateDualColorPatternReferences(path, p.DualColorPattern)...)
+	}
+
+	return validationResult
+}
+
+func (b *SceneBuilder) validateDualColorPatternReferences(path string, p DualColorPattern) []error {
+	validationResult := make([]error, 0)
+
+	if b.yamlColors[p.ColorA] == nil {
+		err := fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorA, p.Name)
+		validationResult = append(validationResult, atField(path+".colorA", err))
+	}
+	if b.yamlColors[p.ColorB] == nil {
+		err := fmt.Errorf("cannot resolve color '%v' for pattern '%v'", p.ColorB, p.Name)
+		validationResult = append(validationResult, atField(path+".colorB", err))
+	}
+
+	return validationResult
+}
+
+func (b *SceneBuilder) validateMaterialReferences() []error {
+	validationResult := make([]error, 0)
+
+	for i, m := range b.yml.Materials {
+		path := fmt.Sprintf("$.materials[%d]", i)
+		if m.Color != "" && b.yamlColors[m.Color] == nil {
+			err := fmt.Errorf("cannot resolve color '%v' for material '%v'", m.Color, m.Name)
+			validationResult = append(validationResult, atField(path+".color", err))
+		}
+
+		if m.Pattern != "" && !b.containsPattern(m.Pattern) {
+			err := fmt.Errorf("cannot resolve pattern '%v' for material '%v'", m.Pattern, m.Name)
+			validationResult = append(validationResult, atField(path+".pattern", err))
 		}
 
-		if m.Pattern != "" && !containsPattern(m.Pattern) {
-			validationResult = append(validationResult, fmt.Errorf("cannot resolve pattern '%v' for material '%v'", m.Pattern, m.Name))
+		if m.Extends == "" {
+			continue
+		}
+		if b.yamlMaterials[m.Extends] == nil {
+			err := fmt.Errorf("cannot resolve material '%v' extended by material '%v'", m.Extends, m.Name)
+			validationResult = append(validationResult, atField(path+".extends", err))
+		} else if cycle := b.materialInheritanceCycle(m.Name); cycle != nil {
+			err := fmt.Errorf("material '%v' extends itself: %v", m.Name, strings.Join(cycle, " -> "))
+			validationResult = append(validationResult, atField(path+".extends", err))
 		}
 	}
 
 	return validationResult
 }
 
-func validateSceneObjectReferences(yml *YamlDescription) []error {
+// materialInheritanceCycle returns the chain of materials that leads from the material back to itself,
+// nil if the material is not part of a cycle
+func (b *SceneBuilder) materialInheritanceCycle(name string) []string {
+	chain := []string{name}
+	for m := b.yamlMaterials[name]; m != nil && m.Extends != ""; m = b.yamlMaterials[m.Extends] {
+		if m.Extends == name {
+			return append(chain, name)
+		}
+		if slices.Contains(chain, m.Extends) {
+			// a cycle that this material only leads into, it is reported for its members
+			return nil
+		}
+		chain = append(chain, m.Extends)
+	}
+	return nil
+}
+
+// resolveMaterial returns the material with all inherited values filled in
+func (b *SceneBuilder) resolveMaterial(m *NamedMaterialModel) MaterialModel {
+	resolved := m.MaterialModel
+	visited := []string{m.Name}
+	for parent := b.yamlMaterials[m.Extends]; parent != nil; parent = b.yamlMaterials[parent.Extends] {
+		if slices.Contains(visited, parent.Name) {
+			break
+		}
+		resolved = resolved.inherit(parent.MaterialModel)
+		visited = append(visited, parent.Name)
+	}
+	return resolved
+}
+
+func (b *SceneBuilder) validateSceneObjectReferences() []error {
 	validationResult := make([]error, 0)
 
-	for _, p := range yml.Scene.Planes {
-		validationResult = append(validationResult, validateCommonSceneObjectReferences(p.CommonSceneObject)...)
+	for i, p := range b.yml.Scene.Planes {
+		path := fmt.Sprintf("$.scene.planes[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, p.CommonSceneObject)...)
+	}
+
+	for i, c := range b.yml.Scene.Cubes {
+		path := fmt.Sprintf("$.scene.cubes[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, c.CommonSceneObject)...)
+	}
+
+	for i, s := range b.yml.Scene.Spheres {
+		path := fmt.Sprintf("$.scene.spheres[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, s.CommonSceneObject)...)
+	}
+
+	for i, t := range b.yml.Scene.Triangles {
+		path := fmt.Sprintf("$.scene.triangles[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, t.CommonSceneObject)...)
+	}
+
+	for i, c := range b.yml.Scene.Cylinders {
+		path := fmt.Sprintf("$.scene.cylinders[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, c.CommonSceneObject)...)
 	}
 
-	for _, c := range yml.Scene.Cubes {
-		validationResult = append(validationResult, validateCommonSceneObjectReferences(c.CommonSceneObject)...)
+	for i, c := range b.yml.Scene.Cones {
+		path := fmt.Sprintf("$.scene.cones[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, c.CommonSceneObject)...)
 	}
 
-	for _, s := range yml.Scene.Spheres {
-		validationResult = append(validationResult, validateCommonSceneObjectReferences(s.CommonSceneObject)...)
+	for i, t := range b.yml.Scene.Tori {
+		path := fmt.Sprintf("$.scene.tori[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, t.CommonSceneObject)...)
 	}
 
-	for _, t := range yml.Scene.Triangles {
-		validationResult = append(validationResult, validateCommonSceneObjectReferences(t.CommonSceneObject)...)
+	for i, d := range b.yml.Scene.Disks {
+		path := fmt.Sprintf("$.scene.disks[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, d.CommonSceneObject)...)
 	}
 
-	for _, c := range yml.Scene.Cylinders {
-		validationResult = append(validationResult, validateCommonSceneObjectReferences(c.CommonSceneObject)...)
+	for i, q := range b.yml.Scene.Quads {
+		path := fmt.Sprintf("$.scene.quads[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, q.CommonSceneObject)...)
 	}
 
-	for _, c := range yml.Scene.Cones {
-		validationResult = append(validationResult, validateCommonSceneObjectReferences(c.CommonSceneObject)...)
+	for i, s := range b.yml.Scene.SDFs {
+		path := fmt.Sprintf("$.scene.sdfs[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, s.CommonSceneObject)...)
 	}
 
-	for _, o := range yml.Scene.Objects {
-		validationResult = append(validationResult, validateCommonSceneObjectReferences(o.CommonSceneObject)...)
+	for i, h := range b.yml.Scene.Heightfields {
+		path := fmt.Sprintf("$.scene.heightfields[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, h.CommonSceneObject)...)
 	}
 
-	for _, g := range yml.Scene.Groups {
-		validationResult = append(validationResult, validateCommonSceneObjectReferences(g.CommonSceneObject)...)
+	for i, o := range b.yml.Scene.Objects {
+		path := fmt.Sprintf("$.scene.objects[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, o.CommonSceneObject)...)
 
-		for _, child := range g.Children {
-			if !containsSceneObject(child) {
+		for _, objMaterial := range slices.Sorted(maps.Keys(o.Materials)) {
+			if material := o.Materials[objMaterial]; b.yamlMaterials[material] == nil {
+				err := fmt.Errorf("cannot resolve material '%v' for '%v' of scene object '%v'", material, objMaterial, o.Name)
+				validationResult = append(validationResult, atField(fmt.Sprintf("%v.materials.%v", path, objMaterial), err))
+			}
+		}
+	}
+
+	for i, g := range b.yml.Scene.Groups {
+		path := fmt.Sprintf("$.scene.groups[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, g.CommonSceneObject)...)
+
+		for j, child := range g.Children {
+			if !b.containsSceneObject(child) {
 				err := fmt.Errorf("cannot resolve child '%v' for group '%v'", child, g.Name)
-				validationResult = append(validationResult, err)
+				validationResult = append(validationResult, atField(fmt.Sprintf("%v.children[%d]", path, j), err))
+			}
+		}
+	}
+
+	for i, c := range b.yml.Scene.CSGs {
+		path := fmt.Sprintf("$.scene.csg[%d]", i)
+		validationResult = append(validationResult, b.validateCommonSceneObjectReferences(path, c.CommonSceneObject)...)
+
+		if c.Left != "" && !b.containsSceneObject(c.Left) {
+			err := fmt.Errorf("cannot resolve operand '%v' for csg '%v'", c.Left, c.Name)
+			validationResult = append(validationResult, atField(path+".left", err))
+		}
+		if c.Right != "" && !b.containsSceneObject(c.Right) {
+			err := fmt.Errorf("cannot resolve operand '%v' for csg '%v'", c.Right, c.Name)
+			validationResult = append(validationResult, atField(path+".right", err))
+		}
+	}
+
+	validationResult = append(validationResult, b.validateCompositeReferences()...)
+
+	return validationResult
+}
+
+// validateCompositeReferences makes sure that every shape has at most one parent
+// and that no group or csg contains itself
+func (b *SceneBuilder) validateCompositeReferences() []error {
+	validationResult := make([]error, 0)
+	parents := make(map[string]string)
+	// yaml paths of the composites for error positions
+	paths := make(map[string]string)
+
+	addParent := func(child string, parent string, path string) {
+		if child == "" {
+			return
+		}
+		if other, ok := parents[child]; ok {
+			err := fmt.Errorf("shape '%v' is used by both '%v' and '%v'", child, other, parent)
+			validationResult = append(validationResult, atField(path, err))
+			return
+		}
+		parents[child] = parent
+	}
+
+	composites := make([]string, 0)
+	for i, g := range b.yml.Scene.Groups {
+		path := fmt.Sprintf("$.scene.groups[%d]", i)
+		composites = append(composites, g.Name)
+		paths[g.Name] = path
+		for j, child := range g.Children {
+			addParent(child, g.Name, fmt.Sprintf("%v.children[%d]", path, j))
+		}
+	}
+	for i, c := range b.yml.Scene.CSGs {
+		path := fmt.Sprintf("$.scene.csg[%d]", i)
+		composites = append(composites, c.Name)
+		paths[c.Name] = path
+		addParent(c.Left, c.Name, path+".left")
+		addParent(c.Right, c.Name, path+".right")
+	}
+
+	// every shape has a single parent, walking up from a composite
+	// either ends at a root element or comes back around in a cycle
+	inCycle := make(map[string]struct{})
+	for _, name := range composites {
+		if _, ok := inCycle[name]; ok {
+			continue
+		}
+		visited := map[string]struct{}{name: {}}
+		for p, ok := parents[name]; ok; p, ok = parents[p] {
+			if p == name {
+				err := fmt.Errorf("'%v' contains itself", name)
+				validationResult = append(validationResult, atField(paths[name], err))
+				for member := parents[name]; member != name; member = parents[member] {
+					inCycle[member] = struct{}{}
+				}
+				break
+			}
+			if _, seen := visited[p]; seen {
+				// cycle further up which does not include name
+				break
 			}
+			visited[p] = struct{}{}
 		}
 	}
 
 	return validationResult
 }
 
-func validateCameraReferences(yml *YamlDescription) []error {
+func (b *SceneBuilder) validateCameraReferences() []error {
 	valResult := make([]error, 0)
 
-	if yml.Camera.To == nil && !containsSceneObject(yml.Camera.LookAt) {
-		err := fmt.Errorf("cannot resolve scene object '%v' for camera", yml.Camera.LookAt)
-		valResult = append(valResult, err)
+	path := b.yml.Camera.path()
+	if b.yml.Camera.To == nil && (path == nil || len(path.To) == 0) && !b.containsSceneObject(b.yml.Camera.LookAt) {
+		err := fmt.Errorf("cannot resolve scene object '%v' for camera", b.yml.Camera.LookAt)
+		valResult = append(valResult, atField("$.camera.lookAt", err))
 	}
 
 	return valResult
 }
 
-func validateCommonSceneObjectReferences(sceneObject CommonSceneObject) []error {
+func (b *SceneBuilder) validateCommonSceneObjectReferences(path string, sceneObject CommonSceneObject) []error {
 	valResult := make([]error, 0)
-	if sceneObject.Material != "" && yamlMaterials[sceneObject.Material] == nil {
+	if sceneObject.Material != "" && b.yamlMaterials[sceneObject.Material] == nil {
 		err := fmt.Errorf("cannot resolve material '%v' for scene object '%v'",
 			sceneObject.Material, sceneObject.Name)
-		valResult = append(valResult, err)
+		valResult = append(valResult, atField(path+".material", err))
 	}
-	if sceneObject.Transform != "" && yamlTransforms[sceneObject.Transform] == nil {
+	if sceneObject.Transform != "" && b.yamlTransforms[sceneObject.Transform] == nil {
 		err := fmt.Errorf("cannot resolve transform '%v' for scene object '%v'",
 			sceneObject.Transform, sceneObject.Name)
-		valResult = append(valResult, err)
+		valResult = append(valResult, atField(path+".transform", err))
+	}
+	for i, k := range sceneObject.Keyframes {
+		if k.Color != "" && b.yamlColors[k.Color] == nil {
+			err := fmt.Errorf("cannot resolve color '%v' for keyframe of scene object '%v'", k.Color, sceneObject.Name)
+			valResult = append(valResult, atField(fmt.Sprintf("%v.keyframes[%d].color", path, i), err))
+		}
 	}
 	return valResult
 }
 
-func containsSceneObject(name string) bool {
-	return yamlSpheres[name] != nil ||
-		yamlPlanes[name] != nil ||
-		yamlCubes[name] != nil ||
-		yamlCylinders[name] != nil ||
-		yamlCones[name] != nil ||
-		yamlTriangles[name] != nil ||
-		yamlGroups[name] != nil ||
-		yamlObjects[name] != nil
+func (b *SceneBuilder) containsSceneObject(name string) bool {
+	return b.yamlSpheres[name] != nil ||
+		b.yamlPlanes[name] != nil ||
+		b.yamlCubes[name] != nil ||
+		b.yamlCylinders[name] != nil ||
+		b.yamlCones[name] != nil ||
+		b.yamlTori[name] != nil ||
+		b.yamlDisks[name] != nil ||
+		b.yamlQuads[name] != nil ||
+		b.yamlSDFs[name] != nil ||
+		b.yamlHeightfields[name] != nil ||
+		b.yamlTriangles[name] != nil ||
+		b.yamlGroups[name] != nil ||
+		b.yamlCsgs[name] != nil ||
+		b.yamlObjects[name] != nil
 }
 
-func containsPattern(name string) bool {
-	return yamlStripePatterns[name] != nil ||
-		yamlGradientPatterns[name] != nil ||
-		yamlRingPatterns[name] != nil ||
-		yamlCheckerPatterns[name] != nil
+func (b *SceneBuilder) containsPattern(name string) bool {
+	return b.yamlStripePatterns[name] != nil ||
+		b.yamlGradientPatterns[name] != nil ||
+		b.yamlRingPatterns[name] != nil ||
+		b.yamlCheckerPatterns[name] != nil
 }
 
-func CreateWorld(yml *YamlDescription, directory string) *scene.World {
+func (b *SceneBuilder) CreateWorld() (*scene.World, error) {
 	world := scene.EmptyWorld()
 
-	createRaygoColors()
-	createRaygoTransformations()
-	createRaygoPatterns()
-	createRaygoMaterials()
-	loadTextures(directory)
-	createRaygoShapes(directory)
-
-	calculateInverseTransforms()
+	b.createRaygoColors()
+	if err := b.createRaygoTransformations(); err != nil {
+		return nil, err
+	}
+	b.createRaygoPatterns()
+	b.createRaygoMaterials()
+	if err := b.loadTextures(); err != nil {
+		return nil, err
+	}
+	if err := b.createRaygoShapes(); err != nil {
+		return nil, err
+	}
+	if err := b.createMotions(); err != nil {
+		return nil, err
+	}
 
-	sceneObjects := collectRootElements()
+	sceneObjects := b.collectRootElements()
+	b.calculateInverseTransforms(sceneObjects)
 	world.Objects = sceneObjects
+	world.Animation = b.createAnimation()
+	// animated objects start in the state of their first frame, e.g. for the camera to look at them
+	world.SetTime(0.0)
+
+	for _, yamlLight := range b.yml.GetLights() {
+		world.AddLight(createLight(yamlLight))
+	}
 
-	light := createLight(yml.Light)
-	world.Light = &light
+	for _, yamlLight := range b.yml.AreaLights {
+		light := createAreaLight(yamlLight)
+		if b.yml.Render != nil {
+			light.Seed = b.yml.Render.Seed
+		}
+		world.AddLight(light)
+	}
 
-	return world
+	for _, yamlLight := range b.yml.DirectionalLights {
+		world.AddLight(createDirectionalLight(yamlLight))
+	}
+
+	for _, yamlLight := range b.yml.SpotLights {
+		world.AddLight(createSpotLight(yamlLight))
+	}
+
+	return world, nil
 }
 
-func CreateCamera(yml *YamlDescription) scene.Camera {
-	camera := scene.CreateCamera(yml.Width, yml.Height, gomath.Pi/3.0)
-	from := mapPoint(yml.Camera.From)
+// CreateCamera has to be called after CreateWorld when the camera looks at a scene object
+func (b *SceneBuilder) CreateCamera() *scene.Camera {
+	fov := DEFAULT_FOV
+	if b.yml.Camera.Fov != nil {
+		fov = math.Radians(*b.yml.Camera.Fov)
+	}
+
+	camera := scene.CreateCamera(b.yml.Width, b.yml.Height, fov)
+	if b.yml.Camera.ViewWidth != nil {
+		camera.ViewWidth = *b.yml.Camera.ViewWidth
+	}
+	camera.SetProjection(projections[b.yml.Camera.Projection])
+	var animation *scene.CameraAnimation
+	if b.yml.Camera.Animation != nil {
+		animation = createCameraAnimation(b.yml.Camera.Animation)
+	}
+
+	// a path can replace the fixed position and target, the camera starts at the beginning of it
+	var from math.Point
+	if b.yml.Camera.From != nil {
+		from = mapPoint(b.yml.Camera.From)
+	} else {
+		from = animation.Path.From[0]
+	}
 	var to math.Point
-	if yml.Camera.LookAt != "" {
-		to = geometry.GetCenter(raygoShapes[yml.Camera.LookAt])
+	if b.yml.Camera.LookAt != "" {
+		camera.Tracking = b.raygoShapes[b.yml.Camera.LookAt]
+		to = geometry.GetCenter(camera.Tracking)
+	} else if b.yml.Camera.To != nil {
+		to = mapPoint(b.yml.Camera.To)
 	} else {
-		to = mapPoint(yml.Camera.To)
+		to = animation.Path.To[0]
 	}
 
-	up := math.CreateVector(0.0, 1.0, 0.0)
+	up := mapVector(b.yml.Camera.Up)
 	camera.Position = scene.CreateCameraPosition(from, to, up)
-	if yml.Camera.Animation != nil {
-		camera.Animation = createCameraAnimation(yml.Camera.Animation)
+	if b.yml.Camera.Aperture != nil {
+		camera.Aperture = *b.yml.Camera.Aperture
+	}
+	if b.yml.Camera.Shutter != nil {
+		camera.Shutter = *b.yml.Camera.Shutter
+	}
+	if b.yml.Camera.FocalDistance != nil {
+		camera.FocalDistance = *b.yml.Camera.FocalDistance
+	} else {
+		camera.FocalDistance = to.Subtract(from).Magnitude()
+	}
+	camera.Animation = animation
+	if b.yml.Render != nil {
+		camera.Sampler = createSampler(b.yml.Render)
 	}
 
-	return *camera
+	return camera
+}
+
+var projections = map[string]scene.Projection{
+	"":                scene.PERSPECTIVE,
+	"perspective":     scene.PERSPECTIVE,
+	"orthographic":    scene.ORTHOGRAPHIC,
+	"fisheye":         scene.FISHEYE,
+	"equirectangular": scene.EQUIRECTANGULAR,
+}
+
+var splines = map[string]scene.SplineType{
+	"":           scene.CATMULL_ROM,
+	"catmullRom": scene.CATMULL_ROM,
+	"bezier":     scene.BEZIER,
+}
+
+var easings = map[string]scene.Easing{
+	"":          scene.LINEAR,
+	"linear":    scene.LINEAR,
+	"easeIn":    scene.EASE_IN,
+	"easeOut":   scene.EASE_OUT,
+	"easeInOut": scene.EASE_IN_OUT,
+}
+
+var filters = map[string]scene.Filter{
+	"":         scene.BOX_FILTER,
+	"box":      scene.BOX_FILTER,
+	"tent":     scene.TENT_FILTER,
+	"gaussian": scene.GAUSSIAN_FILTER,
+}
+
+func createSampler(yamlRender *RenderModel) scene.Sampler {
+	if yamlRender.Adaptive {
+		threshold := DEFAULT_AA_THRESHOLD
+		if yamlRender.Threshold != nil {
+			threshold = *yamlRender.Threshold
+		}
+		maxDepth := DEFAULT_AA_DEPTH
+		if yamlRender.MaxDepth != nil {
+			maxDepth = *yamlRender.MaxDepth
+		}
+		return scene.CreateAdaptiveSampler(threshold, maxDepth)
+	}
+	return scene.CreateSampler(max(yamlRender.Samples, 1), filters[yamlRender.Filter], yamlRender.Seed)
 }
 
-func calculateInverseTransforms() {
+// calculateInverseTransforms only visits the root elements,
+// groups and csgs calculate the inverse transforms of their children themselves
+func (b *SceneBuilder) calculateInverseTransforms(rootElements []geometry.Shape) {
 	var wg sync.WaitGroup
 
-	for _, pattern := range raygoPatterns {
+	for _, pattern := range b.raygoPatterns {
 		wg.Go(func() {
 			pattern.CalculateInverseTransform()
 		})
 	}
 
-	for _, shape := range raygoShapes {
+	for _, shape := range rootElements {
 		wg.Go(func() {
 			shape.CalculateInverseTransform()
 		})
@@ -348,29 +831,159 @@ func calculateInverseTransforms() {
 	wg.Wait()
 }
 
-func loadTextures(directory string) {
-	for _, m := range raygoMaterials {
+func (b *SceneBuilder) loadTextures() error {
+	for name, m := range b.raygoMaterials {
 		if m.Texture.Exists() {
-			m.Texture.InitTexture(directory)
+			if err := m.Texture.InitTexture(b.directory); err != nil {
+				return &LoadError{Name: name, Err: err}
+			}
 		}
 	}
+	return nil
+}
+
+// createMotions lets scene objects with end transforms move while the shutter of the camera is open
+func (b *SceneBuilder) createMotions() error {
+	for _, sceneObject := range b.yml.Scene.sceneObjects() {
+		if len(sceneObject.EndTransforms) == 0 {
+			continue
+		}
+		end, err := createTransformFromList(sceneObject.EndTransforms)
+		if err != nil {
+			return fmt.Errorf("invalid end transforms for scene object '%v': %w", sceneObject.Name, err)
+		}
+		shape := b.raygoShapes[sceneObject.Name]
+		shape.SetMotion(geometry.CreateMotion(end))
+		// the groups may already have cached their bounds while they were built
+		for parent := shape.GetParent(); parent != nil; parent = parent.GetParent() {
+			parent.CachedBoundingBox = nil
+		}
+	}
+	return nil
+}
+
+// createAnimation returns nil if the scene has neither an animation nor keyframes
+func (b *SceneBuilder) createAnimation() *scene.Animation {
+	var animation *scene.Animation
+	if b.yml.Animation != nil {
+		animation = scene.CreateAnimation(b.yml.Animation.Time, b.yml.Animation.Fps)
+	} else if b.yml.Camera.Animation != nil && b.yml.Scene.hasKeyframes() {
+		// keyframes follow the timeline of the camera
+		animation = scene.CreateAnimation(b.yml.Camera.Animation.Time, b.yml.Camera.Animation.Fps)
+	} else {
+		return nil
+	}
+
+	for _, sceneObject := range b.yml.Scene.sceneObjects() {
+		if len(sceneObject.Keyframes) == 0 {
+			continue
+		}
+		keyframes := make([]scene.Keyframe, 0, len(sceneObject.Keyframes))
+		for _, k := range sceneObject.Keyframes {
+			keyframes = append(keyframes, b.createKeyframe(k))
+		}
+		animation.AddObject(scene.CreateObjectAnimation(b.raygoShapes[sceneObject.Name], keyframes))
+	}
+	return animation
+}
+
+func (b *SceneBuilder) createKeyframe(yk KeyframeModel) scene.Keyframe {
+	k := scene.Keyframe{
+		Time:            *yk.Time,
+		Easing:          easings[yk.Easing],
+		Ambient:         yk.Ambient,
+		Diffuse:         yk.Diffuse,
+		Specular:        yk.Specular,
+		Shininess:       yk.Shininess,
+		Reflective:      yk.Reflective,
+		Transparency:    yk.Transparency,
+		RefractiveIndex: yk.RefractiveIndex,
+	}
+	if yk.Translation != nil {
+		translation := mapVector(yk.Translation)
+		k.Translation = &translation
+	}
+	if yk.Rotation != nil {
+		rotation := math.CreateVector(math.Radians(yk.Rotation.X), math.Radians(yk.Rotation.Y), math.Radians(yk.Rotation.Z))
+		k.Rotation = &rotation
+	}
+	if yk.Scale != nil {
+		scale := mapVector(yk.Scale)
+		k.Scale = &scale
+	}
+	// like for materials the named color takes precedence
+	if yk.RawColor != nil {
+		color := mapColor(yk.RawColor)
+		k.Color = &color
+	}
+	if yk.Color != "" {
+		color := *b.raygoColors[yk.Color]
+		k.Color = &color
+	}
+	return k
 }
 
 func createCameraAnimation(yamlAnimation *CircularCameraAnimation) *scene.CameraAnimation {
-	return scene.CreateCameraAnimation(math.Radians(yamlAnimation.Degrees), yamlAnimation.Time, yamlAnimation.Fps)
+	animation := scene.CreateCameraAnimation(math.Radians(yamlAnimation.Degrees), yamlAnimation.Time, yamlAnimation.Fps)
+	if yamlAnimation.Path != nil {
+		animation.Path = createCameraPath(yamlAnimation.Path)
+	}
+	return animation
+}
+
+func createCameraPath(yamlPath *CameraPathModel) *scene.CameraPath {
+	from := make([]math.Point, 0, len(yamlPath.From))
+	for _, p := range yamlPath.From {
+		from = append(from, mapPoint(&p))
+	}
+	to := make([]math.Point, 0, len(yamlPath.To))
+	for _, p := range yamlPath.To {
+		to = append(to, mapPoint(&p))
+	}
+
+	path := scene.CreateCameraPath(splines[yamlPath.Spline], from, to)
+	for _, fov := range yamlPath.Fov {
+		path.FieldOfView = append(path.FieldOfView, math.Radians(fov))
+	}
+	return path
 }
 
-func createLight(yamlLight LightModel) lighting.Light {
+func createLight(yamlLight LightModel) *lighting.PointLight {
 	p := mapPoint(yamlLight.Position)
 	intensity := mapColor(yamlLight.Intensity)
 	return lighting.CreateLight(p, intensity)
 }
 
-func collectRootElements() []geometry.Shape {
+func createAreaLight(yamlLight AreaLightModel) *lighting.AreaLight {
+	corner := mapPoint(yamlLight.Corner)
+	u := mapVector(yamlLight.U)
+	v := mapVector(yamlLight.V)
+	intensity := mapColor(yamlLight.Intensity)
+	light := lighting.CreateAreaLight(corner, u, yamlLight.USteps, v, yamlLight.VSteps, intensity)
+	light.Jitter = yamlLight.Jitter
+	return light
+}
+
+func createDirectionalLight(yamlLight DirectionalLightModel) *lighting.DirectionalLight {
+	direction := mapVector(yamlLight.Direction)
+	intensity := mapColor(yamlLight.Intensity)
+	return lighting.CreateDirectionalLight(direction, intensity)
+}
+
+func createSpotLight(yamlLight SpotLightModel) *lighting.SpotLight {
+	p := mapPoint(yamlLight.Position)
+	direction := mapVector(yamlLight.Direction)
+	inner := math.Radians(yamlLight.InnerAngle)
+	outer := math.Radians(yamlLight.OuterAngle)
+	intensity := mapColor(yamlLight.Intensity)
+	return lighting.CreateSpotLight(p, direction, inner, outer, intensity)
+}
+
+func (b *SceneBuilder) collectRootElements() []geometry.Shape {
 	elements := make([]geometry.Shape, 0)
 
-	for name, shape := range raygoShapes {
-		if _, ok := childrenObjects[name]; !ok {
+	for name, shape := range b.raygoShapes {
+		if _, ok := b.childrenObjects[name]; !ok {
 			elements = append(elements, shape)
 		}
 	}
@@ -378,23 +991,24 @@ func collectRootElements() []geometry.Shape {
 	return elements
 }
 
-func createRaygoColors() {
-	raygoColors = make(map[string]*math.Color)
-	for _, v := range yamlColors {
+func (b *SceneBuilder) createRaygoColors() {
+	b.raygoColors = make(map[string]*math.Color)
+	for _, v := range b.yamlColors {
 		rc := math.CreateColor(math.BToF(v.R), math.BToF(v.G), math.BToF(v.B))
-		raygoColors[v.Name] = &rc
+		b.raygoColors[v.Name] = &rc
 	}
 }
 
-func createRaygoTransformations() {
-	raygoTransforms = make(map[string]math.Matrix)
-	for name, ytf := range yamlTransforms {
+func (b *SceneBuilder) createRaygoTransformations() error {
+	b.raygoTransforms = make(map[string]math.Matrix)
+	for name, ytf := range b.yamlTransforms {
 		tf, err := mapTransform(&ytf.TransformModel)
 		if err != nil {
-			panic(fmt.Sprintf("could not map transform '%v'", name))
+			return fmt.Errorf("could not map transform '%v': %w", name, err)
 		}
-		raygoTransforms[name] = tf
+		b.raygoTransforms[name] = tf
 	}
+	return nil
 }
 
 func mapTransform(ymlTransform *TransformModel) (math.Matrix, error) {
@@ -429,38 +1043,39 @@ func mapTransform(ymlTransform *TransformModel) (math.Matrix, error) {
 	return math.Matrix{}, fmt.Errorf("unknown transform type '%v'", ymlTransform.Type)
 }
 
-func createRaygoPatterns() {
-	raygoPatterns = make(map[string]geometry.Pattern)
+func (b *SceneBuilder) createRaygoPatterns() {
+	b.raygoPatterns = make(map[string]geometry.Pattern)
 
-	for name, yp := range yamlCheckerPatterns {
-		c1 := raygoColors[yp.ColorA]
-		c2 := raygoColors[yp.ColorB]
-		raygoPatterns[name] = geometry.CreateCheckerPattern(*c1, *c2)
+	for name, yp := range b.yamlCheckerPatterns {
+		c1 := b.raygoColors[yp.ColorA]
+		c2 := b.raygoColors[yp.ColorB]
+		b.raygoPatterns[name] = geometry.CreateCheckerPattern(*c1, *c2)
 	}
 
-	for name, yp := range yamlGradientPatterns {
-		c1 := raygoColors[yp.ColorA]
-		c2 := raygoColors[yp.ColorB]
-		raygoPatterns[name] = geometry.CreateGradientPattern(*c1, *c2)
+	for name, yp := range b.yamlGradientPatterns {
+		c1 := b.raygoColors[yp.ColorA]
+		c2 := b.raygoColors[yp.ColorB]
+		b.raygoPatterns[name] = geometry.CreateGradientPattern(*c1, *c2)
 	}
 
-	for name, yp := range yamlRingPatterns {
-		c1 := raygoColors[yp.ColorA]
-		c2 := raygoColors[yp.ColorB]
-		raygoPatterns[name] = geometry.CreateRingPattern(*c1, *c2)
+	for name, yp := range b.yamlRingPatterns {
+		c1 := b.raygoColors[yp.ColorA]
+		c2 := b.raygoColors[yp.ColorB]
+		b.raygoPatterns[name] = geometry.CreateRingPattern(*c1, *c2)
 	}
 
-	for name, yp := range yamlStripePatterns {
-		c1 := raygoColors[yp.ColorA]
-		c2 := raygoColors[yp.ColorB]
-		raygoPatterns[name] = geometry.CreateStripePattern(*c1, *c2)
+	for name, yp := range b.yamlStripePatterns {
+		c1 := b.raygoColors[yp.ColorA]
+		c2 := b.raygoColors[yp.ColorB]
+		b.raygoPatterns[name] = geometry.CreateStripePattern(*c1, *c2)
 	}
 }
 
-func createRaygoMaterials() {
-	raygoMaterials = make(map[string]*geometry.Material)
+func (b *SceneBuilder) createRaygoMaterials() {
+	b.raygoMaterials = make(map[string]*geometry.Material)
 
-	for name, ym := range yamlMaterials {
+	for name, named := range b.yamlMaterials {
+		ym := b.resolveMaterial(named)
 		m := geometry.DefaultMaterial()
 		if ym.Ambient != nil {
 			m.Ambient = *ym.Ambient
@@ -489,11 +1104,11 @@ func createRaygoMaterials() {
 		}
 
 		if ym.Color != "" {
-			m.Color = *raygoColors[ym.Color]
+			m.Color = *b.raygoColors[ym.Color]
 		}
 
 		if ym.Pattern != "" {
-			m.Pattern = raygoPatterns[ym.Pattern]
+			m.Pattern = b.raygoPatterns[ym.Pattern]
 		}
 
 		if ym.Texture != nil {
@@ -503,87 +1118,116 @@ func createRaygoMaterials() {
 			}
 		}
 
-		raygoMaterials[name] = &m
+		b.raygoMaterials[name] = &m
 	}
 }
 
-func createRaygoShapes(directory string) {
-	raygoShapes = make(map[string]geometry.Shape)
+func (b *SceneBuilder) createRaygoShapes() error {
+	b.raygoShapes = make(map[string]geometry.Shape)
+	b.objPrototypes = make(map[string]*geometry.Group)
+	b.childrenObjects = make(map[string]struct{})
+
+	creators := []func() error{
+		b.createRaygoPlanes,
+		b.createRaygoSpheres,
+		b.createRaygoCubes,
+		b.createRaygoCylinders,
+		b.createRaygoCones,
+		b.createRaygoTori,
+		b.createRaygoDisks,
+		b.createRaygoQuads,
+		b.createRaygoSDFs,
+		b.createRaygoHeightfields,
+		b.createRaygoTriangles,
+		b.createRaygoObjects,
+		// groups and csgs need to be last, they can reference all other shapes
+		b.createRaygoComposites,
+	}
+	for _, create := range creators {
+		if err := create(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	createRaygoPlanes()
-	createRaygoSpheres()
-	createRaygoCubes()
-	createRaygoCylinders()
-	createRaygoCones()
-	createRaygoTriangles()
-	createRaygoObjects(directory)
-	// groups need to be last, they can reference all other shapes
-	createRaygoGroups()
+// createTransform returns the named transform of the scene object or combines its list of transforms
+func (b *SceneBuilder) createTransform(sceneObject CommonSceneObject) (math.Matrix, error) {
+	if sceneObject.Transform != "" {
+		return b.raygoTransforms[sceneObject.Transform], nil
+	}
+	tf, err := createTransformFromList(sceneObject.Transforms)
+	if err != nil {
+		return math.Matrix{}, fmt.Errorf("invalid transforms for scene object '%v': %w", sceneObject.Name, err)
+	}
+	return tf, nil
 }
 
-func createRaygoPlanes() {
-	for name, yp := range yamlPlanes {
+func (b *SceneBuilder) createRaygoPlanes() error {
+	for name, yp := range b.yamlPlanes {
 		plane := geometry.CreatePlane()
-		if yp.Transform != "" {
-			plane.Transform = raygoTransforms[name]
-		} else {
-			plane.Transform = createTransformFromList(yp.Transforms)
+		tf, err := b.createTransform(yp.CommonSceneObject)
+		if err != nil {
+			return err
 		}
+		plane.Transform = tf
 
 		if yp.Material != "" {
-			plane.Material = *raygoMaterials[yp.Material]
+			plane.Material = *b.raygoMaterials[yp.Material]
 		}
 
-		raygoShapes[name] = plane
+		b.raygoShapes[name] = plane
 	}
+	return nil
 }
 
-func createRaygoSpheres() {
-	for name, ys := range yamlSpheres {
+func (b *SceneBuilder) createRaygoSpheres() error {
+	for name, ys := range b.yamlSpheres {
 		sphere := geometry.CreateSphere()
-
-		if ys.Transform != "" {
-			sphere.Transform = raygoTransforms[name]
-		} else {
-			sphere.Transform = createTransformFromList(ys.Transforms)
+		tf, err := b.createTransform(ys.CommonSceneObject)
+		if err != nil {
+			return err
 		}
+		sphere.Transform = tf
 
 		if ys.Material != "" {
-			sphere.Material = *raygoMaterials[ys.Material]
+			sphere.Material = *b.raygoMaterials[ys.Material]
 		}
 
-		raygoShapes[name] = sphere
+		b.raygoShapes[name] = sphere
 	}
+	return nil
 }
 
-func createRaygoCubes() {
-	for name, yc := range yamlCubes {
+func (b *SceneBuilder) createRaygoCubes() error {
+	for name, yc := range b.yamlCubes {
 		cube := geometry.CreateCube()
-		if yc.Transform != "" {
-			cube.Transform = raygoTransforms[name]
-		} else {
-			cube.Transform = createTransformFromList(yc.Transforms)
+		tf, err := b.createTransform(yc.CommonSceneObject)
+		if err != nil {
+			return err
 		}
+		cube.Transform = tf
 
 		if yc.Material != "" {
-			cube.Material = *raygoMaterials[yc.Material]
+			cube.Material = *b.raygoMaterials[yc.Material]
 		}
 
-		raygoShapes[name] = cube
+		b.raygoShapes[name] = cube
 	}
+	return nil
 }
 
-func createRaygoCylinders() {
-	for name, yc := range yamlCylinders {
+func (b *SceneBuilder) createRaygoCylinders() error {
+	for name, yc := range b.yamlCylinders {
 		cylinder := geometry.CreateCylinder()
-		if yc.Transform != "" {
-			cylinder.Transform = raygoTransforms[name]
-		} else {
-			cylinder.Transform = createTransformFromList(yc.Transforms)
+		tf, err := b.createTransform(yc.CommonSceneObject)
+		if err != nil {
+			return err
 		}
+		cylinder.Transform = tf
 
 		if yc.Material != "" {
-			cylinder.Material = *raygoMaterials[yc.Material]
+			cylinder.Material = *b.raygoMaterials[yc.Material]
 		}
 
 		if yc.Minimum != nil {
@@ -594,21 +1238,22 @@ func createRaygoCylinders() {
 		}
 
 		cylinder.Closed = yc.Closed
-		raygoShapes[name] = cylinder
+		b.raygoShapes[name] = cylinder
 	}
+	return nil
 }
 
-func createRaygoCones() {
-	for name, yc := range yamlCones {
+func (b *SceneBuilder) createRaygoCones() error {
+	for name, yc := range b.yamlCones {
 		cone := geometry.CreateCone()
-		if yc.Transform != "" {
-			cone.Transform = raygoTransforms[name]
-		} else {
-			cone.Transform = createTransformFromList(yc.Transforms)
+		tf, err := b.createTransform(yc.CommonSceneObject)
+		if err != nil {
+			return err
 		}
+		cone.Transform = tf
 
 		if yc.Material != "" {
-			cone.Material = *raygoMaterials[yc.Material]
+			cone.Material = *b.raygoMaterials[yc.Material]
 		}
 
 		if yc.Minimum != nil {
@@ -619,79 +1264,352 @@ func createRaygoCones() {
 		}
 
 		cone.Closed = yc.Closed
-		raygoShapes[name] = cone
+		b.raygoShapes[name] = cone
 	}
+	return nil
 }
 
-func createRaygoTriangles() {
-	for name, yt := range yamlTriangles {
+func (b *SceneBuilder) createRaygoTori() error {
+	for name, yt := range b.yamlTori {
+		torus := geometry.CreateTorus()
+		tf, err := b.createTransform(yt.CommonSceneObject)
+		if err != nil {
+			return err
+		}
+		torus.Transform = tf
+
+		if yt.Material != "" {
+			torus.Material = *b.raygoMaterials[yt.Material]
+		}
+
+		if yt.MajorRadius != nil {
+			torus.MajorRadius = *yt.MajorRadius
+		}
+		if yt.MinorRadius != nil {
+			torus.MinorRadius = *yt.MinorRadius
+		}
+		b.raygoShapes[name] = torus
+	}
+	return nil
+}
+
+func (b *SceneBuilder) createRaygoDisks() error {
+	for name, yd := range b.yamlDisks {
+		disk := geometry.CreateDisk()
+		tf, err := b.createTransform(yd.CommonSceneObject)
+		if err != nil {
+			return err
+		}
+		disk.Transform = tf
+
+		if yd.Material != "" {
+			disk.Material = *b.raygoMaterials[yd.Material]
+		}
+
+		if yd.Radius != nil {
+			disk.Radius = *yd.Radius
+		}
+		if yd.InnerRadius != nil {
+			disk.InnerRadius = *yd.InnerRadius
+		}
+		b.raygoShapes[name] = disk
+	}
+	return nil
+}
+
+func (b *SceneBuilder) createRaygoQuads() error {
+	for name, yq := range b.yamlQuads {
+		quad := geometry.CreateQuad()
+		tf, err := b.createTransform(yq.CommonSceneObject)
+		if err != nil {
+			return err
+		}
+		quad.Transform = tf
+
+		if yq.Material != "" {
+			quad.Material = *b.raygoMaterials[yq.Material]
+		}
+
+		b.raygoShapes[name] = quad
+	}
+	return nil
+}
+
+func (b *SceneBuilder) createRaygoSDFs() error {
+	for name, ys := range b.yamlSDFs {
+		sdf := geometry.CreateSDF(createSdfNode(ys.Shape))
+		tf, err := b.createTransform(ys.CommonSceneObject)
+		if err != nil {
+			return err
+		}
+		sdf.Transform = tf
+
+		if ys.Material != "" {
+			sdf.Material = *b.raygoMaterials[ys.Material]
+		}
+
+		b.raygoShapes[name] = sdf
+	}
+	return nil
+}
+
+func createSdfNode(yn *SdfNodeModel) geometry.SdfNode {
+	center := func(p *PointModel) math.Point {
+		if p == nil {
+			return math.CreatePoint(0.0, 0.0, 0.0)
+		}
+		return mapPoint(p)
+	}
+
+	switch {
+	case yn.Sphere != nil:
+		radius := 1.0
+		if yn.Sphere.Radius != nil {
+			radius = *yn.Sphere.Radius
+		}
+		return geometry.CreateSdfSphere(center(yn.Sphere.Center), radius)
+	case yn.Box != nil:
+		halfSize := mapVector(yn.Box.Size).Mul(0.5)
+		return geometry.CreateSdfBox(center(yn.Box.Center), halfSize)
+	case yn.Torus != nil:
+		major, minor := 1.0, 0.25
+		if yn.Torus.MajorRadius != nil {
+			major = *yn.Torus.MajorRadius
+		}
+		if yn.Torus.MinorRadius != nil {
+			minor = *yn.Torus.MinorRadius
+		}
+		return geometry.CreateSdfTorus(center(yn.Torus.Center), major, minor)
+	case yn.Capsule != nil:
+		return geometry.CreateSdfCapsule(mapPoint(yn.Capsule.A), mapPoint(yn.Capsule.B), *yn.Capsule.Radius)
+	case yn.Union != nil:
+		return createSdfCombination(geometry.SDF_UNION, yn.Union)
+	case yn.Subtraction != nil:
+		return createSdfCombination(geometry.SDF_SUBTRACTION, yn.Subtraction)
+	case yn.Intersection != nil:
+		return createSdfCombination(geometry.SDF_INTERSECTION, yn.Intersection)
+	case yn.Repeat != nil:
+		count := [3]int{1, 1, 1}
+		if yn.Repeat.Count != nil {
+			count = [3]int{yn.Repeat.Count.X, yn.Repeat.Count.Y, yn.Repeat.Count.Z}
+		}
+		return geometry.CreateSdfRepetition(createSdfNode(yn.Repeat.Shape), mapVector(yn.Repeat.Spacing), count)
+	case yn.Twist != nil:
+		return geometry.CreateSdfTwist(createSdfNode(yn.Twist.Shape), math.Radians(*yn.Twist.Angle))
+	}
+	return nil
+}
+
+// createSdfCombination folds the shapes from left to right
+func createSdfCombination(op geometry.SdfOperation, yc *SdfCombinationModel) geometry.SdfNode {
+	node := createSdfNode(&yc.Shapes[0])
+	for i := range yc.Shapes[1:] {
+		node = geometry.CreateSdfCombination(op, node, createSdfNode(&yc.Shapes[i+1]), yc.Smoothness)
+	}
+	return node
+}
+
+func (b *SceneBuilder) createRaygoHeightfields() error {
+	for name, yh := range b.yamlHeightfields {
+		heightfield, err := geometry.LoadHeightfield(fmt.Sprintf("%v%v", b.directory, yh.File))
+		if err != nil {
+			return &LoadError{Name: name, Err: err}
+		}
+		if yh.Scale != nil {
+			heightfield.Scale = mapVector(yh.Scale)
+		}
+
+		tf, err := b.createTransform(yh.CommonSceneObject)
+		if err != nil {
+			return err
+		}
+		heightfield.Transform = tf
+
+		if yh.Material != "" {
+			heightfield.Material = *b.raygoMaterials[yh.Material]
+		}
+
+		b.raygoShapes[name] = heightfield
+	}
+	return nil
+}
+
+func (b *SceneBuilder) createRaygoTriangles() error {
+	for name, yt := range b.yamlTriangles {
 		triangle := geometry.CreateTriangle(mapPoint(yt.P1), mapPoint(yt.P2), mapPoint(yt.P3))
-		if yt.Transform != "" {
-			triangle.Transform = raygoTransforms[name]
-		} else {
-			triangle.Transform = createTransformFromList(yt.Transforms)
+		tf, err := b.createTransform(yt.CommonSceneObject)
+		if err != nil {
+			return err
 		}
+		triangle.Transform = tf
 
 		if yt.Material != "" {
-			triangle.Material = *raygoMaterials[yt.Material]
+			triangle.Material = *b.raygoMaterials[yt.Material]
 		}
 
-		raygoShapes[name] = triangle
+		b.raygoShapes[name] = triangle
 	}
+	return nil
 }
 
-func createRaygoObjects(directory string) {
-	for name, yo := range yamlObjects {
-		objData := obj.ParseFile(fmt.Sprintf("%v%v", directory, yo.File))
-		objGroup := objData.ToGroup(true)
+func (b *SceneBuilder) createRaygoObjects() error {
+	for name, yo := range b.yamlObjects {
+		prototype, err := b.loadObjPrototype(fmt.Sprintf("%v%v", b.directory, yo.File))
+		if err != nil {
+			return &LoadError{Name: name, Err: err}
+		}
+		instance := geometry.CreateInstance(prototype)
 
-		if yo.Transform != "" {
-			objGroup.Transform = raygoTransforms[name]
-		} else {
-			objGroup.Transform = createTransformFromList(yo.Transforms)
+		tf, err := b.createTransform(yo.CommonSceneObject)
+		if err != nil {
+			return err
 		}
+		instance.Transform = tf
 
 		if yo.Material != "" {
-			objGroup.Material = *raygoMaterials[yo.Material]
+			instance.SetMaterial(*b.raygoMaterials[yo.Material])
+		}
+		for objMaterial, material := range yo.Materials {
+			if !prototypeUsesMaterial(prototype, objMaterial) {
+				return &LoadError{Name: name, Err: fmt.Errorf("'%v' has no material '%v'", yo.File, objMaterial)}
+			}
+			instance.SetNamedMaterial(objMaterial, *b.raygoMaterials[material])
 		}
 
-		raygoShapes[name] = objGroup
+		b.raygoShapes[name] = instance
 	}
+	return nil
 }
 
-func createRaygoGroups() {
-	for name, yg := range yamlGroups {
-		group := geometry.EmptyGroup()
-		if yg.Transform != "" {
-			group.Transform = raygoTransforms[name]
-		} else {
-			group.Transform = createTransformFromList(yg.Transforms)
+// prototypeUsesMaterial checks if any triangle of the prototype has the material of an mtl file called name
+func prototypeUsesMaterial(prototype *geometry.Group, name string) bool {
+	for _, child := range prototype.Children {
+		if group, ok := child.(*geometry.Group); ok {
+			if prototypeUsesMaterial(group, name) {
+				return true
+			}
+		} else if child.GetMaterial().Name == name {
+			return true
 		}
+	}
+	return false
+}
+
+// loadObjPrototype returns the triangles of the obj file at path and parses it first if necessary.
+// The prototype is shared by all instances, so its inverse transforms are calculated here only once.
+func (b *SceneBuilder) loadObjPrototype(path string) (*geometry.Group, error) {
+	if prototype, ok := b.objPrototypes[path]; ok {
+		return prototype, nil
+	}
+	objData, err := obj.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	prototype := objData.ToGroup(true)
+	prototype.CalculateInverseTransform()
+	b.objPrototypes[path] = prototype
+	return prototype, nil
+}
 
-		if yg.Material != "" {
-			group.Material = *raygoMaterials[yg.Material]
+func (b *SceneBuilder) createRaygoComposites() error {
+	for name := range b.yamlGroups {
+		if _, err := b.createRaygoComposite(name); err != nil {
+			return err
 		}
+	}
+	for name := range b.yamlCsgs {
+		if _, err := b.createRaygoComposite(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createRaygoComposite returns the group or csg with the given name and creates it
+// first if necessary. Groups and csgs can reference each other in any order,
+// so children are created on demand.
+func (b *SceneBuilder) createRaygoComposite(name string) (geometry.Shape, error) {
+	if shape, ok := b.raygoShapes[name]; ok {
+		return shape, nil
+	}
+	if yg := b.yamlGroups[name]; yg != nil {
+		return b.createRaygoGroup(name, yg)
+	}
+	return b.createRaygoCsg(name, b.yamlCsgs[name])
+}
 
-		for _, child := range yg.Children {
-			// mark child as dependent on group
-			childrenObjects[child] = struct{}{}
-			// get raygo child and add to group
-			raygoChild := raygoShapes[child]
-			group.AddChild(raygoChild)
+func (b *SceneBuilder) createRaygoGroup(name string, yg *GroupModel) (*geometry.Group, error) {
+	group := geometry.EmptyGroup()
+	tf, err := b.createTransform(yg.CommonSceneObject)
+	if err != nil {
+		return nil, err
+	}
+	group.Transform = tf
+
+	if yg.Material != "" {
+		group.Material = *b.raygoMaterials[yg.Material]
+	}
+
+	for _, child := range yg.Children {
+		// mark child as dependent on group
+		b.childrenObjects[child] = struct{}{}
+		// get raygo child and add to group
+		raygoChild, err := b.createRaygoComposite(child)
+		if err != nil {
+			return nil, err
 		}
+		group.AddChild(raygoChild)
+	}
+
+	if yg.Bvh {
+		group.BuildBVH()
+	}
+
+	b.raygoShapes[name] = group
+	return group, nil
+}
 
-		raygoShapes[name] = group
+var csgOperations = map[string]geometry.CsgOperation{
+	"union":        geometry.UNION,
+	"intersection": geometry.INTERSECTION,
+	"difference":   geometry.DIFFERENCE,
+}
+
+func (b *SceneBuilder) createRaygoCsg(name string, yc *CsgModel) (*geometry.CSG, error) {
+	b.childrenObjects[yc.Left] = struct{}{}
+	b.childrenObjects[yc.Right] = struct{}{}
+	left, err := b.createRaygoComposite(yc.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := b.createRaygoComposite(yc.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	csg := geometry.CreateCSG(csgOperations[yc.Operation], left, right)
+	tf, err := b.createTransform(yc.CommonSceneObject)
+	if err != nil {
+		return nil, err
 	}
+	csg.SetTransform(tf)
+
+	if yc.Material != "" {
+		csg.SetMaterial(*b.raygoMaterials[yc.Material])
+	}
+
+	b.raygoShapes[name] = csg
+	return csg, nil
 }
 
-func createTransformFromList(tfList []TransformModel) math.Matrix {
+func createTransformFromList(tfList []TransformModel) (math.Matrix, error) {
 	result := math.IdentityMatrix()
 
 	transforms := make([]math.Matrix, 0)
 	for _, tf := range tfList {
 		mappedTf, err := mapTransform(&tf)
 		if err != nil {
-			panic(err)
+			return math.Matrix{}, err
 		}
 		transforms = append(transforms, mappedTf)
 	}
@@ -701,13 +1619,17 @@ func createTransformFromList(tfList []TransformModel) math.Matrix {
 		result = result.MulM(tf)
 	}
 
-	return result
+	return result, nil
 }
 
 func mapPoint(yamlPoint *PointModel) math.Point {
 	return math.CreatePoint(yamlPoint.X, yamlPoint.Y, yamlPoint.Z)
 }
 
+func mapVector(yamlVector *VectorModel) math.Vector {
+	return math.CreateVector(yamlVector.X, yamlVector.Y, yamlVector.Z)
+}
+
 func mapColor(yamlColor *ColorModel) math.Color {
 	return math.CreateColor(math.BToF(yamlColor.R), math.BToF(yamlColor.G), math.BToF(yamlColor.B))
 }
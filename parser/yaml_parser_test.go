@@ -1,6 +1,21 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	gomath "math"
+	"os"
+	"path/filepath"
+	"raygo/geometry"
+	"raygo/math"
+	"raygo/scene"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -18,7 +33,8 @@ colors:
     g: 0
     b: 0`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Colors) == 2)
@@ -40,7 +56,8 @@ patterns:
       colorA: light_gray
       colorB: black`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Patterns.Checker) == 1)
@@ -64,7 +81,8 @@ patterns:
           x: 100
           y: 23.4`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Patterns.Checker) == 1)
@@ -90,7 +108,8 @@ materials:
     transparency: 0.6
     refractiveIndex: 0.7`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Patterns.Checker) == 0)
@@ -107,6 +126,89 @@ materials:
 	assert.Assert(t, *desc.Materials[0].RefractiveIndex == 0.7)
 }
 
+func TestCreateMaterialWithExtends(t *testing.T) {
+	yml := `
+colors:
+  - name: white
+    r: 255
+    g: 255
+    b: 255
+materials:
+  - name: glass
+    color: white
+    diffuse: 0.1
+    transparency: 0.9
+    refractiveIndex: 1.5
+  - name: tinted_glass
+    extends: glass
+    rawColor:
+      r: 0
+      g: 255
+      b: 0
+    refractiveIndex: 1.52
+  - name: frosted_tinted_glass
+    extends: tinted_glass
+    diffuse: 0.4`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	builder := CreateSceneBuilder(desc, "")
+	assert.Assert(t, len(builder.validateMaterialReferences()) == 0)
+	builder.createRaygoColors()
+	builder.createRaygoMaterials()
+
+	glass := builder.raygoMaterials["glass"]
+	assert.Assert(t, glass.Color.Equals(math.CreateColor(1, 1, 1)))
+
+	tinted := builder.raygoMaterials["tinted_glass"]
+	// a raw color replaces the inherited named color
+	assert.Assert(t, tinted.Color.Equals(math.CreateColor(0, 1, 0)))
+	assert.Assert(t, tinted.Diffuse == 0.1)
+	assert.Assert(t, tinted.Transparency == 0.9)
+	assert.Assert(t, tinted.RefractiveIndex == 1.52)
+
+	frosted := builder.raygoMaterials["frosted_tinted_glass"]
+	assert.Assert(t, frosted.Color.Equals(math.CreateColor(0, 1, 0)))
+	assert.Assert(t, frosted.Diffuse == 0.4)
+	assert.Assert(t, frosted.Transparency == 0.9)
+	assert.Assert(t, frosted.RefractiveIndex == 1.52)
+	// unset in the whole chain
+	assert.Assert(t, frosted.Specular == geometry.DefaultMaterial().Specular)
+}
+
+func TestValidateMaterialExtends(t *testing.T) {
+	yml := `
+materials:
+  - name: a
+    extends: b
+  - name: b
+    extends: c
+  - name: c
+    extends: a
+  - name: d
+    extends: a
+  - name: e
+    extends: missing`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	errs := CreateSceneBuilder(desc, "").validateMaterialReferences()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return err.Error() == "material 'a' extends itself: a -> b -> c -> a"
+	}), "%v", errs)
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		var fieldErr *FieldError
+		return errors.As(err, &fieldErr) && fieldErr.Path == "$.materials[2].extends"
+	}), "%v", errs)
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return err.Error() == "cannot resolve material 'missing' extended by material 'e'"
+	}), "%v", errs)
+	// only the members of the cycle are reported
+	assert.Assert(t, len(errs) == 4, "%v", errs)
+}
+
 func TestParseSceneObject(t *testing.T) {
 	yml := `
 scene:
@@ -115,7 +217,8 @@ scene:
       material: black_mat
       transform: rotate_right_90`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Patterns.Checker) == 0)
@@ -147,7 +250,8 @@ scene:
         - g1
         - c1`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Patterns.Checker) == 0)
@@ -183,7 +287,8 @@ scene:
         y: 20.0
         z: 30.0`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Patterns.Checker) == 0)
@@ -209,7 +314,8 @@ scene:
       max: 100.0
       closed: false`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Patterns.Checker) == 0)
@@ -234,7 +340,8 @@ scene:
       transform: rotate_right_90
       file: ../obj/test.obj`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, len(desc.Patterns.Checker) == 0)
@@ -266,7 +373,8 @@ camera:
     y: 1.0
     z: 0.0`
 
-	desc := ParseYaml(yml)
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
 
 	assert.Assert(t, desc != nil)
 	assert.Assert(t, desc.Width == 400)
@@ -277,3 +385,1961 @@ camera:
 	assert.Assert(t, desc.Camera.LookAt == "")
 	assert.Assert(t, desc.Camera.Animation == nil)
 }
+
+func TestParseLegacyLight(t *testing.T) {
+	yml := `
+light:
+  p:
+    x: -3
+    y: 30
+    z: -20
+  intensity:
+    r: 255
+    g: 255
+    b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	assert.Assert(t, desc != nil)
+	assert.Assert(t, desc.Light != nil)
+	assert.Assert(t, len(desc.Lights) == 0)
+	assert.Assert(t, len(desc.GetLights()) == 1)
+	assert.Assert(t, desc.GetLights()[0].Position.X == -3.0)
+}
+
+func TestParseMultipleLights(t *testing.T) {
+	yml := `
+lights:
+  - p:
+      x: -3
+      y: 30
+      z: -20
+    intensity:
+      r: 255
+      g: 255
+      b: 255
+  - p:
+      x: 10
+      y: 10
+      z: 10
+    intensity:
+      r: 128
+      g: 0
+      b: 0`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	assert.Assert(t, desc != nil)
+	assert.Assert(t, desc.Light == nil)
+	assert.Assert(t, len(desc.Lights) == 2)
+	assert.Assert(t, len(desc.GetLights()) == 2)
+	assert.Assert(t, desc.Lights[1].Position.X == 10.0)
+	assert.Assert(t, desc.Lights[1].Intensity.R == 128)
+}
+
+func TestValidateMissingLight(t *testing.T) {
+	desc := YamlDescription{
+		Width:  100,
+		Height: 100,
+	}
+
+	errs := desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "at least one light")
+	}))
+}
+
+func TestValidateEveryLight(t *testing.T) {
+	desc := YamlDescription{
+		Width:  100,
+		Height: 100,
+		Light: &LightModel{
+			Position:  &PointModel{},
+			Intensity: &ColorModel{},
+		},
+		Lights: []LightModel{
+			{Position: &PointModel{}},
+		},
+	}
+
+	errs := desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "'intensity'")
+	}))
+}
+
+func TestParseAreaLight(t *testing.T) {
+	yml := `
+areaLights:
+  - corner:
+      x: -1
+      y: 10
+      z: -1
+    u:
+      x: 2
+      y: 0
+      z: 0
+    v:
+      x: 0
+      y: 0
+      z: 2
+    uSteps: 4
+    vSteps: 8
+    jitter: true
+    intensity:
+      r: 255
+      g: 255
+      b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	assert.Assert(t, desc != nil)
+	assert.Assert(t, len(desc.AreaLights) == 1)
+	assert.Assert(t, desc.AreaLights[0].Corner.Y == 10.0)
+	assert.Assert(t, desc.AreaLights[0].U.X == 2.0)
+	assert.Assert(t, desc.AreaLights[0].V.Z == 2.0)
+	assert.Assert(t, desc.AreaLights[0].USteps == 4)
+	assert.Assert(t, desc.AreaLights[0].VSteps == 8)
+	assert.Assert(t, desc.AreaLights[0].Jitter)
+}
+
+func TestJitteredAreaLightRendersDeterministically(t *testing.T) {
+	yml := `
+width: 20
+height: 20
+render:
+  samples: 1
+  seed: 7
+scene:
+  spheres:
+    - name: s1
+  planes:
+    - name: floor
+      transforms:
+        - type: translation
+          x: 0
+          y: -1
+          z: 0
+camera:
+  from:
+    x: 0
+    y: 2
+    z: -5
+  to:
+    x: 0
+    y: 0
+    z: 0
+  up:
+    x: 0
+    y: 1
+    z: 0
+areaLights:
+  - corner:
+      x: -2
+      y: 5
+      z: -2
+    u:
+      x: 4
+      y: 0
+      z: 0
+    v:
+      x: 0
+      y: 0
+      z: 4
+    uSteps: 2
+    vSteps: 2
+    jitter: true
+    intensity:
+      r: 255
+      g: 255
+      b: 255`
+
+	render := func() []math.Color {
+		desc, err := ParseYaml(yml)
+		assert.NilError(t, err)
+		builder := CreateSceneBuilder(desc, "")
+		world, err := builder.CreateWorld()
+		assert.NilError(t, err)
+		canv := builder.CreateCamera().RenderMultithreaded(world, 4)
+		return canv.Pixels
+	}
+
+	first := render()
+	second := render()
+	for i := range first {
+		assert.Assert(t, first[i] == second[i], "pixel %d differs", i)
+	}
+}
+
+func TestValidateAreaLightSteps(t *testing.T) {
+	desc := YamlDescription{
+		Width:  100,
+		Height: 100,
+		AreaLights: []AreaLightModel{
+			{
+				Corner:    &PointModel{},
+				U:         &VectorModel{},
+				V:         &VectorModel{},
+				Intensity: &ColorModel{},
+			},
+		},
+	}
+
+	errs := desc.Validate()
+
+	assert.Assert(t, !slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "at least one light")
+	}))
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "'uSteps' and 'vSteps'")
+	}))
+}
+
+func TestParseDirectionalAndSpotLights(t *testing.T) {
+	yml := `
+directionalLights:
+  - direction:
+      x: 0
+      y: -1
+      z: 1
+    intensity:
+      r: 255
+      g: 240
+      b: 200
+spotLights:
+  - p:
+      x: 0
+      y: 10
+      z: 0
+    direction:
+      x: 0
+      y: -1
+      z: 0
+    innerAngle: 15
+    outerAngle: 25
+    intensity:
+      r: 255
+      g: 255
+      b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	assert.Assert(t, desc != nil)
+	assert.Assert(t, len(desc.DirectionalLights) == 1)
+	assert.Assert(t, desc.DirectionalLights[0].Direction.Z == 1.0)
+	assert.Assert(t, desc.DirectionalLights[0].Intensity.B == 200)
+	assert.Assert(t, len(desc.SpotLights) == 1)
+	assert.Assert(t, desc.SpotLights[0].Position.Y == 10.0)
+	assert.Assert(t, desc.SpotLights[0].Direction.Y == -1.0)
+	assert.Assert(t, desc.SpotLights[0].InnerAngle == 15.0)
+	assert.Assert(t, desc.SpotLights[0].OuterAngle == 25.0)
+}
+
+func TestValidateSpotLightAngles(t *testing.T) {
+	desc := YamlDescription{
+		Width:  100,
+		Height: 100,
+		SpotLights: []SpotLightModel{
+			{
+				Position:   &PointModel{},
+				Direction:  &VectorModel{PointModel{Y: -1.0}},
+				InnerAngle: 30.0,
+				OuterAngle: 20.0,
+				Intensity:  &ColorModel{},
+			},
+		},
+	}
+
+	errs := desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "'innerAngle'")
+	}))
+}
+
+func TestCreateCameraWithFovAndUp(t *testing.T) {
+	yml := `
+width: 200
+height: 125
+camera:
+  from:
+    x: 0.0
+    y: 0.0
+    z: -5.0
+  to:
+    x: 0.0
+    y: 0.0
+    z: 0.0
+  up:
+    x: 1.0
+    y: 0.0
+    z: 0.0
+  fov: 90`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	camera := CreateSceneBuilder(desc, "").CreateCamera()
+
+	assert.Assert(t, *desc.Camera.Fov == 90.0)
+	assert.Assert(t, floatEquals(camera.FieldOfView, gomath.Pi/2.0))
+	assert.Assert(t, floatEquals(camera.PixelSize, 0.01))
+	assert.Assert(t, math.CreateVector(1.0, 0.0, 0.0).Equals(camera.Position.Up))
+}
+
+func TestCreateCameraDefaultFov(t *testing.T) {
+	yml := `
+width: 200
+height: 125
+camera:
+  from:
+    x: 0.0
+    y: 0.0
+    z: -5.0
+  to:
+    x: 0.0
+    y: 0.0
+    z: 0.0
+  up:
+    x: 0.0
+    y: 1.0
+    z: 0.0`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	camera := CreateSceneBuilder(desc, "").CreateCamera()
+
+	assert.Assert(t, desc.Camera.Fov == nil)
+	assert.Assert(t, floatEquals(camera.FieldOfView, gomath.Pi/3.0))
+}
+
+func TestValidateCameraFovAndUp(t *testing.T) {
+	fov := 180.0
+	camera := CameraModel{
+		From: &PointModel{Z: -5.0},
+		To:   &PointModel{},
+		Up:   &VectorModel{PointModel{Z: 2.0}},
+		Fov:  &fov,
+	}
+
+	errs := camera.validate()
+
+	assert.Assert(t, len(errs) == 2)
+	assert.Assert(t, strings.Contains(errs[0].Error(), "parallel"))
+	assert.Assert(t, strings.Contains(errs[1].Error(), "'fov'"))
+}
+
+func floatEquals(a float64, b float64) bool {
+	return gomath.Abs(a-b) < math.EPSILON
+}
+
+func TestParseCsg(t *testing.T) {
+	yml := `
+scene:
+  cubes:
+    - name: block
+  cylinders:
+    - name: hole
+      min: -2
+      max: 2
+  csg:
+    - name: drilled_block
+      operation: difference
+      left: block
+      right: hole`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	assert.Assert(t, desc != nil)
+	assert.Assert(t, len(desc.Scene.CSGs) == 1)
+	assert.Assert(t, desc.Scene.CSGs[0].Name == "drilled_block")
+	assert.Assert(t, desc.Scene.CSGs[0].Operation == "difference")
+	assert.Assert(t, desc.Scene.CSGs[0].Left == "block")
+	assert.Assert(t, desc.Scene.CSGs[0].Right == "hole")
+}
+
+func TestCreateWorldWithCsgInGroup(t *testing.T) {
+	yml := `
+scene:
+  spheres:
+    - name: s1
+    - name: s2
+  cubes:
+    - name: c1
+  groups:
+    - name: g1
+      children:
+        - inner
+        - c1
+  csg:
+    - name: outer
+      operation: union
+      left: g1
+      right: s2
+    - name: inner
+      operation: intersection
+      left: s1
+      right: p1
+  planes:
+    - name: p1
+camera:
+  to:
+    x: 0
+    y: 0
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: 0
+  intensity:
+    r: 255
+    g: 255
+    b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	builder := CreateSceneBuilder(desc, "")
+	errs := builder.ValidateReferences()
+	world, err := builder.CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, len(errs) == 0, "%v", errs)
+	assert.Assert(t, len(world.Objects) == 1)
+	outer := world.Objects[0].(*geometry.CSG)
+	assert.Assert(t, outer.Operation == geometry.UNION)
+	g1 := outer.Left.(*geometry.Group)
+	inner := g1.Children[0].(*geometry.CSG)
+	assert.Assert(t, inner.Operation == geometry.INTERSECTION)
+	assert.Assert(t, inner.GetParent() == g1)
+}
+
+func TestValidateCsg(t *testing.T) {
+	desc := YamlDescription{
+		Scene: SceneContainer{
+			CSGs: []CsgModel{
+				{CommonSceneObject: CommonSceneObject{Name: "c1"}, Operation: "xor", Left: "a", Right: "a"},
+			},
+		},
+	}
+
+	errs := desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "unknown operation 'xor'")
+	}))
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "cannot use 'a' as both operands")
+	}))
+}
+
+func TestValidateCsgReferences(t *testing.T) {
+	yml := `
+scene:
+  spheres:
+    - name: s1
+  groups:
+    - name: g1
+      children:
+        - s1
+        - c2
+  csg:
+    - name: c1
+      operation: union
+      left: s1
+      right: missing
+    - name: c2
+      operation: union
+      left: c3
+      right: g1
+    - name: c3
+      operation: union
+      left: c2
+      right: s1`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	errs := CreateSceneBuilder(desc, "").ValidateReferences()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return err.Error() == "cannot resolve operand 'missing' for csg 'c1'"
+	}))
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return err.Error() == "shape 's1' is used by both 'g1' and 'c1'"
+	}))
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "contains itself")
+	}))
+}
+
+func TestCreateCameraWithSampler(t *testing.T) {
+	yml := `
+width: 100
+height: 50
+render:
+  samples: 3
+  filter: gaussian
+  seed: 42
+camera:
+  from:
+    x: 0
+    y: 0
+    z: -5
+  to:
+    x: 0
+    y: 0
+    z: 0
+  up:
+    x: 0
+    y: 1
+    z: 0`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	camera := CreateSceneBuilder(desc, "").CreateCamera()
+
+	assert.Assert(t, camera.Sampler.Samples == 3)
+	assert.Assert(t, camera.Sampler.Filter == scene.GAUSSIAN_FILTER)
+	assert.Assert(t, camera.Sampler.Seed == 42)
+}
+
+func TestCreateCameraDefaultSampler(t *testing.T) {
+	desc := YamlDescription{
+		Width:  100,
+		Height: 50,
+		Camera: CameraModel{
+			From: &PointModel{X: 0.0, Y: 0.0, Z: -5.0},
+			To:   &PointModel{X: 0.0, Y: 0.0, Z: 0.0},
+			Up:   &VectorModel{PointModel{X: 0.0, Y: 1.0, Z: 0.0}},
+		},
+	}
+
+	camera := CreateSceneBuilder(&desc, "").CreateCamera()
+
+	assert.Assert(t, camera.Sampler == scene.DefaultSampler())
+}
+
+func TestValidateRender(t *testing.T) {
+	desc := YamlDescription{
+		Render: &RenderModel{Samples: -1, Filter: "mitchell"},
+	}
+
+	errs := desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "'samples' must not be negative")
+	}))
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "unknown filter 'mitchell'")
+	}))
+}
+
+func TestCreateAdaptiveSampler(t *testing.T) {
+	depth := 2
+	desc := YamlDescription{
+		Width:  100,
+		Height: 50,
+		Camera: CameraModel{
+			From: &PointModel{X: 0.0, Y: 0.0, Z: -5.0},
+			To:   &PointModel{X: 0.0, Y: 0.0, Z: 0.0},
+			Up:   &VectorModel{PointModel{X: 0.0, Y: 1.0, Z: 0.0}},
+		},
+		Render: &RenderModel{Adaptive: true, MaxDepth: &depth},
+	}
+
+	camera := CreateSceneBuilder(&desc, "").CreateCamera()
+
+	assert.Assert(t, camera.Sampler.Adaptive)
+	assert.Assert(t, camera.Sampler.Threshold == DEFAULT_AA_THRESHOLD)
+	assert.Assert(t, camera.Sampler.MaxDepth == 2)
+}
+
+func TestCreateCameraWithDepthOfField(t *testing.T) {
+	aperture := 0.4
+	desc := YamlDescription{
+		Width:  100,
+		Height: 50,
+		Camera: CameraModel{
+			From:     &PointModel{X: 0.0, Y: 3.0, Z: -4.0},
+			To:       &PointModel{X: 0.0, Y: 0.0, Z: 0.0},
+			Up:       &VectorModel{PointModel{X: 0.0, Y: 1.0, Z: 0.0}},
+			Aperture: &aperture,
+		},
+	}
+
+	camera := CreateSceneBuilder(&desc, "").CreateCamera()
+
+	assert.Assert(t, camera.Aperture == 0.4)
+	// defaults to the distance between from and to
+	assert.Assert(t, floatEquals(camera.FocalDistance, 5.0))
+
+	focalDistance := 8.0
+	desc.Camera.FocalDistance = &focalDistance
+	camera = CreateSceneBuilder(&desc, "").CreateCamera()
+
+	assert.Assert(t, camera.FocalDistance == 8.0)
+}
+
+func TestValidateCameraDepthOfField(t *testing.T) {
+	aperture := -1.0
+	focalDistance := 0.0
+	desc := YamlDescription{
+		Camera: CameraModel{Aperture: &aperture, FocalDistance: &focalDistance},
+	}
+
+	errs := desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "'aperture'(-1) must not be negative")
+	}))
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "'focalDistance'(0) must be greater than 0")
+	}))
+}
+
+func TestCreateCameraWithProjection(t *testing.T) {
+	viewWidth := 6.0
+	desc := YamlDescription{
+		Width:  100,
+		Height: 50,
+		Camera: CameraModel{
+			From:       &PointModel{X: 0.0, Y: 0.0, Z: -4.0},
+			To:         &PointModel{X: 0.0, Y: 0.0, Z: 0.0},
+			Up:         &VectorModel{PointModel{X: 0.0, Y: 1.0, Z: 0.0}},
+			Projection: "orthographic",
+			ViewWidth:  &viewWidth,
+		},
+	}
+
+	camera := CreateSceneBuilder(&desc, "").CreateCamera()
+
+	assert.Assert(t, camera.Projection == scene.ORTHOGRAPHIC)
+	assert.Assert(t, floatEquals(camera.HalfWidth, 3.0))
+	assert.Assert(t, floatEquals(camera.PixelSize, 0.06))
+
+	desc.Camera.Projection = "equirectangular"
+	camera = CreateSceneBuilder(&desc, "").CreateCamera()
+	assert.Assert(t, camera.Projection == scene.EQUIRECTANGULAR)
+
+	desc.Camera.Projection = ""
+	camera = CreateSceneBuilder(&desc, "").CreateCamera()
+	assert.Assert(t, camera.Projection == scene.PERSPECTIVE)
+}
+
+func TestValidateCameraProjection(t *testing.T) {
+	fov := 270.0
+	desc := YamlDescription{
+		Camera: CameraModel{Projection: "fisheye", Fov: &fov},
+	}
+
+	errs := desc.Validate()
+
+	assert.Assert(t, !slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "'fov'")
+	}))
+
+	viewWidth := -2.0
+	desc.Camera = CameraModel{Projection: "panorama", ViewWidth: &viewWidth}
+	errs = desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "unknown projection 'panorama'")
+	}))
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "'viewWidth'(-2) must be greater than 0")
+	}))
+
+	desc.Camera = CameraModel{Projection: "orthographic"}
+	errs = desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "orthographic camera requires a 'viewWidth'")
+	}))
+}
+
+func TestCreateWorldWithNamedTransform(t *testing.T) {
+	yml := `
+transforms:
+  - name: up
+    type: translation
+    x: 0
+    y: 2
+    z: 0
+scene:
+  spheres:
+    - name: s1
+      transform: up
+camera:
+  to:
+    x: 0
+    y: 0
+    z: 0`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	world, err := CreateSceneBuilder(desc, "").CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, len(world.Objects) == 1)
+	assert.Assert(t, world.Objects[0].GetTransform().Equals(math.Translation(0.0, 2.0, 0.0)))
+}
+
+func TestCreateWorldWithKeyframes(t *testing.T) {
+	yml := `
+animation:
+  timeSec: 1
+  fps: 5
+colors:
+  - name: red
+    r: 255
+scene:
+  spheres:
+    - name: ball
+      transforms:
+        - type: scaling
+          x: 0.5
+          y: 0.5
+          z: 0.5
+      keyframes:
+        - time: 0
+          translation: {x: 0, y: 2, z: 0}
+          rotation: {x: 0, y: 90, z: 0}
+          rawColor: {r: 0, g: 0, b: 255}
+        - time: 1
+          easing: easeIn
+          translation: {x: 0, y: 0, z: 0}
+          color: red
+          transparency: 0.5`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	world, err := CreateSceneBuilder(desc, "").CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, world.Animation.FrameCount() == 5)
+	assert.Assert(t, len(world.Animation.Objects) == 1)
+	keyframes := world.Animation.Objects[0].Keyframes
+	assert.Assert(t, keyframes[0].Rotation.Equals(math.CreateVector(0, gomath.Pi/2, 0)))
+	assert.Assert(t, keyframes[0].Color.Equals(math.CreateColor(0, 0, 1)))
+	assert.Assert(t, keyframes[1].Easing == scene.EASE_IN)
+	assert.Assert(t, keyframes[1].Color.Equals(math.CreateColor(1, 0, 0)))
+
+	// the world starts in the state of the first frame
+	ball := world.Objects[0]
+	start := math.Translation(0, 2, 0).MulM(math.Scaling(0.5, 0.5, 0.5)).MulM(math.Rotation_Y(gomath.Pi / 2))
+	assert.Assert(t, ball.GetTransform().Equals(start))
+	assert.Assert(t, ball.GetMaterial().Color.Equals(math.CreateColor(0, 0, 1)))
+
+	world.SetTime(0.5)
+	// eased in, a quarter of the way
+	middle := math.Translation(0, 1.5, 0).MulM(math.Scaling(0.5, 0.5, 0.5)).MulM(math.Rotation_Y(gomath.Pi / 2))
+	assert.Assert(t, ball.GetTransform().Equals(middle))
+	assert.Assert(t, ball.GetMaterial().Transparency == 0.5)
+}
+
+func TestCreateWorldWithKeyframesOnCameraTimeline(t *testing.T) {
+	yml := `
+scene:
+  spheres:
+    - name: ball
+      keyframes:
+        - time: 0
+          translation: {x: 0, y: 2, z: 0}
+camera:
+  animation:
+    degrees: 0
+    timeSec: 2
+    fps: 3`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	world, err := CreateSceneBuilder(desc, "").CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, world.Animation.Duration == 2.0)
+	assert.Assert(t, world.Animation.FrameCount() == 6)
+}
+
+func TestValidateKeyframes(t *testing.T) {
+	yml := `
+animation:
+  timeSec: 0
+  fps: 24
+scene:
+  spheres:
+    - name: ball
+      keyframes:
+        - time: 1
+          color: missing
+        - time: 0.5
+          easing: bounce
+        - easing: linear
+camera:
+  animation:
+    timeSec: 2
+    fps: 24`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	builder := CreateSceneBuilder(desc, "")
+	errs := append(desc.Validate(), builder.ValidateReferences()...)
+
+	expected := map[string]string{
+		"$.animation.timeSec":                    "the animation requires a 'timeSec' value of > 0.0",
+		"$.animation":                            "'animation' and the camera animation require the same 'timeSec' and 'fps'",
+		"$.scene.spheres[0].keyframes[0].color":  "cannot resolve color 'missing' for keyframe of scene object 'ball'",
+		"$.scene.spheres[0].keyframes[1].time":   "keyframes of scene object 'ball' have to be sorted by 'time'",
+		"$.scene.spheres[0].keyframes[1].easing": "keyframe has unknown easing 'bounce', expected linear, easeIn, easeOut or easeInOut",
+		"$.scene.spheres[0].keyframes[2].time":   "keyframes require a 'time'",
+	}
+	for path, message := range expected {
+		assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+			var fieldErr *FieldError
+			return errors.As(err, &fieldErr) && fieldErr.Path == path && err.Error() == message
+		}), "%v: %v not in %v", path, message, errs)
+	}
+
+	// keyframes need a timeline
+	desc.Animation = nil
+	desc.Camera.Animation = nil
+	assert.Assert(t, slices.ContainsFunc(desc.Validate(), func(err error) bool {
+		return err.Error() == "scene objects with keyframes require an 'animation' with 'timeSec' and 'fps'"
+	}))
+}
+
+func TestCreateCameraWithPath(t *testing.T) {
+	yml := `
+width: 10
+height: 10
+light:
+  p: {x: 0, y: 10, z: -10}
+  intensity: {r: 255, g: 255, b: 255}
+scene:
+  spheres:
+    - name: ball
+      transforms:
+        - type: translation
+          x: 1
+          y: 0
+          z: 0
+camera:
+  lookAt: ball
+  up: {x: 0, y: 1, z: 0}
+  fov: 60
+  animation:
+    timeSec: 1
+    fps: 4
+    path:
+      spline: bezier
+      from:
+        - {x: 0, y: 0, z: -5}
+        - {x: 1, y: 0, z: -5}
+        - {x: 2, y: 0, z: -5}
+        - {x: 3, y: 0, z: -5}
+      fov: [60, 30]`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	assert.Assert(t, len(desc.Validate()) == 0, "%v", desc.Validate())
+	builder := CreateSceneBuilder(desc, "")
+	assert.Assert(t, len(builder.ValidateReferences()) == 0)
+	_, err = builder.CreateWorld()
+	assert.NilError(t, err)
+
+	camera := builder.CreateCamera()
+
+	// the camera starts at the first control point and tracks the object
+	assert.Assert(t, camera.Position.From.Equals(math.CreatePoint(0, 0, -5)))
+	assert.Assert(t, camera.Position.To.Equals(math.CreatePoint(1, 0, 0)))
+	assert.Assert(t, camera.Tracking != nil)
+	path := camera.Animation.Path
+	assert.Assert(t, path.Spline == scene.BEZIER)
+	assert.Assert(t, len(path.From) == 4 && len(path.To) == 0)
+	assert.Assert(t, floatEquals(path.FieldOfView[1], gomath.Pi/6))
+}
+
+func TestValidateCameraPath(t *testing.T) {
+	yml := `
+camera:
+  lookAt: ball
+  animation:
+    degrees: 90
+    timeSec: 1
+    fps: 4
+    path:
+      spline: hermite
+      to:
+        - {x: 0, y: 0, z: 0}
+      fov: [60, 200]`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	errs := desc.Validate()
+
+	expected := map[string]string{
+		"$.camera.from":                  "camera requires a 'from' position",
+		"$.camera.lookAt":                "camera cannot follow a 'to' path and look at 'ball' at the same time",
+		"$.camera.animation.degrees":     "the camera animation cannot combine 'degrees' with a 'path'",
+		"$.camera.animation.path.spline": "camera path has unknown spline 'hermite', expected catmullRom or bezier",
+		"$.camera.animation.path.fov[1]": "camera 'fov'(200) must be between 0 and 180 degrees",
+	}
+	for path, message := range expected {
+		assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+			var fieldErr *FieldError
+			return errors.As(err, &fieldErr) && fieldErr.Path == path && err.Error() == message
+		}), "%v: %v not in %v", path, message, errs)
+	}
+	// the path replaces the fixed target
+	assert.Assert(t, !slices.ContainsFunc(errs, func(err error) bool {
+		return strings.Contains(err.Error(), "valid 'to' or 'lookAt'")
+	}))
+
+	desc.Camera.Animation.Path = &CameraPathModel{
+		Spline: "bezier",
+		From:   []PointModel{{}, {}, {}},
+	}
+	errs = desc.Validate()
+
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return err.Error() == "bezier path requires 3n+1 'from' points, got 3"
+	}))
+	assert.Assert(t, !slices.ContainsFunc(errs, func(err error) bool {
+		return err.Error() == "camera requires a 'from' position"
+	}))
+
+	desc.Camera.Animation.Path = &CameraPathModel{}
+	assert.Assert(t, slices.ContainsFunc(desc.Validate(), func(err error) bool {
+		return err.Error() == "camera path requires control points for 'from' or 'to', or 'fov' values"
+	}))
+}
+
+func TestCreateWorldWithMotionBlur(t *testing.T) {
+	yml := `
+scene:
+  groups:
+    - name: g
+      children: [ball]
+  spheres:
+    - name: ball
+      transforms:
+        - type: translation
+          x: -1
+          y: 0
+          z: 0
+      endTransforms:
+        - type: translation
+          x: 1
+          y: 0
+          z: 0
+camera:
+  from: {x: 0, y: 0, z: -5}
+  to: {x: 0, y: 0, z: 0}
+  up: {x: 0, y: 1, z: 0}
+  shutter: 0.5`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	builder := CreateSceneBuilder(desc, "")
+	world, err := builder.CreateWorld()
+	assert.NilError(t, err)
+	camera := builder.CreateCamera()
+
+	assert.Assert(t, camera.Shutter == 0.5)
+	ball := world.Objects[0].(*geometry.Group).Children[0]
+	assert.Assert(t, ball.GetTransform().Equals(math.Translation(-1, 0, 0)))
+	assert.Assert(t, ball.GetMotion().End.Equals(math.Translation(1, 0, 0)))
+	// the group contains the whole motion
+	assert.Assert(t, world.Objects[0].Bounds().Maximum.Equals(math.CreatePoint(2, 1, 1)))
+}
+
+func TestValidateMotionBlur(t *testing.T) {
+	yml := `
+scene:
+  spheres:
+    - name: ball
+      endTransforms:
+        - type: bend
+      keyframes:
+        - time: 0
+          translation: {x: 0, y: 1, z: 0}
+camera:
+  shutter: 2`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	errs := desc.Validate()
+
+	expected := map[string]string{
+		"$.camera.shutter":                         "camera 'shutter'(2) must be between 0 and 1",
+		"$.scene.spheres[0].endTransforms":         "scene object 'ball' cannot combine 'endTransforms' with keyframes that move it",
+		"$.scene.spheres[0].endTransforms[0].type": "transform has unknown type 'bend', expected scaling, translation, shearing or rotation",
+	}
+	for path, message := range expected {
+		assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+			var fieldErr *FieldError
+			return errors.As(err, &fieldErr) && fieldErr.Path == path && err.Error() == message
+		}), "%v: %v not in %v", path, message, errs)
+	}
+}
+
+func TestCreateWorldWithTorus(t *testing.T) {
+	yml := `
+scene:
+  tori:
+    - name: ring
+      majorRadius: 2
+      minorRadius: 0.5
+    - name: default_ring
+camera:
+  to:
+    x: 0
+    y: 0
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: 0
+  intensity:
+    r: 255
+    g: 255
+    b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	builder := CreateSceneBuilder(desc, "")
+	errs := builder.ValidateReferences()
+	world, err := builder.CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, len(errs) == 0, "%v", errs)
+	assert.Assert(t, len(world.Objects) == 2)
+	for _, o := range world.Objects {
+		torus := o.(*geometry.Torus)
+		if torus.MajorRadius == 2.0 {
+			assert.Assert(t, torus.MinorRadius == 0.5)
+		} else {
+			assert.Assert(t, torus.MajorRadius == 1.0 && torus.MinorRadius == 0.25)
+		}
+	}
+}
+
+func TestValidateTorus(t *testing.T) {
+	yml := `
+scene:
+  tori:
+    - name: flat
+      majorRadius: 0
+    - name: closed
+      majorRadius: 1
+      minorRadius: 1`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	errs := desc.Validate()
+
+	expected := map[string]string{
+		"$.scene.tori[0].majorRadius": "majorRadius(0) of torus 'flat' must be greater than 0",
+		"$.scene.tori[1].minorRadius": "minorRadius(1) of torus 'closed' must be smaller than its majorRadius(1)",
+	}
+	for path, message := range expected {
+		assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+			var fieldErr *FieldError
+			return errors.As(err, &fieldErr) && fieldErr.Path == path && err.Error() == message
+		}), "%v: %v not in %v", path, message, errs)
+	}
+}
+
+func TestCreateWorldWithDiskAndQuad(t *testing.T) {
+	yml := `
+scene:
+  disks:
+    - name: shade
+      radius: 2
+      innerRadius: 0.5
+  quads:
+    - name: panel
+      transforms:
+        - type: scaling
+          x: 2
+          y: 1
+          z: 0.5
+camera:
+  to:
+    x: 0
+    y: 0
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: 0
+  intensity:
+    r: 255
+    g: 255
+    b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	builder := CreateSceneBuilder(desc, "")
+	errs := builder.ValidateReferences()
+	world, err := builder.CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, len(errs) == 0, "%v", errs)
+	assert.Assert(t, len(world.Objects) == 2)
+	for _, o := range world.Objects {
+		switch shape := o.(type) {
+		case *geometry.Disk:
+			assert.Assert(t, shape.Radius == 2.0 && shape.InnerRadius == 0.5)
+		case *geometry.Quad:
+			assert.Assert(t, shape.Transform.Equals(math.Scaling(2, 1, 0.5)))
+		default:
+			t.Fatalf("unexpected shape %T", o)
+		}
+	}
+}
+
+func TestValidateDisk(t *testing.T) {
+	yml := `
+scene:
+  disks:
+    - name: nothing
+      radius: -1
+    - name: hole
+      innerRadius: 1`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	errs := desc.Validate()
+
+	expected := map[string]string{
+		"$.scene.disks[0].radius":      "radius(-1) of disk 'nothing' must be greater than 0",
+		"$.scene.disks[1].innerRadius": "innerRadius(1) of disk 'hole' must be smaller than its radius(1)",
+	}
+	for path, message := range expected {
+		assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+			var fieldErr *FieldError
+			return errors.As(err, &fieldErr) && fieldErr.Path == path && err.Error() == message
+		}), "%v: %v not in %v", path, message, errs)
+	}
+}
+
+func TestCreateWorldWithSdf(t *testing.T) {
+	yml := `
+scene:
+  sdfs:
+    - name: blob
+      shape:
+        subtraction:
+          smoothness: 0.1
+          shapes:
+            - union:
+                smoothness: 0.5
+                shapes:
+                  - sphere:
+                      radius: 1
+                  - capsule:
+                      a: {x: 0, y: 0, z: 0}
+                      b: {x: 0, y: 2, z: 0}
+                      radius: 0.3
+                  - twist:
+                      angle: 45
+                      shape:
+                        box:
+                          size: {x: 2, y: 1, z: 0.2}
+            - repeat:
+                spacing: {x: 0.5, y: 0, z: 0}
+                count: {x: 3}
+                shape:
+                  torus:
+                    center: {x: 0, y: 1, z: 0}
+camera:
+  to:
+    x: 0
+    y: 0
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: 0
+  intensity:
+    r: 255
+    g: 255
+    b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	builder := CreateSceneBuilder(desc, "")
+	errs := builder.ValidateReferences()
+	world, err := builder.CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, len(errs) == 0, "%v", errs)
+	assert.Assert(t, len(world.Objects) == 1)
+	sdf := world.Objects[0].(*geometry.SDF)
+	subtraction := sdf.Root.(*geometry.SdfCombination)
+	assert.Assert(t, subtraction.Operation == geometry.SDF_SUBTRACTION)
+	assert.Assert(t, subtraction.Smoothness == 0.1)
+	// the three shapes of the union are folded from left to right
+	union := subtraction.Left.(*geometry.SdfCombination)
+	assert.Assert(t, union.Operation == geometry.SDF_UNION)
+	twist := union.Right.(*geometry.SdfTwist)
+	assert.Assert(t, floatEquals(twist.Rate, gomath.Pi/4))
+	assert.Assert(t, twist.Child.(*geometry.SdfBox).HalfSize.Equals(math.CreateVector(1, 0.5, 0.1)))
+	inner := union.Left.(*geometry.SdfCombination)
+	assert.Assert(t, inner.Left.(*geometry.SdfSphere).Radius == 1.0)
+	assert.Assert(t, inner.Right.(*geometry.SdfCapsule).B.Equals(math.CreatePoint(0, 2, 0)))
+	repeat := subtraction.Right.(*geometry.SdfRepetition)
+	assert.Assert(t, repeat.Count == [3]int{3, 0, 0})
+	torus := repeat.Child.(*geometry.SdfTorus)
+	assert.Assert(t, torus.MajorRadius == 1.0 && torus.MinorRadius == 0.25)
+	assert.Assert(t, torus.Center.Equals(math.CreatePoint(0, 1, 0)))
+}
+
+func TestValidateSdf(t *testing.T) {
+	yml := `
+scene:
+  sdfs:
+    - name: nothing
+    - name: broken
+      shape:
+        union:
+          smoothness: -1
+          shapes:
+            - sphere:
+                radius: 0
+              box:
+                size: {x: 1, y: 1, z: 1}
+            - {}
+            - twist:
+                shape:
+                  capsule:
+                    radius: 1
+        repeat:
+          spacing: {x: -1, y: 0, z: 0}`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	errs := desc.Validate()
+
+	expected := map[string]string{
+		"$.scene.sdfs[0]":                                           "sdf 'nothing' requires a 'shape'",
+		"$.scene.sdfs[1].shape":                                     "sdf 'broken' has a node with more than one shape: union, repeat",
+		"$.scene.sdfs[1].shape.union.smoothness":                    "smoothness(-1) of sdf 'broken' must not be negative",
+		"$.scene.sdfs[1].shape.union.shapes[0]":                     "sdf 'broken' has a node with more than one shape: sphere, box",
+		"$.scene.sdfs[1].shape.union.shapes[0].sphere.radius":       "sphere radius(0) of sdf 'broken' must be greater than 0",
+		"$.scene.sdfs[1].shape.union.shapes[1]":                     "sdf 'broken' has a node without a shape, expected sphere, box, torus, capsule, union, subtraction, intersection, repeat or twist",
+		"$.scene.sdfs[1].shape.union.shapes[2].twist":               "twist of sdf 'broken' requires an 'angle'",
+		"$.scene.sdfs[1].shape.union.shapes[2].twist.shape.capsule": "capsule of sdf 'broken' requires the end points 'a' and 'b'",
+		"$.scene.sdfs[1].shape.repeat":                              "repeat of sdf 'broken' requires a 'shape'",
+		"$.scene.sdfs[1].shape.repeat.spacing":                      "repeat spacing(-1, 0, 0) of sdf 'broken' must not be negative",
+	}
+	for path, message := range expected {
+		assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+			var fieldErr *FieldError
+			return errors.As(err, &fieldErr) && fieldErr.Path == path && err.Error() == message
+		}), "%v: %v not in %v", path, message, errs)
+	}
+}
+
+func TestRenderTexturedSdf(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewGray(image.Rect(0, 0, 2, 2))
+	img.SetGray(1, 0, color.Gray{Y: 255})
+	f, err := os.Create(filepath.Join(dir, "texture.png"))
+	assert.NilError(t, err)
+	assert.NilError(t, png.Encode(f, img))
+	assert.NilError(t, f.Close())
+
+	yml := `
+width: 20
+height: 20
+materials:
+  - name: textured
+    texture:
+      file: texture.png
+scene:
+  sdfs:
+    - name: ball
+      material: textured
+      shape:
+        sphere:
+          radius: 1
+camera:
+  from:
+    x: 0
+    y: 0
+    z: -5
+  to:
+    x: 0
+    y: 0
+    z: 0
+  up:
+    x: 0
+    y: 1
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: -10
+  intensity:
+    r: 255
+    g: 255
+    b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	assert.Assert(t, len(desc.Validate()) == 0)
+	builder := CreateSceneBuilder(desc, dir+"/")
+	assert.Assert(t, len(builder.ValidateReferences()) == 0)
+	world, err := builder.CreateWorld()
+	assert.NilError(t, err)
+
+	canv := builder.CreateCamera().RenderSinglethreaded(world)
+	assert.Assert(t, canv.GetPixelAt(10, 10) != math.CreateColor(0.0, 0.0, 0.0))
+}
+
+func TestCreateWorldWithHeightfield(t *testing.T) {
+	dir := t.TempDir()
+	img := image.NewGray(image.Rect(0, 0, 3, 2))
+	img.SetGray(1, 0, color.Gray{Y: 255})
+	f, err := os.Create(filepath.Join(dir, "terrain.png"))
+	assert.NilError(t, err)
+	assert.NilError(t, png.Encode(f, img))
+	assert.NilError(t, f.Close())
+
+	yml := `
+scene:
+  heightfields:
+    - name: hills
+      file: terrain.png
+      scale: {x: 100, y: 10, z: 50}
+    - name: missing
+      file: missing.png`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	_, err = CreateSceneBuilder(desc, dir+"/").CreateWorld()
+	var loadErr *LoadError
+	var heightfieldErr *geometry.HeightfieldError
+	assert.Assert(t, errors.As(err, &loadErr))
+	assert.Assert(t, loadErr.Name == "missing")
+	assert.Assert(t, errors.As(err, &heightfieldErr))
+	assert.Assert(t, errors.Is(err, fs.ErrNotExist))
+
+	desc.Scene.Heightfields = desc.Scene.Heightfields[:1]
+	world, err := CreateSceneBuilder(desc, dir+"/").CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, len(world.Objects) == 1)
+	heightfield := world.Objects[0].(*geometry.Heightfield)
+	assert.Assert(t, heightfield.Columns == 3 && heightfield.Rows == 2)
+	assert.Assert(t, heightfield.Scale.Equals(math.CreateVector(100, 10, 50)))
+	assert.Assert(t, heightfield.Bounds().Maximum.Equals(math.CreatePoint(50, 10, 25)))
+}
+
+func TestValidateHeightfield(t *testing.T) {
+	yml := `
+scene:
+  heightfields:
+    - name: flat
+      scale: {x: 1, y: 0, z: 1}`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	errs := desc.Validate()
+
+	expected := map[string]string{
+		"$.scene.heightfields[0].file":  "heightfield 'flat' requires a 'file' field from which to load the heights",
+		"$.scene.heightfields[0].scale": "scale(1, 0, 1) of heightfield 'flat' must be greater than 0",
+	}
+	for path, message := range expected {
+		assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+			var fieldErr *FieldError
+			return errors.As(err, &fieldErr) && fieldErr.Path == path && err.Error() == message
+		}), "%v: %v not in %v", path, message, errs)
+	}
+}
+
+func TestCreateWorldWithObjectInstances(t *testing.T) {
+	dir := t.TempDir()
+	triangle := "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "triangle.obj"), []byte(triangle), 0o644))
+
+	yml := `
+colors:
+  - name: red
+    r: 255
+    g: 0
+    b: 0
+materials:
+  - name: red_mat
+    color: red
+scene:
+  objects:
+    - name: plain
+      file: triangle.obj
+    - name: red
+      file: triangle.obj
+      material: red_mat
+      transform: moved
+transforms:
+  - name: moved
+    type: translation
+    x: 5
+    y: 0
+    z: 0
+camera:
+  to:
+    x: 0
+    y: 0
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: 0
+  intensity:
+    r: 255
+    g: 255
+    b: 255`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	builder := CreateSceneBuilder(desc, dir+"/")
+	errs := builder.ValidateReferences()
+	world, err := builder.CreateWorld()
+	assert.NilError(t, err)
+
+	assert.Assert(t, len(errs) == 0, "%v", errs)
+	assert.Assert(t, len(world.Objects) == 2)
+	plain := builder.raygoShapes["plain"].(*geometry.Instance)
+	red := builder.raygoShapes["red"].(*geometry.Instance)
+	// the file is parsed once and shared
+	assert.Assert(t, len(builder.objPrototypes) == 1)
+	assert.Assert(t, plain.Prototype == red.Prototype)
+	assert.Assert(t, red.Transform.Equals(math.Translation(5, 0, 0)))
+
+	ray := geometry.CreateRay(math.CreatePoint(5.25, 0.25, -1), math.CreateVector(0, 0, 1))
+	xs := red.Intersect(ray)
+	assert.Assert(t, len(xs) == 1)
+	assert.Assert(t, xs[0].Object.GetMaterial().Color.Equals(math.CreateColor(1, 0, 0)))
+	xs = plain.Intersect(geometry.CreateRay(math.CreatePoint(0.25, 0.25, -1), math.CreateVector(0, 0, 1)))
+	assert.Assert(t, len(xs) == 1)
+	assert.Assert(t, !xs[0].Object.GetMaterial().Color.Equals(math.CreateColor(1, 0, 0)))
+}
+
+func TestCreateWorldWithObjectMaterials(t *testing.T) {
+	dir := t.TempDir()
+	mtl := "newmtl paint\nKd 0 1 0\nnewmtl chrome\nKs 1 1 1\nillum 3\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "car.mtl"), []byte(mtl), 0o644))
+	car := "mtllib car.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl paint\nf 1 2 3\nusemtl chrome\nf 3 2 1\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "car.obj"), []byte(car), 0o644))
+
+	yml := `
+colors:
+  - name: red
+    r: 255
+    g: 0
+    b: 0
+materials:
+  - name: red_paint
+    color: red
+scene:
+  objects:
+    - name: car
+      file: car.obj
+      materials:
+        paint: red_paint
+    - name: wrong
+      file: car.obj
+      materials:
+        seats: red_paint`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	_, err = CreateSceneBuilder(desc, dir+"/").CreateWorld()
+	var loadErr *LoadError
+	assert.Assert(t, errors.As(err, &loadErr))
+	assert.Assert(t, loadErr.Name == "wrong")
+	assert.ErrorContains(t, err, "'car.obj' has no material 'seats'")
+
+	desc.Scene.Objects = desc.Scene.Objects[:1]
+	builder := CreateSceneBuilder(desc, dir+"/")
+	_, err = builder.CreateWorld()
+	assert.NilError(t, err)
+
+	instance := builder.raygoShapes["car"].(*geometry.Instance)
+	front := instance.Intersect(geometry.CreateRay(math.CreatePoint(0.25, 0.25, -1), math.CreateVector(0, 0, 1)))
+	assert.Assert(t, len(front) == 2)
+	for _, x := range front {
+		m := x.Object.GetMaterial()
+		if m.Name == "chrome" {
+			assert.Assert(t, m.Reflective == 1.0)
+		} else {
+			assert.Assert(t, m.Color.Equals(math.CreateColor(1, 0, 0)))
+		}
+	}
+	// the prototype keeps the material of the mtl file for other instances
+	paint := builder.objPrototypes[dir+"/car.obj"].Children[0].GetMaterial()
+	assert.Assert(t, paint.Color.Equals(math.CreateColor(0, 1, 0)))
+}
+
+func TestAnimateObjectWithMtlMaterials(t *testing.T) {
+	dir := t.TempDir()
+	mtl := "newmtl paint\nKd 0 1 0\nnewmtl glass\nKd 0 0 1\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "car.mtl"), []byte(mtl), 0o644))
+	car := "mtllib car.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nv 0 0 1\nusemtl paint\nf 1 2 3\nusemtl glass\nf 1 2 4\n"
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "car.obj"), []byte(car), 0o644))
+
+	yml := `
+animation:
+  timeSec: 1
+  fps: 5
+scene:
+  objects:
+    - name: car
+      file: car.obj
+      keyframes:
+        - time: 0
+          transparency: 0
+        - time: 1
+          transparency: 0.8`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	world, err := CreateSceneBuilder(desc, dir+"/").CreateWorld()
+	assert.NilError(t, err)
+	world.SetTime(0.5)
+
+	instance := world.Objects[0].(*geometry.Instance)
+	paint := instance.Intersect(geometry.CreateRay(math.CreatePoint(0.25, 0.25, -1), math.CreateVector(0, 0, 1)))
+	glass := instance.Intersect(geometry.CreateRay(math.CreatePoint(0.25, -1, 0.25), math.CreateVector(0, 1, 0)))
+	assert.Assert(t, len(paint) == 1 && len(glass) == 1)
+	for _, tc := range []struct {
+		hit   geometry.Intersection
+		color math.Color
+	}{
+		{paint[0], math.CreateColor(0, 1, 0)},
+		{glass[0], math.CreateColor(0, 0, 1)},
+	} {
+		m := tc.hit.Object.GetMaterial()
+		assert.Assert(t, m.Color.Equals(tc.color), "%v", m.Color)
+		assert.Assert(t, floatEquals(m.Transparency, 0.4), "%v", m.Transparency)
+	}
+}
+
+func TestValidateObjectMaterials(t *testing.T) {
+	yml := `
+scene:
+  objects:
+    - name: car
+      file: car.obj
+      materials:
+        paint: missing_paint`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+	errs := CreateSceneBuilder(desc, "").ValidateReferences()
+
+	expected := map[string]string{
+		"$.scene.objects[0].materials.paint": "cannot resolve material 'missing_paint' for 'paint' of scene object 'car'",
+	}
+	for path, message := range expected {
+		assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+			var fieldErr *FieldError
+			return errors.As(err, &fieldErr) && fieldErr.Path == path && err.Error() == message
+		}), "%v: %v not in %v", path, message, errs)
+	}
+}
+
+func TestParseScenesConcurrently(t *testing.T) {
+	sceneTemplate := `
+width: 20
+height: 10
+scene:
+  spheres:
+%v
+  groups:
+    - name: g1
+      children:
+        - s0
+        - s1
+camera:
+  from:
+    x: 0
+    y: 0
+    z: -5
+  lookAt: g1
+  up:
+    x: 0
+    y: 1
+    z: 0
+light:
+  p:
+    x: 0
+    y: 10
+    z: 0
+  intensity:
+    r: 255
+    g: 255
+    b: 255`
+
+	// every scene has a different number of spheres, state leaking
+	// between the builders would show up as wrong object counts
+	const scenes = 8
+	worlds := make([]*scene.World, scenes)
+	var wg sync.WaitGroup
+	for i := range scenes {
+		wg.Go(func() {
+			spheres := ""
+			for j := range i + 2 {
+				spheres += fmt.Sprintf("    - name: s%v\n", j)
+			}
+			desc, err := ParseYaml(fmt.Sprintf(sceneTemplate, spheres))
+			if err != nil {
+				t.Errorf("scene %v: %v", i, err)
+				return
+			}
+			builder := CreateSceneBuilder(desc, "")
+			if err := builder.Validate(); err != nil {
+				t.Errorf("scene %v: %v", i, err)
+				return
+			}
+			world, err := builder.CreateWorld()
+			if err != nil {
+				t.Errorf("scene %v: %v", i, err)
+				return
+			}
+			worlds[i] = world
+			builder.CreateCamera()
+		})
+	}
+	wg.Wait()
+
+	for i, world := range worlds {
+		assert.Assert(t, world != nil)
+		// the group and every sphere that is not part of it
+		assert.Assert(t, len(world.Objects) == i+1, "scene %v has %v objects", i, len(world.Objects))
+	}
+}
+
+func TestParseYamlSyntaxError(t *testing.T) {
+	_, err := ParseYaml("scene: [spheres")
+
+	var syntaxErr *SyntaxError
+	assert.Assert(t, errors.As(err, &syntaxErr))
+}
+
+func TestValidateReturnsValidationError(t *testing.T) {
+	yml := `
+scene:
+  spheres:
+    - name: s1
+      material: missing
+      transforms:
+        - type: stretch`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	err = CreateSceneBuilder(desc, "").Validate()
+
+	var validationErr *ValidationError
+	assert.Assert(t, errors.As(err, &validationErr))
+	assert.Assert(t, slices.ContainsFunc(validationErr.Errors, func(err error) bool {
+		return err.Error() == "5:17: cannot resolve material 'missing' for scene object 's1'"
+	}))
+	assert.Assert(t, slices.ContainsFunc(validationErr.Errors, func(err error) bool {
+		return strings.Contains(err.Error(), "unknown type 'stretch'")
+	}))
+}
+
+func TestValidationErrorPositions(t *testing.T) {
+	yml := `width: 10
+height: 10
+materials:
+  - name: red
+scene:
+  spheres:
+    - name: s1
+      material: foo
+  groups:
+    - name: g1
+camera:
+  to:
+    x: 0
+    y: 0
+    z: 0
+  up:
+    x: 0
+    y: 1
+    z: 0
+light:
+  p:
+    x: 0
+    y: 0
+    z: 0`
+	path := filepath.Join(t.TempDir(), "scene.yaml")
+	assert.NilError(t, os.WriteFile(path, []byte(yml), 0644))
+
+	desc, err := ParseYamlFile(path)
+	assert.NilError(t, err)
+	err = CreateSceneBuilder(desc, "").Validate()
+
+	var validationErr *ValidationError
+	assert.Assert(t, errors.As(err, &validationErr))
+	messages := make([]string, 0)
+	for _, err := range validationErr.Errors {
+		messages = append(messages, err.Error())
+	}
+	// value of a field
+	assert.Assert(t, slices.Contains(messages, path+":8:17: cannot resolve material 'foo' for scene object 's1'"), "%v", messages)
+	// missing fields point at their parent
+	assert.Assert(t, slices.Contains(messages, path+":10:7: group 'g1' requires children"), "%v", messages)
+	assert.Assert(t, slices.Contains(messages, path+":11:1: camera requires a 'from' position"), "%v", messages)
+	assert.Assert(t, slices.Contains(messages, path+":20:1: light requires an 'intensity' field for its color"), "%v", messages)
+
+	formatted := FormatError(err)
+	assert.Assert(t, strings.Contains(formatted, ">  8 |       material: foo\n                       ^"), formatted)
+}
+
+func TestSyntaxErrorPosition(t *testing.T) {
+	_, err := ParseYaml("width: 10\nheight: abc\n")
+
+	var syntaxErr *SyntaxError
+	assert.Assert(t, errors.As(err, &syntaxErr))
+	assert.Assert(t, syntaxErr.Line == 2, "%v", err)
+	assert.Assert(t, syntaxErr.Column == 9, "%v", err)
+	assert.Assert(t, strings.HasPrefix(err.Error(), "2:9: could not unmarshal yaml"), "%v", err)
+	assert.Assert(t, strings.Contains(FormatError(err), "^"))
+}
+
+func TestParentPath(t *testing.T) {
+	assert.Assert(t, parentPath("$.scene.spheres[2].material") == "$.scene.spheres[2]")
+	assert.Assert(t, parentPath("$.scene.spheres[2]") == "$.scene.spheres")
+	assert.Assert(t, parentPath("$.scene") == "$")
+	assert.Assert(t, parentPath("$") == "$")
+}
+
+func TestCreateWorldWithMissingFiles(t *testing.T) {
+	yml := `
+materials:
+  - name: wood
+    texture:
+      file: missing.png
+scene:
+  spheres:
+    - name: s1
+      material: wood
+  objects:
+    - name: teapot
+      file: missing.obj`
+
+	desc, err := ParseYaml(yml)
+	assert.NilError(t, err)
+
+	_, err = CreateSceneBuilder(desc, t.TempDir()+"/").CreateWorld()
+
+	var loadErr *LoadError
+	var textureErr *geometry.TextureError
+	assert.Assert(t, errors.As(err, &loadErr))
+	assert.Assert(t, loadErr.Name == "wood")
+	assert.Assert(t, errors.As(err, &textureErr))
+	assert.Assert(t, errors.Is(err, fs.ErrNotExist))
+
+	// without the texture the obj file is the first thing that fails
+	desc.Materials = nil
+	desc.Scene.Spheres[0].Material = ""
+	_, err = CreateSceneBuilder(desc, t.TempDir()+"/").CreateWorld()
+
+	assert.Assert(t, errors.As(err, &loadErr))
+	assert.Assert(t, loadErr.Name == "teapot")
+	assert.Assert(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func writeSceneFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestParseYamlFileWithIncludes(t *testing.T) {
+	dir := writeSceneFiles(t, map[string]string{
+		"scene.yaml": `include:
+  - lib/materials.yaml
+  - lib/colors.yaml
+width: 10
+height: 10
+materials:
+  - name: blue
+scene:
+  spheres:
+    - name: s1
+      material: wood
+camera:
+  from: {x: 0, y: 0, z: -5}
+  to: {x: 0, y: 0, z: 0}
+  up: {x: 0, y: 1, z: 0}
+light:
+  p: {x: 0, y: 0, z: 0}
+  intensity: {r: 255, g: 255, b: 255}`,
+		// relative to the including file
+		"lib/materials.yaml": `include:
+  - colors.yaml
+materials:
+  - name: wood
+    texture:
+      file: textures/wood.png
+scene:
+  objects:
+    - name: teapot
+      file: teapot.obj
+  heightfields:
+    - name: hills
+      file: terrain.png`,
+		// included twice, but only merged once
+		"lib/colors.yaml": `colors:
+  - name: red
+    r: 255`,
+	})
+
+	desc, err := ParseYamlFile(filepath.Join(dir, "scene.yaml"))
+	assert.NilError(t, err)
+	assert.NilError(t, CreateSceneBuilder(desc, dir+"/").Validate())
+
+	assert.Assert(t, len(desc.Colors) == 1)
+	assert.Assert(t, desc.Colors[0].Name == "red")
+	assert.Assert(t, len(desc.Materials) == 2)
+	assert.Assert(t, desc.Materials[0].Name == "blue")
+	assert.Assert(t, desc.Materials[1].Name == "wood")
+	// files of included elements are relative to the scene afterwards
+	assert.Assert(t, desc.Materials[1].Texture.File == filepath.Join("lib", "textures", "wood.png"))
+	assert.Assert(t, desc.Scene.Objects[0].File == filepath.Join("lib", "teapot.obj"))
+	assert.Assert(t, desc.Scene.Heightfields[0].File == filepath.Join("lib", "terrain.png"))
+}
+
+func TestParseYamlFileWithIncludeCycle(t *testing.T) {
+	dir := writeSceneFiles(t, map[string]string{
+		"scene.yaml": "include:\n  - a.yaml\nwidth: 10\n",
+		"a.yaml":     "include:\n  - b.yaml\n",
+		"b.yaml":     "include:\n  - scene.yaml\n",
+	})
+
+	_, err := ParseYamlFile(filepath.Join(dir, "scene.yaml"))
+
+	var validationErr *ValidationError
+	assert.Assert(t, errors.As(err, &validationErr))
+	cycle := strings.Join([]string{
+		filepath.Join(dir, "scene.yaml"), filepath.Join(dir, "a.yaml"),
+		filepath.Join(dir, "b.yaml"), filepath.Join(dir, "scene.yaml"),
+	}, " -> ")
+	assert.Assert(t, strings.HasSuffix(err.Error(), filepath.Join(dir, "b.yaml")+":2:5: include cycle "+cycle), "%v", err)
+}
+
+func TestParseYamlFileWithMissingInclude(t *testing.T) {
+	dir := writeSceneFiles(t, map[string]string{
+		"scene.yaml": "width: 10\ninclude:\n  - missing.yaml\n",
+	})
+	path := filepath.Join(dir, "scene.yaml")
+
+	_, err := ParseYamlFile(path)
+
+	assert.Assert(t, errors.Is(err, fs.ErrNotExist))
+	assert.Assert(t, strings.HasPrefix(err.Error(), path+":3:5: cannot include 'missing.yaml'"), "%v", err)
+}
+
+func TestParseYamlFileWithIncludeSyntaxError(t *testing.T) {
+	dir := writeSceneFiles(t, map[string]string{
+		"scene.yaml": "include:\n  - lib.yaml\n",
+		"lib.yaml":   "width: abc\n",
+	})
+
+	_, err := ParseYamlFile(filepath.Join(dir, "scene.yaml"))
+
+	var syntaxErr *SyntaxError
+	assert.Assert(t, errors.As(err, &syntaxErr))
+	assert.Assert(t, syntaxErr.File == filepath.Join(dir, "lib.yaml"), "%v", err)
+}
+
+func TestValidateDuplicateNamesInIncludes(t *testing.T) {
+	dir := writeSceneFiles(t, map[string]string{
+		"scene.yaml": `include:
+  - lib.yaml
+materials:
+  - name: red
+  - name: blue
+scene:
+  spheres:
+    - name: ball
+      material: bogus`,
+		"lib.yaml": `
+materials:
+  - name: red
+scene:
+  cubes:
+    - name: ball`,
+	})
+	scene := filepath.Join(dir, "scene.yaml")
+	lib := filepath.Join(dir, "lib.yaml")
+
+	desc, err := ParseYamlFile(scene)
+	assert.NilError(t, err)
+	err = CreateSceneBuilder(desc, dir+"/").Validate()
+
+	var validationErr *ValidationError
+	assert.Assert(t, errors.As(err, &validationErr))
+	messages := make([]string, 0)
+	for _, err := range validationErr.Errors {
+		messages = append(messages, err.Error())
+	}
+	assert.Assert(t, slices.Contains(messages,
+		lib+":3:11: material 'red' is defined more than once, first definition at "+scene+":4:11"), "%v", messages)
+	// cubes are checked before spheres
+	assert.Assert(t, slices.Contains(messages,
+		scene+":8:13: scene object 'ball' is defined more than once, first definition at "+lib+":6:13"), "%v", messages)
+	// errors of elements in the scene itself still point into the scene
+	assert.Assert(t, slices.Contains(messages, scene+":9:17: cannot resolve material 'bogus' for scene object 'ball'"), "%v", messages)
+
+	var duplicateErr *DuplicateNameError
+	assert.Assert(t, errors.As(err, &duplicateErr))
+	assert.Assert(t, duplicateErr.First.File == scene)
+}
+
+func TestValidateDuplicateNames(t *testing.T) {
+	desc, err := ParseYaml(`
+patterns:
+  checker:
+    - name: p
+  stripe:
+    - name: p
+transforms:
+  - name: t
+  - name: t`)
+	assert.NilError(t, err)
+
+	errs := CreateSceneBuilder(desc, "").ValidateReferences()
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		return err.Error() == "pattern 'p' is defined more than once"
+	}), "%v", errs)
+	assert.Assert(t, slices.ContainsFunc(errs, func(err error) bool {
+		var fieldErr *FieldError
+		return errors.As(err, &fieldErr) && fieldErr.Path == "$.transforms[1].name"
+	}), "%v", errs)
+}
@@ -2,6 +2,7 @@ package progress
 
 import (
 	"fmt"
+	"os"
 	"strings"
 )
 
@@ -10,6 +11,11 @@ func Step(message string) {
 	fmt.Printf("%s\n", message)
 }
 
+// Warning reports a problem that doesn't stop the rendering on stderr
+func Warning(message string) {
+	fmt.Fprintf(os.Stderr, "warning: %s\n", message)
+}
+
 // SetFrameInfo sets up frame tracking information
 func SetFrameInfo(currentFrame, totalFrames int) {
 	percentage := int((float64(currentFrame) / float64(totalFrames)) * 100)
@@ -65,7 +65,7 @@ func CreateCubeScene(width int, height int) *canvas.Canvas {
 	objs = append(objs, room, tableSurface, leg1, leg2, leg3, leg4, glassCube, mirror)
 	light := lighting.CreateLight(math.CreatePoint(-9.0, 9.0, -5.0), math.CreateColor(0.7, 0.7, 0.7))
 	w := scene.EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 
 	cam := scene.CreateCamera(width, height, gomath.Pi/3.0)
@@ -48,7 +48,7 @@ func CreateCylinderScene(width int, height int) *canvas.Canvas {
 	objs = append(objs, floor, truncatedCylinder, closedCylinder, closedCone)
 	light := lighting.CreateLight(math.CreatePoint(-9.0, 9.0, -5.0), math.CreateColor(1.0, 1.0, 1.0))
 	w := scene.EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 
 	cam := scene.CreateCamera(width, height, gomath.Pi/3.0)
@@ -71,7 +71,7 @@ func CreateMirrorScene(width int, height int) *canvas.Canvas {
 	objs = append(objs, floor, mirror1, group1, group2, wallBack, middleWall, wallBehindCamera)
 	light := lighting.CreateLight(math.CreatePoint(-9.0, 9.0, -1.0), math.CreateColor(1.0, 1.0, 1.0))
 	w := scene.EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 
 	cam := scene.CreateCamera(width, height, gomath.Pi/3.0)
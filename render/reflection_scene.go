@@ -84,7 +84,7 @@ func CreateReflectionScene(width int, height int) *canvas.Canvas {
 	objs = append(objs, floor, backdrop, middle, right, left)
 	light := lighting.CreateLight(math.CreatePoint(-10.0, 10.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
 	w := scene.EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 
 	cam := scene.CreateCamera(width, height, gomath.Pi/3.0)
@@ -46,7 +46,7 @@ func CreateRefractionScene(width int, height int) *canvas.Canvas {
 	objs = append(objs, backdrop, outerBall, middleBall)
 	light := lighting.CreateLight(math.CreatePoint(-10.0, 15.0, -5.0), math.CreateColor(0.5, 0.5, 0.5))
 	w := scene.EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 
 	cam := scene.CreateCamera(width, height, gomath.Pi/3.0)
@@ -73,7 +73,7 @@ func CreateRefractionPlaygroundScene(width int, height int) *canvas.Canvas {
 	objs = append(objs, backdrop, leftBall, rightBall, rightBall2, rightBall3)
 	light := lighting.CreateLight(math.CreatePoint(-10.0, 15.0, -5.0), math.CreateColor(0.7, 0.7, 0.7))
 	w := scene.EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 
 	cam := scene.CreateCamera(width, height, gomath.Pi/3.0)
@@ -47,7 +47,10 @@ func CreateTeapotScene(width int, height int) *canvas.Canvas {
 	wallBehindCamera.GetMaterial().SetPattern(umberRedStripePattern)
 	wallBehindCamera.SetTransform(math.Translation(0.0, 0.0, -40.0).MulM(math.Rotation_X(gomath.Pi / 2.0)))
 
-	teapot := obj.ParseFile("resources/teapot_high.obj")
+	teapot, err := obj.ParseFile("resources/teapot_high.obj")
+	if err != nil {
+		panic(err)
+	}
 	teapotGroup := teapot.ToGroup(true)
 	teapotMaterial := g.DefaultMaterial()
 	teapotMaterial.SetReflective(0)
@@ -58,7 +61,7 @@ func CreateTeapotScene(width int, height int) *canvas.Canvas {
 	objs = append(objs, floor, ceiling, wallBack, wallLeft, wallRight, teapotGroup, wallBehindCamera)
 	light := lighting.CreateLight(math.CreatePoint(-3.0, 30.0, -20.0), math.CreateColor(1.0, 1.0, 1.0))
 	w := scene.EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 
 	cam := scene.CreateCamera(width, height, gomath.Pi/3.0)
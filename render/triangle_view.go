@@ -37,7 +37,7 @@ func CreateTriangleScene(width int, height int) *canvas.Canvas {
 	objs = append(objs, floor, wallBack, triangle)
 	light := lighting.CreateLight(math.CreatePoint(-9.0, 9.0, -1.0), math.CreateColor(1.0, 1.0, 1.0))
 	w := scene.EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 
 	cam := scene.CreateCamera(width, height, gomath.Pi/3.0)
@@ -0,0 +1,129 @@
+package scene
+
+import (
+	gomath "math"
+	"math/rand/v2"
+	g "raygo/geometry"
+	"raygo/math"
+	"sync"
+)
+
+// Adaptive antialiasing works in two passes:
+// first one ray is cast through every pixel corner. Neighbouring pixels share their corners,
+// so this costs about one ray per pixel. Afterwards every pixel whose corners differ by more
+// than the threshold is divided into quarters whose corners are sampled as well, recursively
+// up to the maximum depth. Pixels in flat regions are just the mean of their corners.
+
+// sampleCorners casts the rays of the first pass, it has to run before the pixels are rendered
+func (c *Camera) sampleCorners(w *World, workerThreads int) {
+	width := c.Hsize + 1
+	c.cornerColors = make([]math.Color, width*(c.Vsize+1))
+
+	rows := make(chan int, c.Vsize+1)
+	for y := range c.Vsize + 1 {
+		rows <- y
+	}
+	close(rows)
+
+	var wg sync.WaitGroup
+	for range max(workerThreads, 1) {
+		wg.Go(func() {
+			for y := range rows {
+				for x := range width {
+					coordinate := c.calculateWorldCoordinateWithOffset(float64(x), float64(y), 0.0, 0.0)
+					c.cornerColors[y*width+x] = w.ColorAt(c.adaptiveRay(coordinate), MAX_REFLECTION_LIMIT)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+func (c *Camera) renderAdaptivePixel(w *World, x int, y int) math.Color {
+	width := c.Hsize + 1
+	// samples of this pixel keyed by their offset from the top left corner
+	samples := map[[2]float64]math.Color{
+		{0.0, 0.0}: c.cornerColors[y*width+x],
+		{1.0, 0.0}: c.cornerColors[y*width+x+1],
+		{0.0, 1.0}: c.cornerColors[(y+1)*width+x],
+		{1.0, 1.0}: c.cornerColors[(y+1)*width+x+1],
+	}
+
+	rays := 0
+	color := c.adaptiveColor(w, x, y, samples, 0.0, 0.0, 1.0, 0, &rays)
+	c.ExtraRays.Add(int64(rays))
+	return color
+}
+
+// adaptiveColor returns the color of the square inside of pixel (x, y) with its top left corner
+// at (u, v) and the edge length size
+func (c *Camera) adaptiveColor(w *World, x int, y int, samples map[[2]float64]math.Color,
+	u float64, v float64, size float64, depth int, rays *int) math.Color {
+
+	sample := func(du float64, dv float64) math.Color {
+		key := [2]float64{u + du, v + dv}
+		if color, ok := samples[key]; ok {
+			return color
+		}
+		coordinate := c.calculateWorldCoordinateWithOffset(float64(x), float64(y), key[0], key[1])
+		color := w.ColorAt(c.adaptiveRay(coordinate), MAX_REFLECTION_LIMIT)
+		samples[key] = color
+		*rays++
+		return color
+	}
+
+	corners := []math.Color{sample(0.0, 0.0), sample(size, 0.0), sample(0.0, size), sample(size, size)}
+	if depth >= c.Sampler.MaxDepth || colorContrast(corners) <= c.Sampler.Threshold {
+		return getMeanColor(corners)
+	}
+
+	half := size / 2.0
+	quarters := []math.Color{
+		c.adaptiveColor(w, x, y, samples, u, v, half, depth+1, rays),
+		c.adaptiveColor(w, x, y, samples, u+half, v, half, depth+1, rays),
+		c.adaptiveColor(w, x, y, samples, u, v+half, half, depth+1, rays),
+		c.adaptiveColor(w, x, y, samples, u+half, v+half, half, depth+1, rays),
+	}
+	return getMeanColor(quarters)
+}
+
+// adaptiveRay picks the lens point and the time from the coordinate itself,
+// that way a sample that is shared by neighbouring pixels is the same ray for both of them
+func (c *Camera) adaptiveRay(coordinate math.Point) g.Ray {
+	if c.Aperture <= 0.0 && c.Shutter <= 0.0 {
+		return c.RayForCoordinate(coordinate)
+	}
+	rng := rand.New(rand.NewPCG(gomath.Float64bits(coordinate.X), gomath.Float64bits(coordinate.Y)))
+	ray := c.RayThroughLens(coordinate, rng.Float64(), rng.Float64())
+	if c.Shutter > 0.0 {
+		ray.Time = rng.Float64()
+	}
+	return ray
+}
+
+// colorContrast is the largest difference of any color channel between the given colors
+func colorContrast(colors []math.Color) float64 {
+	contrast := 0.0
+	for i := range colors {
+		for j := i + 1; j < len(colors); j++ {
+			diff := colors[i].Subtract(colors[j]).Abs()
+			contrast = gomath.Max(contrast, gomath.Max(diff.X, gomath.Max(diff.Y, diff.Z)))
+		}
+	}
+	return contrast
+}
+
+func getMeanColor(colors []math.Color) math.Color {
+	red := 0.0
+	green := 0.0
+	blue := 0.0
+
+	for _, color := range colors {
+		red += color.X
+		green += color.Y
+		blue += color.Z
+	}
+
+	colorCount := float64(len(colors))
+	return math.CreateColor(red/colorCount, green/colorCount, blue/colorCount)
+}
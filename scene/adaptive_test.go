@@ -0,0 +1,64 @@
+package scene
+
+import (
+	gomath "math"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func adaptiveTestCamera(sampler Sampler) *Camera {
+	c := CreateCamera(21, 21, gomath.Pi/2.0)
+	c.Position = CreateCameraPosition(math.CreatePoint(0.0, 0.0, -5.0), math.CreatePoint(0.0, 0.0, 0.0), math.CreateVector(0.0, 1.0, 0.0))
+	c.Sampler = sampler
+	return c
+}
+
+func TestAdaptiveSamplingSkipsFlatImage(t *testing.T) {
+	w := EmptyWorld()
+	c := adaptiveTestCamera(CreateAdaptiveSampler(0.05, 3))
+
+	canv := c.RenderSinglethreaded(w)
+
+	assert.Assert(t, c.ExtraRays.Load() == 0)
+	assert.Assert(t, math.CreateColor(0.0, 0.0, 0.0).Equals(canv.GetPixelAt(10, 10)))
+}
+
+func TestAdaptiveSamplingRefinesEdges(t *testing.T) {
+	w := DefaultWorld()
+	w.CalculateInverseTransforms()
+	c := adaptiveTestCamera(CreateAdaptiveSampler(0.05, 2))
+
+	c.RenderSinglethreaded(w)
+
+	extraRays := c.ExtraRays.Load()
+	// a full subdivision of every pixel to depth 2 would need 24 extra rays per pixel
+	assert.Assert(t, extraRays > 0)
+	assert.Assert(t, extraRays < int64(24*c.Hsize*c.Vsize))
+}
+
+func TestAdaptiveSamplingWithoutDepth(t *testing.T) {
+	w := DefaultWorld()
+	w.CalculateInverseTransforms()
+	c := adaptiveTestCamera(CreateAdaptiveSampler(0.0, 0))
+
+	canv := c.RenderSinglethreaded(w)
+
+	assert.Assert(t, c.ExtraRays.Load() == 0)
+	expected := getMeanColor([]math.Color{
+		c.cornerColors[10*22+10], c.cornerColors[10*22+11], c.cornerColors[11*22+10], c.cornerColors[11*22+11],
+	})
+	assert.Assert(t, expected.Equals(canv.GetPixelAt(10, 10)))
+}
+
+func TestColorContrast(t *testing.T) {
+	colors := []math.Color{
+		math.CreateColor(0.1, 0.5, 0.2),
+		math.CreateColor(0.2, 0.5, 0.2),
+		math.CreateColor(0.1, 0.2, 0.3),
+	}
+
+	assert.Assert(t, gomath.Abs(colorContrast(colors)-0.3) < math.EPSILON)
+	assert.Assert(t, colorContrast(colors[:1]) == 0.0)
+}
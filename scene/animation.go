@@ -0,0 +1,267 @@
+package scene
+
+import (
+	"cmp"
+	g "raygo/geometry"
+	"raygo/math"
+	"slices"
+)
+
+type Easing int
+
+const (
+	LINEAR Easing = iota
+	EASE_IN
+	EASE_OUT
+	EASE_IN_OUT
+)
+
+// Animation moves the objects of a world along their keyframes.
+// Frames are distributed evenly, the first one shows time 0 and the last one Duration.
+type Animation struct {
+	Duration float64 // seconds
+	Fps      float64
+	Objects  []*ObjectAnimation
+}
+
+// Keyframe is the state of an animated object at a point in time. Every value is interpolated
+// between the closest keyframes before and after the current time that set it, nil values are left out.
+type Keyframe struct {
+	Time   float64
+	Easing Easing // interpolation from the previous keyframe to this one
+	// translation in the space of the parent, rotation and scale in object space
+	Translation     *math.Vector
+	Rotation        *math.Vector // radians around the x, y and z axis, applied in this order
+	Scale           *math.Vector
+	Color           *math.Color
+	Ambient         *float64
+	Diffuse         *float64
+	Specular        *float64
+	Shininess       *float64
+	Reflective      *float64
+	Transparency    *float64
+	RefractiveIndex *float64
+}
+
+type ObjectAnimation struct {
+	Shape     g.Shape
+	Keyframes []Keyframe // sorted by time
+	transform math.Matrix
+	materials []animatedMaterial
+}
+
+// animatedMaterial is a material as it was before the animation and how to set it. Groups and csg
+// shapes have one for every shape inside of them, that way their children keep their own materials.
+type animatedMaterial struct {
+	base g.Material
+	set  func(m g.Material)
+}
+
+func CreateAnimation(duration float64, fps float64) *Animation {
+	return &Animation{
+		Duration: duration,
+		Fps:      fps,
+		Objects:  make([]*ObjectAnimation, 0),
+	}
+}
+
+// CreateObjectAnimation animates the shape relative to its current transform and material
+func CreateObjectAnimation(shape g.Shape, keyframes []Keyframe) *ObjectAnimation {
+	sorted := slices.Clone(keyframes)
+	slices.SortStableFunc(sorted, func(a Keyframe, b Keyframe) int {
+		return cmp.Compare(a.Time, b.Time)
+	})
+	return &ObjectAnimation{
+		Shape:     shape,
+		Keyframes: sorted,
+		transform: shape.GetTransform(),
+		materials: collectMaterials(shape),
+	}
+}
+
+// collectMaterials returns the materials of the shape and every shape inside of it
+func collectMaterials(shape g.Shape) []animatedMaterial {
+	switch s := shape.(type) {
+	case *g.Group:
+		materials := []animatedMaterial{{base: s.Material, set: func(m g.Material) { s.Material = m }}}
+		for _, child := range s.Children {
+			materials = append(materials, collectMaterials(child)...)
+		}
+		return materials
+	case *g.CSG:
+		materials := []animatedMaterial{{base: s.Material, set: func(m g.Material) { s.Material = m }}}
+		materials = append(materials, collectMaterials(s.Left)...)
+		return append(materials, collectMaterials(s.Right)...)
+	case *g.Instance:
+		// instances keep the different materials of their prototype, e.g. from an mtl file
+		materials := make([]animatedMaterial, 0)
+		for name, base := range s.PrototypeMaterials() {
+			materials = append(materials, animatedMaterial{base: base, set: func(m g.Material) { s.SetPrototypeMaterial(name, m) }})
+		}
+		return materials
+	}
+	return []animatedMaterial{{base: *shape.GetMaterial(), set: shape.SetMaterial}}
+}
+
+func (a *Animation) AddObject(o *ObjectAnimation) *Animation {
+	a.Objects = append(a.Objects, o)
+	return a
+}
+
+func (a *Animation) FrameCount() int {
+	if a == nil {
+		return 0
+	}
+	return int(a.Duration * a.Fps)
+}
+
+// FrameTime returns the point in time that is shown by the frame, frames after the last one show its time
+func (a *Animation) FrameTime(frame int) float64 {
+	frames := a.FrameCount()
+	if frames <= 1 {
+		return 0.0
+	}
+	return a.Duration * float64(min(frame, frames-1)) / float64(frames-1)
+}
+
+// SetTime moves all animated objects to their state at time t.
+// Their inverse transforms and the bounding boxes of their groups are recalculated.
+func (w *World) SetTime(t float64) {
+	w.SetShutter(t, t)
+}
+
+// SetShutter moves all animated objects to their state at time open. Objects with animated transforms
+// move on to their state at time close while the shutter is open, which blurs them.
+// If both times are the same there is no motion.
+func (w *World) SetShutter(open float64, close float64) {
+	if w.Animation == nil {
+		return
+	}
+	for _, o := range w.Animation.Objects {
+		var end math.Matrix
+		if close > open {
+			o.apply(close)
+			end = o.Shape.GetTransform()
+		}
+		o.apply(open)
+		if o.animatesTransform() {
+			if close > open {
+				o.Shape.SetMotion(g.CreateMotion(end))
+			} else {
+				o.Shape.SetMotion(nil)
+			}
+		}
+		o.Shape.CalculateInverseTransform()
+		for parent := o.Shape.GetParent(); parent != nil; parent = parent.GetParent() {
+			parent.CachedBoundingBox = nil
+		}
+	}
+}
+
+func (o *ObjectAnimation) apply(t float64) {
+	// object space animations are applied before the transform of the object, translations after it
+	transform := o.transform
+	if rotation, ok := interpolate(o.Keyframes, t, func(k *Keyframe) *math.Vector { return k.Rotation }, lerpTuple); ok {
+		rotate := math.Rotation_Z(rotation.Z).MulM(math.Rotation_Y(rotation.Y)).MulM(math.Rotation_X(rotation.X))
+		transform = transform.MulM(rotate)
+	}
+	if scale, ok := interpolate(o.Keyframes, t, func(k *Keyframe) *math.Vector { return k.Scale }, lerpTuple); ok {
+		transform = transform.MulM(math.Scaling(scale.X, scale.Y, scale.Z))
+	}
+	if translation, ok := interpolate(o.Keyframes, t, func(k *Keyframe) *math.Vector { return k.Translation }, lerpTuple); ok {
+		transform = math.Translation(translation.X, translation.Y, translation.Z).MulM(transform)
+	}
+	o.Shape.SetTransform(transform)
+
+	for _, m := range o.materials {
+		// materials without animated channels are left alone
+		if material, animated := o.animateMaterial(m.base, t); animated {
+			m.set(material)
+		}
+	}
+}
+
+// animateMaterial returns base with the animated channels at time t, animated is false if no keyframe sets any of them
+func (o *ObjectAnimation) animateMaterial(base g.Material, t float64) (material g.Material, animated bool) {
+	material = base
+	if color, ok := interpolate(o.Keyframes, t, func(k *Keyframe) *math.Color { return k.Color }, lerpTuple); ok {
+		material.Color = color
+		animated = true
+	}
+	scalars := []struct {
+		value   *float64
+		channel func(k *Keyframe) *float64
+	}{
+		{&material.Ambient, func(k *Keyframe) *float64 { return k.Ambient }},
+		{&material.Diffuse, func(k *Keyframe) *float64 { return k.Diffuse }},
+		{&material.Specular, func(k *Keyframe) *float64 { return k.Specular }},
+		{&material.Shininess, func(k *Keyframe) *float64 { return k.Shininess }},
+		{&material.Reflective, func(k *Keyframe) *float64 { return k.Reflective }},
+		{&material.Transparency, func(k *Keyframe) *float64 { return k.Transparency }},
+		{&material.RefractiveIndex, func(k *Keyframe) *float64 { return k.RefractiveIndex }},
+	}
+	for _, s := range scalars {
+		if value, ok := interpolate(o.Keyframes, t, s.channel, lerpFloat); ok {
+			*s.value = value
+			animated = true
+		}
+	}
+	return material, animated
+}
+
+func (o *ObjectAnimation) animatesTransform() bool {
+	return slices.ContainsFunc(o.Keyframes, func(k Keyframe) bool {
+		return k.Translation != nil || k.Rotation != nil || k.Scale != nil
+	})
+}
+
+// interpolate returns the value of a channel at time t, ok is false if no keyframe sets the channel.
+// Before the first and after the last keyframe of the channel its value stays the same.
+func interpolate[T any](keyframes []Keyframe, t float64, channel func(k *Keyframe) *T,
+	lerp func(a T, b T, f float64) T) (value T, ok bool) {
+
+	var previous, next *Keyframe
+	for i := range keyframes {
+		k := &keyframes[i]
+		if channel(k) == nil {
+			continue
+		}
+		if k.Time <= t {
+			previous = k
+		} else if next == nil {
+			next = k
+		}
+	}
+
+	switch {
+	case previous == nil && next == nil:
+		return value, false
+	case next == nil:
+		return *channel(previous), true
+	case previous == nil:
+		return *channel(next), true
+	}
+	f := (t - previous.Time) / (next.Time - previous.Time)
+	return lerp(*channel(previous), *channel(next), next.Easing.apply(f)), true
+}
+
+// apply maps the linear progress f in [0, 1] between two keyframes to the eased progress
+func (e Easing) apply(f float64) float64 {
+	switch e {
+	case EASE_IN:
+		return f * f
+	case EASE_OUT:
+		return 1.0 - (1.0-f)*(1.0-f)
+	case EASE_IN_OUT:
+		return f * f * (3.0 - 2.0*f)
+	}
+	return f
+}
+
+func lerpFloat(a float64, b float64, f float64) float64 {
+	return a + (b-a)*f
+}
+
+func lerpTuple(a math.Tuple, b math.Tuple, f float64) math.Tuple {
+	return a.Add(b.Subtract(a).Mul(f))
+}
@@ -0,0 +1,179 @@
+package scene
+
+import (
+	gomath "math"
+	g "raygo/geometry"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestAnimationFrameTimes(t *testing.T) {
+	a := CreateAnimation(2.0, 3.0)
+
+	assert.Assert(t, a.FrameCount() == 6)
+	assert.Assert(t, a.FrameTime(0) == 0.0)
+	assert.Assert(t, a.FrameTime(5) == 2.0)
+	assert.Assert(t, a.FrameTime(9) == 2.0)
+	assert.Assert(t, math.CreatePoint(a.FrameTime(1), 0, 0).Equals(math.CreatePoint(0.4, 0, 0)))
+
+	var missing *Animation
+	assert.Assert(t, missing.FrameCount() == 0)
+}
+
+func TestInterpolateLinear(t *testing.T) {
+	keyframes := []Keyframe{
+		{Time: 0.0, Translation: ptr(math.CreateVector(0, 0, 0))},
+		{Time: 1.0, Translation: ptr(math.CreateVector(2, 4, 0))},
+		{Time: 2.0, Diffuse: ptr(0.5)},
+	}
+	translation := func(k *Keyframe) *math.Vector { return k.Translation }
+
+	v, ok := interpolate(keyframes, 0.25, translation, lerpTuple)
+	assert.Assert(t, ok)
+	assert.Assert(t, v.Equals(math.CreateVector(0.5, 1, 0)))
+
+	// the last keyframe doesn't set a translation, the value stays the same after time 1
+	v, _ = interpolate(keyframes, 1.5, translation, lerpTuple)
+	assert.Assert(t, v.Equals(math.CreateVector(2, 4, 0)))
+
+	// before the first keyframe of a channel it has the value of that keyframe
+	diffuse, ok := interpolate(keyframes, 0.0, func(k *Keyframe) *float64 { return k.Diffuse }, lerpFloat)
+	assert.Assert(t, ok)
+	assert.Assert(t, diffuse == 0.5)
+
+	_, ok = interpolate(keyframes, 0.0, func(k *Keyframe) *float64 { return k.Specular }, lerpFloat)
+	assert.Assert(t, !ok)
+}
+
+func TestEasing(t *testing.T) {
+	for _, e := range []Easing{LINEAR, EASE_IN, EASE_OUT, EASE_IN_OUT} {
+		assert.Assert(t, e.apply(0.0) == 0.0)
+		assert.Assert(t, e.apply(1.0) == 1.0)
+	}
+	assert.Assert(t, LINEAR.apply(0.25) == 0.25)
+	assert.Assert(t, EASE_IN.apply(0.25) < 0.25)
+	assert.Assert(t, EASE_OUT.apply(0.25) > 0.25)
+	assert.Assert(t, EASE_IN_OUT.apply(0.25) < 0.25)
+	assert.Assert(t, EASE_IN_OUT.apply(0.5) == 0.5)
+	assert.Assert(t, EASE_IN_OUT.apply(0.75) > 0.75)
+}
+
+func TestSetTimeMovesObject(t *testing.T) {
+	s := g.CreateSphere()
+	s.SetTransform(math.Scaling(2, 2, 2))
+	group := g.EmptyGroup()
+	group.AddChild(s)
+
+	w := EmptyWorld()
+	w.Objects = append(w.Objects, group)
+	w.Animation = CreateAnimation(1.0, 10.0)
+	w.Animation.AddObject(CreateObjectAnimation(s, []Keyframe{
+		{Time: 1.0, Translation: ptr(math.CreateVector(0, 10, 0)), Reflective: ptr(1.0)},
+		{Time: 0.0, Translation: ptr(math.CreateVector(0, 0, 0)), Reflective: ptr(0.0)},
+	}))
+	group.CalculateInverseTransform()
+	assert.Assert(t, group.Bounds().Maximum.Equals(math.CreatePoint(2, 2, 2)))
+
+	w.SetTime(0.5)
+
+	// the translation is applied after the transform of the sphere
+	expected := math.Translation(0, 5, 0).MulM(math.Scaling(2, 2, 2))
+	assert.Assert(t, s.GetTransform().Equals(expected))
+	assert.Assert(t, s.GetInverseTransform().Equals(expected.Inverse()))
+	assert.Assert(t, s.GetMaterial().Reflective == 0.5)
+	assert.Assert(t, group.Bounds().Maximum.Equals(math.CreatePoint(2, 7, 2)))
+
+	// object space channels are applied before the transform of the sphere
+	w.Animation.Objects[0] = CreateObjectAnimation(s, []Keyframe{
+		{Time: 0.0, Rotation: ptr(math.CreateVector(0, 0, gomath.Pi/2)), Scale: ptr(math.CreateVector(1, 3, 1))},
+	})
+	w.SetTime(0.0)
+	expected = math.Translation(0, 5, 0).MulM(math.Scaling(2, 2, 2)).
+		MulM(math.Rotation_Z(gomath.Pi / 2)).MulM(math.Scaling(1, 3, 1))
+	assert.Assert(t, s.GetTransform().Equals(expected))
+}
+
+func TestSetTimeKeepsMaterialsOfChildren(t *testing.T) {
+	red := g.CreateSphere()
+	red.GetMaterial().SetColor(math.CreateColor(1, 0, 0))
+	red.GetMaterial().Pattern = g.CreateCheckerPattern(math.CreateColor(1, 0, 0), math.CreateColor(0, 0, 0))
+	blue := g.CreateSphere()
+	blue.GetMaterial().SetColor(math.CreateColor(0, 0, 1))
+	blue.GetMaterial().Transparency = 0.2
+	group := g.EmptyGroup()
+	group.AddChild(red)
+	group.AddChild(blue)
+
+	w := EmptyWorld()
+	w.Objects = append(w.Objects, group)
+	w.Animation = CreateAnimation(1.0, 10.0)
+	w.Animation.AddObject(CreateObjectAnimation(group, []Keyframe{
+		{Time: 0.0, Transparency: ptr(0.0)},
+		{Time: 1.0, Transparency: ptr(0.8)},
+	}))
+	group.CalculateInverseTransform()
+
+	w.SetTime(0.5)
+
+	assert.Assert(t, red.GetMaterial().Color.Equals(math.CreateColor(1, 0, 0)))
+	assert.Assert(t, red.GetMaterial().Pattern != nil)
+	assert.Assert(t, blue.GetMaterial().Color.Equals(math.CreateColor(0, 0, 1)))
+	assert.Assert(t, red.GetMaterial().Transparency == 0.4)
+	assert.Assert(t, blue.GetMaterial().Transparency == 0.4)
+	assert.Assert(t, group.GetMaterial().Transparency == 0.4)
+}
+
+func TestSetShutterBlursMovingObjects(t *testing.T) {
+	s := g.CreateSphere()
+	colored := g.CreateSphere()
+	w := EmptyWorld()
+	w.Objects = append(w.Objects, s, colored)
+	w.Animation = CreateAnimation(1.0, 10.0)
+	w.Animation.AddObject(CreateObjectAnimation(s, []Keyframe{
+		{Time: 0.0, Translation: ptr(math.CreateVector(0, 0, 0))},
+		{Time: 1.0, Translation: ptr(math.CreateVector(0, 10, 0))},
+	}))
+	w.Animation.AddObject(CreateObjectAnimation(colored, []Keyframe{
+		{Time: 0.0, Color: ptr(math.CreateColor(1, 0, 0))},
+	}))
+
+	w.SetShutter(0.2, 0.5)
+
+	assert.Assert(t, s.GetTransform().Equals(math.Translation(0, 2, 0)))
+	assert.Assert(t, s.GetMotion().End.Equals(math.Translation(0, 5, 0)))
+	// objects that only change their material don't move
+	assert.Assert(t, colored.GetMotion() == nil)
+
+	w.SetTime(0.5)
+	assert.Assert(t, s.GetMotion() == nil)
+}
+
+func TestRenderAnimatedWorld(t *testing.T) {
+	w := DefaultWorld()
+	w.Animation = CreateAnimation(1.0, 3.0)
+	w.Animation.AddObject(CreateObjectAnimation(w.Objects[0], []Keyframe{
+		{Time: 0.0, Color: ptr(math.CreateColor(1, 0, 0))},
+		{Time: 1.0, Color: ptr(math.CreateColor(0, 0, 1))},
+	}))
+
+	c := CreateCamera(11, 11, gomath.Pi/2)
+	from := math.CreatePoint(0, 0, -5)
+	to := math.CreatePoint(0, 0, 0)
+	up := math.CreateVector(0, 1, 0)
+	c.Position = CreateCameraPosition(from, to, up)
+	w.CalculateInverseTransforms()
+
+	images := c.Render(w, false)
+
+	assert.Assert(t, len(images) == 3)
+	first := images[0].GetPixelAt(5, 5)
+	last := images[2].GetPixelAt(5, 5)
+	assert.Assert(t, first.X > 0.4 && first.Z < 0.01, "%v", first)
+	assert.Assert(t, last.X < 0.01 && last.Z > 0.4, "%v", last)
+}
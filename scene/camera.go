@@ -6,13 +6,29 @@ import (
 	g "raygo/geometry"
 	"raygo/math"
 	"raygo/progress"
+	"runtime"
 	"sync"
+	"sync/atomic"
 )
 
+type Projection int
+
+const (
+	PERSPECTIVE Projection = iota
+	ORTHOGRAPHIC
+	FISHEYE
+	EQUIRECTANGULAR
+)
+
+// edge length in pixels of the square tiles that are handed to the render workers
+const TILE_SIZE = 32
+
 type Camera struct {
 	Hsize            int
 	Vsize            int
 	FieldOfView      float64
+	Projection       Projection
+	ViewWidth        float64 // width of the visible area for orthographic projection
 	Transform        math.Matrix
 	HalfWidth        float64
 	HalfHeight       float64
@@ -20,16 +36,24 @@ type Camera struct {
 	Animation        *CameraAnimation
 	Position         CameraPosition
 	PositionStates   []CameraPosition
-	Antialias        bool
-	ColorCache       ColorCache
+	FovStates        []float64 // field of view of every frame, nil if it is not animated
+	Tracking         g.Shape   // the camera looks at the center of this shape in every frame, may be nil
+	Sampler          Sampler
+	Aperture         float64      // diameter of the lens, 0 is a pinhole camera with everything in focus
+	FocalDistance    float64      // distance from the camera to the plane that is in focus
+	Shutter          float64      // fraction of a frame the shutter stays open, 0 disables motion blur
+	Threads          int          // number of render workers for multithreaded rendering
+	ExtraRays        atomic.Int64 // rays cast by adaptive antialiasing in addition to the first pass
 	InverseTransform *math.Matrix // <-- invalidate after rendering of frame
+	cornerColors     []math.Color // first pass of adaptive antialiasing, see adaptive.go
 }
 
-// only circular motion around point for now
+// circular motion around the point the camera looks at, or a motion along the splines of Path
 type CameraAnimation struct {
 	FullMotionRadians float64
 	MovementTime      float64
 	TargetFps         float64
+	Path              *CameraPath // replaces the circular motion if set
 }
 
 type CameraPosition struct {
@@ -38,29 +62,6 @@ type CameraPosition struct {
 	Up   math.Vector
 }
 
-type ColorCache struct {
-	mu               sync.Mutex
-	CanvasColorCache map[math.Point]*math.Color // <-- invalidate after rendering of frame
-}
-
-func (cc *ColorCache) Set(p math.Point, color *math.Color) {
-	cc.mu.Lock()
-	defer cc.mu.Unlock()
-	cc.CanvasColorCache[p] = color
-}
-
-func (cc *ColorCache) Get(p math.Point) *math.Color {
-	cc.mu.Lock()
-	defer cc.mu.Unlock()
-	return cc.CanvasColorCache[p]
-}
-
-func (cc *ColorCache) Reset() {
-	cc.mu.Lock()
-	defer cc.mu.Unlock()
-	cc.CanvasColorCache = make(map[math.Point]*math.Color, 0)
-}
-
 func CreateCamera(hsize int, vsize int, fov float64) *Camera {
 	c := &Camera{
 		Hsize:            hsize,
@@ -68,10 +69,10 @@ func CreateCamera(hsize int, vsize int, fov float64) *Camera {
 		FieldOfView:      fov,
 		Transform:        math.IdentityMatrix(),
 		InverseTransform: nil,
-		ColorCache: ColorCache{
-			CanvasColorCache: make(map[math.Point]*math.Color, 0),
-		},
-		Antialias: false,
+		Sampler:          DefaultSampler(),
+		Aperture:         0.0,
+		FocalDistance:    1.0,
+		Threads:          runtime.NumCPU(),
 	}
 	c.calculateCameraProperties()
 
@@ -103,9 +104,37 @@ func (cp *CameraPosition) Equals(other *CameraPosition) bool {
 		cp.Up.Equals(other.Up)
 }
 
+func (c *Camera) SetFieldOfView(fov float64) {
+	c.FieldOfView = fov
+	c.calculateCameraProperties()
+}
+
+func (c *Camera) SetProjection(projection Projection) {
+	c.Projection = projection
+	c.calculateCameraProperties()
+}
+
+func (c *Camera) SetViewWidth(width float64) {
+	c.ViewWidth = width
+	c.calculateCameraProperties()
+}
+
+// the canvas spans [-HalfWidth, HalfWidth] x [-HalfHeight, HalfHeight]. For perspective projection it
+// lies at z = -1 so that its size gives the field of view. Orthographic projection uses the
+// view width directly, fisheye and equirectangular use a canvas normalized to 1 and map it to angles.
 func (c *Camera) calculateCameraProperties() {
-	halfView := gomath.Tan(c.FieldOfView / 2.0)
 	aspect := float64(c.Hsize) / float64(c.Vsize)
+	halfView := 1.0
+	switch c.Projection {
+	case PERSPECTIVE:
+		halfView = gomath.Tan(c.FieldOfView / 2.0)
+	case ORTHOGRAPHIC:
+		halfView = c.ViewWidth / 2.0
+		if aspect < 1.0 {
+			// the view width is always horizontal
+			halfView = halfView / aspect
+		}
+	}
 
 	if aspect >= 1.0 {
 		c.HalfWidth = halfView
@@ -120,12 +149,17 @@ func (c *Camera) calculateCameraProperties() {
 
 // the initial camera position has to be set before calling this function
 func (c *Camera) createAnimationStates() {
+	c.FovStates = nil
 	if c.Animation == nil {
 		states := make([]CameraPosition, 0, 1)
 		states = append(states, c.Position)
 		c.PositionStates = states
 		return
 	}
+	if c.Animation.Path != nil {
+		c.createPathStates()
+		return
+	}
 
 	totalFrames := c.Animation.MovementTime * c.Animation.TargetFps
 	// if I want to enable camera smoothing radianFrameDelta needs to be variable
@@ -148,25 +182,98 @@ func (c *Camera) createAnimationStates() {
 	c.PositionStates = states
 }
 
+func (c *Camera) createPathStates() {
+	path := c.Animation.Path
+	totalFrames := max(int(c.Animation.MovementTime*c.Animation.TargetFps), 1)
+
+	// without control points the camera keeps its position or target
+	from := []math.Point{c.Position.From}
+	if len(path.From) > 0 {
+		from = path.From
+	}
+	to := []math.Point{c.Position.To}
+	if len(path.To) > 0 {
+		to = path.To
+	}
+	froms := path.Spline.sampleByArcLength(from, totalFrames)
+	tos := path.Spline.sampleByArcLength(to, totalFrames)
+
+	states := make([]CameraPosition, 0, totalFrames)
+	for frameIndex := range totalFrames {
+		states = append(states, CreateCameraPosition(froms[frameIndex], tos[frameIndex], c.Position.Up))
+	}
+	c.PositionStates = states
+	c.FovStates = path.fieldOfViewStates(totalFrames)
+}
+
 func (c *Camera) RayForPixel(x int, y int) g.Ray {
 	coordinate := c.calculateWorldCoordinateWithOffset(float64(x), float64(y), 0.5, 0.5)
+	return c.RayForCoordinate(coordinate)
+}
+
+func (c *Camera) RayForCoordinate(coordinate math.Point) g.Ray {
+	origin, target := c.project(coordinate)
 
-	// using the camera matrix, transform the canvas point and the origin,
+	// using the camera matrix, transform the origin and the target point,
 	// and then compute the rays direction vector.
-	// (remember that the canvas is at z = -1)
-	pixel := c.GetInverseTransform().MulT(coordinate)
-	origin := c.GetInverseTransform().MulT(math.CreatePoint(0.0, 0.0, 0.0))
+	pixel := c.GetInverseTransform().MulT(target)
+	origin = c.GetInverseTransform().MulT(origin)
 	direction := pixel.Subtract(origin).Normalize()
 
 	return g.CreateRay(origin, direction)
 }
 
-func (c *Camera) RayForCoordinate(coordinate math.Point) g.Ray {
-	// using the camera matrix, transform the canvas point and the origin,
-	// and then compute the rays direction vector.
-	pixel := c.GetInverseTransform().MulT(coordinate)
-	origin := c.GetInverseTransform().MulT(math.CreatePoint(0.0, 0.0, 0.0))
-	direction := pixel.Subtract(origin).Normalize()
+// project returns the origin and a second point of the ray through the canvas coordinate, both in camera space
+func (c *Camera) project(coordinate math.Point) (math.Point, math.Point) {
+	switch c.Projection {
+	case ORTHOGRAPHIC:
+		// parallel rays that start on the canvas
+		origin := math.CreatePoint(coordinate.X, coordinate.Y, 0.0)
+		return origin, math.CreatePoint(coordinate.X, coordinate.Y, -1.0)
+	case FISHEYE:
+		// equidistant fisheye: the angle to the viewing direction grows linearly
+		// with the distance to the center, the canvas edge is at half the field of view
+		theta := gomath.Hypot(coordinate.X, coordinate.Y) * c.FieldOfView / 2.0
+		phi := gomath.Atan2(coordinate.Y, coordinate.X)
+		target := math.CreatePoint(
+			gomath.Sin(theta)*gomath.Cos(phi),
+			gomath.Sin(theta)*gomath.Sin(phi),
+			-gomath.Cos(theta))
+		return math.CreatePoint(0.0, 0.0, 0.0), target
+	case EQUIRECTANGULAR:
+		// the canvas width covers 360 degrees of longitude, the height 180 degrees of latitude
+		longitude := coordinate.X / c.HalfWidth * gomath.Pi
+		latitude := coordinate.Y / c.HalfHeight * gomath.Pi / 2.0
+		target := math.CreatePoint(
+			gomath.Sin(longitude)*gomath.Cos(latitude),
+			gomath.Sin(latitude),
+			-gomath.Cos(longitude)*gomath.Cos(latitude))
+		return math.CreatePoint(0.0, 0.0, 0.0), target
+	}
+	// the canvas is at z = -1
+	return math.CreatePoint(0.0, 0.0, 0.0), coordinate
+}
+
+// RayThroughLens creates a ray for the canvas coordinate that starts at a point on the lens.
+// lensU and lensV in [0, 1) select the point on the lens disk. All rays for the same coordinate
+// meet on the focal plane, objects in front of or behind it get blurred when multiple lens points are sampled.
+// Depth of field is only supported for perspective projection.
+func (c *Camera) RayThroughLens(coordinate math.Point, lensU float64, lensV float64) g.Ray {
+	if c.Aperture <= 0.0 || c.Projection != PERSPECTIVE {
+		return c.RayForCoordinate(coordinate)
+	}
+
+	// uniformly distributed point on the lens disk
+	radius := c.Aperture / 2.0 * gomath.Sqrt(lensU)
+	theta := 2.0 * gomath.Pi * lensV
+	lensPoint := math.CreatePoint(radius*gomath.Cos(theta), radius*gomath.Sin(theta), 0.0)
+
+	// the canvas is at z = -1, scaling the coordinate moves it onto the focal plane
+	focalPoint := math.CreatePoint(coordinate.X*c.FocalDistance, coordinate.Y*c.FocalDistance, -c.FocalDistance)
+
+	origin := c.GetInverseTransform().MulT(lensPoint)
+	target := c.GetInverseTransform().MulT(focalPoint)
+	direction := target.Subtract(origin).Normalize()
 
 	return g.CreateRay(origin, direction)
 }
@@ -175,18 +282,30 @@ func (c *Camera) SetTransform(tf math.Matrix) {
 	c.Transform = tf
 }
 
+// Render returns one image per frame. The camera and the world are animated independently,
+// if one of them has fewer frames it keeps its last state for the remaining frames.
 func (c *Camera) Render(w *World, multithreaded bool) []*canvas.Canvas {
 	c.createAnimationStates()
-	totalFrames := len(c.PositionStates)
+	totalFrames := max(len(c.PositionStates), w.Animation.FrameCount())
 	progress.TotalFrames(totalFrames)
+	c.ExtraRays.Store(0)
 
-	images := make([]*canvas.Canvas, 0, len(c.PositionStates))
-	for frameIndex, currentPosition := range c.PositionStates {
-		c.Position = currentPosition
+	images := make([]*canvas.Canvas, 0, totalFrames)
+	for frameIndex := range totalFrames {
+		c.Position = c.PositionStates[min(frameIndex, len(c.PositionStates)-1)]
 		c.InverseTransform = nil
-		c.ColorCache.Reset()
+		if len(c.FovStates) > 0 {
+			c.SetFieldOfView(c.FovStates[min(frameIndex, len(c.FovStates)-1)])
+		}
+		if w.Animation != nil {
+			frameTime := w.Animation.FrameTime(frameIndex)
+			w.SetShutter(frameTime, frameTime+c.Shutter/w.Animation.Fps)
+		}
+		if c.Tracking != nil {
+			c.Position.To = g.GetCenter(c.Tracking)
+		}
 		if multithreaded {
-			images = append(images, c.RenderMultithreaded(w, c.Hsize/2))
+			images = append(images, c.RenderMultithreaded(w, c.Threads))
 		} else {
 			images = append(images, c.RenderSinglethreaded(w))
 		}
@@ -198,40 +317,49 @@ func (c *Camera) Render(w *World, multithreaded bool) []*canvas.Canvas {
 func (c *Camera) RenderSinglethreaded(w *World) *canvas.Canvas {
 	c.Transform = math.ViewTransform(c.Position.From, c.Position.To, c.Position.Up)
 	canv := canvas.CreateCanvas(c.Hsize, c.Vsize)
+	if c.Sampler.Adaptive {
+		c.sampleCorners(w, 1)
+	}
 
 	for y := range c.Vsize {
 		for x := range c.Hsize {
-			r := c.RayForPixel(x, y)
-			color := w.ColorAt(r, MAX_REFLECTION_LIMIT)
-			if c.Antialias {
-				relevantPixelColors := c.getCornerColors(w, x, y)
-				relevantPixelColors = append(relevantPixelColors, color)
-				color = getMeanColor(relevantPixelColors)
-			}
-			canv.WritePixel(x, y, color)
+			canv.WritePixel(x, y, c.renderPixel(w, x, y))
 		}
 	}
 
 	return &canv
 }
 
+type tile struct {
+	fromX, toX int
+	fromY, toY int
+}
+
+// RenderMultithreaded splits the image into tiles which are put into a queue.
+// workerThreads workers take tiles from the queue until all of them are rendered,
+// that way expensive regions of the image are shared among all workers.
 func (c *Camera) RenderMultithreaded(w *World, workerThreads int) *canvas.Canvas {
 	c.Transform = math.ViewTransform(c.Position.From, c.Position.To, c.Position.Up)
+	// calculate the inverse once before the workers start to read it concurrently
+	c.GetInverseTransform()
 	var wg sync.WaitGroup
 	canv := canvas.CreateCanvas(c.Hsize, c.Vsize)
+	if c.Sampler.Adaptive {
+		c.sampleCorners(w, workerThreads)
+	}
 
-	rowsPerWorker := c.Vsize / workerThreads
-	remainingRows := c.Vsize % workerThreads
-
-	for worker := range workerThreads {
-		from := rowsPerWorker * worker
-		to := from + rowsPerWorker
-		if worker == workerThreads-1 {
-			to = to + remainingRows
-		}
+	tiles := c.createTiles()
+	queue := make(chan tile, len(tiles))
+	for _, t := range tiles {
+		queue <- t
+	}
+	close(queue)
 
+	for range max(workerThreads, 1) {
 		wg.Go(func() {
-			c.renderPartially(from, to, w, &canv)
+			for t := range queue {
+				c.renderTile(t, w, &canv)
+			}
 		})
 	}
 
@@ -239,61 +367,54 @@ func (c *Camera) RenderMultithreaded(w *World, workerThreads int) *canvas.Canvas
 	return &canv
 }
 
-func (c *Camera) renderPartially(fromY int, toY int, w *World, cv *canvas.Canvas) {
-	for y := fromY; y < toY; y++ {
-		for x := range c.Hsize {
-			r := c.RayForPixel(x, y)
-			color := w.ColorAt(r, MAX_REFLECTION_LIMIT)
-			if c.Antialias {
-				relevantPixelColors := c.getCornerColors(w, x, y)
-				relevantPixelColors = append(relevantPixelColors, color)
-				color = getMeanColor(relevantPixelColors)
-			}
-			cv.WritePixel(x, y, color)
+func (c *Camera) createTiles() []tile {
+	tiles := make([]tile, 0)
+	for y := 0; y < c.Vsize; y += TILE_SIZE {
+		for x := 0; x < c.Hsize; x += TILE_SIZE {
+			tiles = append(tiles, tile{
+				fromX: x,
+				toX:   min(x+TILE_SIZE, c.Hsize),
+				fromY: y,
+				toY:   min(y+TILE_SIZE, c.Vsize),
+			})
 		}
 	}
+	return tiles
 }
 
-/**
-* Gets the colors the 4 corners of the pixel.
- */
-func (c *Camera) getCornerColors(w *World, x int, y int) []math.Color {
-	xFloat := float64(x)
-	yFloat := float64(y)
-	points := make([]math.Point, 0)
-	points = append(points, c.calculateWorldCoordinateWithOffset(xFloat, yFloat, 0.0, 0.0))
-	points = append(points, c.calculateWorldCoordinateWithOffset(xFloat, yFloat, 0.0, 1.0))
-	points = append(points, c.calculateWorldCoordinateWithOffset(xFloat, yFloat, 1.0, 0.0))
-	points = append(points, c.calculateWorldCoordinateWithOffset(xFloat, yFloat, 1.0, 1.0))
-
-	colors := make([]math.Color, 0)
-	for _, corner := range points {
-		if cornerColor := c.ColorCache.Get(corner); cornerColor == nil {
-			cornerRay := c.RayForCoordinate(corner)
-			color := w.ColorAt(cornerRay, MAX_REFLECTION_LIMIT)
-			c.ColorCache.Set(corner, &color)
-			colors = append(colors, color)
-		} else {
-			colors = append(colors, *cornerColor)
+func (c *Camera) renderTile(t tile, w *World, cv *canvas.Canvas) {
+	for y := t.fromY; y < t.toY; y++ {
+		for x := t.fromX; x < t.toX; x++ {
+			cv.WritePixel(x, y, c.renderPixel(w, x, y))
 		}
 	}
-
-	return colors
 }
 
-func getMeanColor(colors []math.Color) math.Color {
-	r := 0.0
-	g := 0.0
-	b := 0.0
+func (c *Camera) renderPixel(w *World, x int, y int) math.Color {
+	if c.Sampler.Adaptive {
+		return c.renderAdaptivePixel(w, x, y)
+	}
+	if c.Sampler.Samples <= 1 && c.Aperture <= 0.0 && c.Shutter <= 0.0 {
+		return w.ColorAt(c.RayForPixel(x, y), MAX_REFLECTION_LIMIT)
+	}
 
-	for _, color := range colors {
-		r += color.X
-		g += color.Y
-		b += color.Z
+	// the samples are spread over the pixel, the lens and the time the shutter is open
+	red, green, blue := 0.0, 0.0, 0.0
+	totalWeight := 0.0
+	for _, sample := range c.Sampler.pixelSamples(x, y) {
+		coordinate := c.calculateWorldCoordinateWithOffset(float64(x), float64(y), sample.xOffset, sample.yOffset)
+		ray := c.RayThroughLens(coordinate, sample.lensU, sample.lensV)
+		if c.Shutter > 0.0 {
+			ray.Time = sample.time
+		}
+		color := w.ColorAt(ray, MAX_REFLECTION_LIMIT)
+		red += color.X * sample.weight
+		green += color.Y * sample.weight
+		blue += color.Z * sample.weight
+		totalWeight += sample.weight
 	}
 
-	colorCount := float64(len(colors))
-	return math.CreateColor(r/colorCount, g/colorCount, b/colorCount)
+	return math.CreateColor(red/totalWeight, green/totalWeight, blue/totalWeight)
 }
 
 func (c *Camera) calculateWorldCoordinateWithOffset(x float64, y float64, xOff float64, yOff float64) math.Point {
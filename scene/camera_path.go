@@ -0,0 +1,139 @@
+package scene
+
+import (
+	"raygo/math"
+	"sort"
+)
+
+type SplineType int
+
+const (
+	CATMULL_ROM SplineType = iota // passes through all control points
+	BEZIER                        // piecewise cubic, every third control point lies on the curve
+)
+
+// number of straight lines per spline segment that approximate its length
+const ARC_LENGTH_SAMPLES = 64
+
+// CameraPath moves the camera along splines. The frames are distributed by arc length,
+// so the camera and the point it looks at move with constant speed.
+type CameraPath struct {
+	Spline      SplineType
+	From        []math.Point // control points of the camera position
+	To          []math.Point // control points of the point the camera looks at, empty keeps the target of the camera
+	FieldOfView []float64    // radians, evenly spread over the animation, empty keeps the field of view of the camera
+}
+
+func CreateCameraPath(spline SplineType, from []math.Point, to []math.Point) *CameraPath {
+	return &CameraPath{
+		Spline: spline,
+		From:   from,
+		To:     to,
+	}
+}
+
+// segmentCount returns the number of curve segments that the control points describe
+func (s SplineType) segmentCount(points int) int {
+	if s == BEZIER {
+		return (points - 1) / 3
+	}
+	return points - 1
+}
+
+// splinePoint returns the point at u in [0, 1] on the whole curve
+func (s SplineType) splinePoint(points []math.Point, u float64) math.Point {
+	segments := s.segmentCount(len(points))
+	if segments < 1 {
+		return points[0]
+	}
+
+	position := u * float64(segments)
+	segment := min(int(position), segments-1)
+	t := position - float64(segment)
+
+	var result math.Vector
+	if s == BEZIER {
+		p := toVectors(points[segment*3 : segment*3+4])
+		mt := 1.0 - t
+		result = p[0].Mul(mt * mt * mt).
+			Add(p[1].Mul(3.0 * mt * mt * t)).
+			Add(p[2].Mul(3.0 * mt * t * t)).
+			Add(p[3].Mul(t * t * t))
+	} else {
+		// the first and the last control point are repeated to reach the ends of the curve
+		p := toVectors([]math.Point{
+			points[max(segment-1, 0)], points[segment], points[segment+1], points[min(segment+2, len(points)-1)],
+		})
+		p0, p1, p2, p3 := p[0], p[1], p[2], p[3]
+		result = p1.Mul(2.0).
+			Add(p2.Subtract(p0).Mul(t)).
+			Add(p0.Mul(2.0).Subtract(p1.Mul(5.0)).Add(p2.Mul(4.0)).Subtract(p3).Mul(t * t)).
+			Add(p1.Mul(3.0).Subtract(p0).Subtract(p2.Mul(3.0)).Add(p3).Mul(t * t * t)).
+			Mul(0.5)
+	}
+	return math.CreatePoint(result.X, result.Y, result.Z)
+}
+
+// toVectors turns points into vectors, so that they can be weighted and summed up
+func toVectors(points []math.Point) []math.Vector {
+	vectors := make([]math.Vector, 0, len(points))
+	for _, p := range points {
+		vectors = append(vectors, math.CreateVector(p.X, p.Y, p.Z))
+	}
+	return vectors
+}
+
+// sampleByArcLength returns frames points on the curve that are evenly spaced along it
+func (s SplineType) sampleByArcLength(points []math.Point, frames int) []math.Point {
+	result := make([]math.Point, 0, frames)
+	segments := s.segmentCount(len(points))
+	if segments < 1 || frames == 1 {
+		for range frames {
+			result = append(result, points[0])
+		}
+		return result
+	}
+
+	// approximate the curve with straight lines and remember the length up to every sample
+	sampleCount := segments * ARC_LENGTH_SAMPLES
+	lengths := make([]float64, sampleCount+1)
+	previous := s.splinePoint(points, 0.0)
+	for i := 1; i <= sampleCount; i++ {
+		current := s.splinePoint(points, float64(i)/float64(sampleCount))
+		lengths[i] = lengths[i-1] + current.Subtract(previous).Magnitude()
+		previous = current
+	}
+
+	total := lengths[sampleCount]
+	for frame := range frames {
+		target := total * float64(frame) / float64(frames-1)
+		i := max(sort.SearchFloat64s(lengths, target), 1)
+		i = min(i, sampleCount)
+		fraction := 0.0
+		if lengths[i] > lengths[i-1] {
+			fraction = (target - lengths[i-1]) / (lengths[i] - lengths[i-1])
+		}
+		u := (float64(i-1) + min(fraction, 1.0)) / float64(sampleCount)
+		result = append(result, s.splinePoint(points, u))
+	}
+	return result
+}
+
+// fieldOfViewStates spreads the field of view values evenly over the frames and interpolates linearly between them
+func (p *CameraPath) fieldOfViewStates(frames int) []float64 {
+	if len(p.FieldOfView) == 0 {
+		return nil
+	}
+
+	states := make([]float64, 0, frames)
+	for frame := range frames {
+		if len(p.FieldOfView) == 1 || frames == 1 {
+			states = append(states, p.FieldOfView[0])
+			continue
+		}
+		position := float64(frame) / float64(frames-1) * float64(len(p.FieldOfView)-1)
+		i := min(int(position), len(p.FieldOfView)-2)
+		states = append(states, lerpFloat(p.FieldOfView[i], p.FieldOfView[i+1], position-float64(i)))
+	}
+	return states
+}
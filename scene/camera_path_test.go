@@ -0,0 +1,115 @@
+package scene
+
+import (
+	gomath "math"
+	"raygo/math"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestCatmullRomPassesThroughControlPoints(t *testing.T) {
+	points := []math.Point{
+		math.CreatePoint(0, 0, 0),
+		math.CreatePoint(1, 2, 0),
+		math.CreatePoint(3, 2, 1),
+		math.CreatePoint(4, 0, 1),
+	}
+
+	for i, p := range points {
+		u := float64(i) / float64(len(points)-1)
+		assert.Assert(t, CATMULL_ROM.splinePoint(points, u).Equals(p), "%v", i)
+	}
+}
+
+func TestBezierSpline(t *testing.T) {
+	points := []math.Point{
+		math.CreatePoint(0, 0, 0),
+		math.CreatePoint(0, 1, 0),
+		math.CreatePoint(1, 1, 0),
+		math.CreatePoint(1, 0, 0),
+		// second segment
+		math.CreatePoint(1, -1, 0),
+		math.CreatePoint(2, -1, 0),
+		math.CreatePoint(2, 0, 0),
+	}
+
+	assert.Assert(t, BEZIER.segmentCount(len(points)) == 2)
+	assert.Assert(t, BEZIER.splinePoint(points, 0.0).Equals(points[0]))
+	assert.Assert(t, BEZIER.splinePoint(points, 0.25).Equals(math.CreatePoint(0.5, 0.75, 0)))
+	// the control points in between are not on the curve, but the end of every segment is
+	assert.Assert(t, BEZIER.splinePoint(points, 0.5).Equals(points[3]))
+	assert.Assert(t, BEZIER.splinePoint(points, 1.0).Equals(points[6]))
+}
+
+func TestSampleByArcLength(t *testing.T) {
+	// the second segment is twice as long as the first, evenly spaced parameters would cluster frames on the first
+	points := []math.Point{
+		math.CreatePoint(0, 0, 0),
+		math.CreatePoint(1, 0, 0),
+		math.CreatePoint(3, 0, 0),
+	}
+	assert.Assert(t, CATMULL_ROM.splinePoint(points, 0.25).X < 0.4)
+
+	samples := CATMULL_ROM.sampleByArcLength(points, 7)
+
+	assert.Assert(t, len(samples) == 7)
+	assert.Assert(t, samples[0].Equals(points[0]))
+	assert.Assert(t, samples[6].Equals(points[2]))
+	for i := 1; i < len(samples); i++ {
+		step := samples[i].Subtract(samples[i-1]).Magnitude()
+		assert.Assert(t, gomath.Abs(step-0.5) < 0.001, "step %v is %v", i, step)
+	}
+}
+
+func TestSampleByArcLengthSinglePoint(t *testing.T) {
+	p := math.CreatePoint(1, 2, 3)
+	samples := BEZIER.sampleByArcLength([]math.Point{p}, 3)
+
+	assert.Assert(t, len(samples) == 3)
+	assert.Assert(t, samples[2].Equals(p))
+}
+
+func TestCreateAnimationStatesAlongPath(t *testing.T) {
+	cam := CreateCamera(10, 10, gomath.Pi/2)
+	up := math.CreateVector(0, 1, 0)
+	cam.Position = CreateCameraPosition(math.CreatePoint(0, 0, -5), math.CreatePoint(0, 0, 0), up)
+	from := []math.Point{math.CreatePoint(0, 0, -5), math.CreatePoint(5, 0, -5)}
+	cam.Animation = CreateCameraAnimation(0, 1, 5)
+	cam.Animation.Path = CreateCameraPath(CATMULL_ROM, from, nil)
+	cam.Animation.Path.FieldOfView = []float64{gomath.Pi / 2, gomath.Pi / 4, gomath.Pi / 2}
+
+	cam.createAnimationStates()
+
+	assert.Assert(t, len(cam.PositionStates) == 5)
+	assert.Assert(t, cam.PositionStates[0].From.Equals(from[0]))
+	assert.Assert(t, cam.PositionStates[2].From.Equals(math.CreatePoint(2.5, 0, -5)))
+	assert.Assert(t, cam.PositionStates[4].From.Equals(from[1]))
+	// without control points for the target the camera keeps looking at the same point
+	assert.Assert(t, cam.PositionStates[4].To.Equals(math.CreatePoint(0, 0, 0)))
+
+	assert.Assert(t, len(cam.FovStates) == 5)
+	assert.Assert(t, cam.FovStates[1] == 3*gomath.Pi/8)
+	assert.Assert(t, cam.FovStates[2] == gomath.Pi/4)
+	assert.Assert(t, cam.FovStates[4] == gomath.Pi/2)
+}
+
+func TestRenderTracksObject(t *testing.T) {
+	w := DefaultWorld()
+	w.CalculateInverseTransforms()
+	tracked := w.Objects[1]
+	w.Animation = CreateAnimation(1.0, 2.0)
+	w.Animation.AddObject(CreateObjectAnimation(tracked, []Keyframe{
+		{Time: 0.0, Translation: ptr(math.CreateVector(0, 0, 0))},
+		{Time: 1.0, Translation: ptr(math.CreateVector(1, 0, 0))},
+	}))
+
+	c := CreateCamera(5, 5, gomath.Pi/2)
+	c.Position = CreateCameraPosition(math.CreatePoint(0, 0, -5), math.CreatePoint(0, 0, 0), math.CreateVector(0, 1, 0))
+	c.Tracking = tracked
+
+	images := c.Render(w, false)
+
+	assert.Assert(t, len(images) == 2)
+	assert.Assert(t, c.Position.To.Equals(math.CreatePoint(1, 0, 0)), "%v", c.Position.To)
+}
@@ -131,7 +131,7 @@ func TestInvestigateAcneBug(t *testing.T) {
 	objs = append(objs, room, tableSurface)
 	light := lighting.CreateLight(math.CreatePoint(-9.0, 9.0, -5.0), math.CreateColor(0.7, 0.7, 0.7))
 	w := EmptyWorld()
-	w.Light = &light
+	w.AddLight(light)
 	w.Objects = objs
 	w.CalculateInverseTransforms()
 
@@ -206,3 +206,209 @@ func TestCreateAnimationStatesQuarterCircleMovement(t *testing.T) {
 	assert.Assert(t, startPosition.Equals(&cam.PositionStates[0]))
 	assert.Assert(t, endPosition.Equals(&cam.PositionStates[1]))
 }
+
+func TestSetFieldOfViewRecalculatesPixelSize(t *testing.T) {
+	c := CreateCamera(200, 125, gomath.Pi/3.0)
+
+	c.SetFieldOfView(gomath.Pi / 2.0)
+
+	assert.Assert(t, c.FieldOfView == gomath.Pi/2.0)
+	assert.Assert(t, floatEquals(c.HalfWidth, 1.0))
+	assert.Assert(t, floatEquals(c.PixelSize, 0.01))
+}
+
+func TestMultithreadedRenderMatchesSinglethreaded(t *testing.T) {
+	w := DefaultWorld()
+	w.CalculateInverseTransforms()
+	from := math.CreatePoint(1.0, 2.0, -5.0)
+	to := math.CreatePoint(0.0, 0.0, 0.0)
+	up := math.CreateVector(0.0, 1.0, 0.0)
+
+	samplers := []Sampler{DefaultSampler(), CreateSampler(3, GAUSSIAN_FILTER, 7), CreateAdaptiveSampler(0.05, 2)}
+	for _, sampler := range samplers {
+		// sizes that are not a multiple of TILE_SIZE
+		c := CreateCamera(TILE_SIZE+13, 2*TILE_SIZE+5, gomath.Pi/3.0)
+		c.Position = CreateCameraPosition(from, to, up)
+		c.Sampler = sampler
+
+		expected := c.RenderSinglethreaded(w)
+		for _, threads := range []int{1, 3, 16} {
+			actual := c.RenderMultithreaded(w, threads)
+
+			assert.Assert(t, len(expected.Pixels) == len(actual.Pixels))
+			for i := range expected.Pixels {
+				assert.Assert(t, expected.Pixels[i] == actual.Pixels[i], "pixel %v differs with %v threads", i, threads)
+			}
+		}
+	}
+}
+
+func TestCreateTilesCoversImage(t *testing.T) {
+	c := CreateCamera(2*TILE_SIZE+1, TILE_SIZE-1, gomath.Pi/3.0)
+
+	tiles := c.createTiles()
+
+	assert.Assert(t, len(tiles) == 3)
+	covered := 0
+	for _, tl := range tiles {
+		covered += (tl.toX - tl.fromX) * (tl.toY - tl.fromY)
+	}
+	assert.Assert(t, covered == c.Hsize*c.Vsize)
+}
+
+func TestRayThroughLensWithoutAperture(t *testing.T) {
+	c := CreateCamera(201, 101, gomath.Pi/2.0)
+	c.SetTransform(math.Rotation_Y(gomath.Pi / 4.0).MulM(math.Translation(0.0, -2.0, 5.0)))
+	coordinate := c.calculateWorldCoordinateWithOffset(100.0, 50.0, 0.5, 0.5)
+
+	expected := c.RayForCoordinate(coordinate)
+	actual := c.RayThroughLens(coordinate, 0.3, 0.7)
+
+	assert.Assert(t, expected.Origin.Equals(actual.Origin))
+	assert.Assert(t, expected.Direction.Equals(actual.Direction))
+}
+
+func TestRayThroughLensConvergesOnFocalPlane(t *testing.T) {
+	c := CreateCamera(201, 101, gomath.Pi/2.0)
+	c.SetTransform(math.ViewTransform(math.CreatePoint(0.0, 0.0, -5.0), math.CreatePoint(0.0, 0.0, 0.0), math.CreateVector(0.0, 1.0, 0.0)))
+	c.Aperture = 0.5
+	c.FocalDistance = 5.0
+	coordinate := c.calculateWorldCoordinateWithOffset(30.0, 20.0, 0.5, 0.5)
+	pinhole := c.RayForCoordinate(coordinate)
+	// the pinhole ray crosses the focal plane z = 0 here
+	expected := pinhole.Position(5.0 / pinhole.Direction.Z)
+
+	for _, lens := range [][2]float64{{0.0, 0.0}, {0.99, 0.25}, {0.5, 0.5}, {0.7, 0.9}} {
+		r := c.RayThroughLens(coordinate, lens[0], lens[1])
+
+		assert.Assert(t, r.Origin.Subtract(math.CreatePoint(0.0, 0.0, -5.0)).Magnitude() <= 0.25+math.EPSILON)
+		assert.Assert(t, r.Origin.Z == -5.0)
+		actual := r.Position(5.0 / r.Direction.Z)
+		assert.Assert(t, expected.Equals(actual))
+	}
+}
+
+func TestDepthOfFieldBlursOutOfFocusObjects(t *testing.T) {
+	w := DefaultWorld()
+	w.CalculateInverseTransforms()
+	c := CreateCamera(41, 41, gomath.Pi/3.0)
+	c.Position = CreateCameraPosition(math.CreatePoint(0.0, 0.0, -5.0), math.CreatePoint(0.0, 0.0, 0.0), math.CreateVector(0.0, 1.0, 0.0))
+	c.Sampler = CreateSampler(4, BOX_FILTER, 0)
+	sharp := c.RenderSinglethreaded(w)
+
+	c.Aperture = 1.0
+	c.FocalDistance = 20.0
+	blurred := c.RenderSinglethreaded(w)
+	again := c.RenderMultithreaded(w, 4)
+
+	// the silhouette of the sphere bleeds into pixels that only saw the background before
+	black := math.CreateColor(0.0, 0.0, 0.0)
+	bled := 0
+	for x := range c.Hsize {
+		if sharp.GetPixelAt(x, 20).Equals(black) && !blurred.GetPixelAt(x, 20).Equals(black) {
+			bled++
+		}
+	}
+	assert.Assert(t, bled > 0)
+	for i := range blurred.Pixels {
+		assert.Assert(t, blurred.Pixels[i] == again.Pixels[i])
+	}
+}
+
+func TestShutterBlursMovingObjects(t *testing.T) {
+	w := DefaultWorld()
+	w.Objects[0].SetMotion(g.CreateMotion(math.Translation(3.0, 0.0, 0.0)))
+	w.CalculateInverseTransforms()
+	c := CreateCamera(41, 41, gomath.Pi/3.0)
+	c.Position = CreateCameraPosition(math.CreatePoint(0.0, 0.0, -5.0), math.CreatePoint(0.0, 0.0, 0.0), math.CreateVector(0.0, 1.0, 0.0))
+	c.Sampler = CreateSampler(4, BOX_FILTER, 0)
+	sharp := c.RenderSinglethreaded(w)
+
+	c.Shutter = 0.5
+	blurred := c.RenderSinglethreaded(w)
+	again := c.RenderMultithreaded(w, 4)
+	c.Sampler = CreateAdaptiveSampler(0.1, 2)
+	adaptive := c.RenderSinglethreaded(w)
+
+	// the sphere is smeared to the right of the image, its left edge is covered for part of the time
+	black := math.CreateColor(0.0, 0.0, 0.0)
+	assert.Assert(t, sharp.GetPixelAt(35, 20).Equals(black))
+	assert.Assert(t, !blurred.GetPixelAt(35, 20).Equals(black))
+	assert.Assert(t, !adaptive.GetPixelAt(35, 20).Equals(black))
+	assert.Assert(t, blurred.GetPixelAt(15, 20).X < sharp.GetPixelAt(15, 20).X)
+	for i := range blurred.Pixels {
+		assert.Assert(t, blurred.Pixels[i] == again.Pixels[i])
+	}
+}
+
+func TestOrthographicRaysAreParallel(t *testing.T) {
+	c := CreateCamera(201, 101, gomath.Pi/2.0)
+	c.ViewWidth = 4.0
+	c.SetProjection(ORTHOGRAPHIC)
+	expectedDirection := math.CreateVector(0.0, 0.0, -1.0)
+
+	center := c.RayForPixel(100, 50)
+	corner := c.RayForPixel(0, 0)
+
+	assert.Assert(t, center.Origin.Equals(math.CreatePoint(0.0, 0.0, 0.0)))
+	assert.Assert(t, center.Direction.Equals(expectedDirection))
+	// the corner pixel center is half a pixel inside of the view width
+	halfPixel := c.PixelSize / 2.0
+	assert.Assert(t, corner.Origin.Equals(math.CreatePoint(2.0-halfPixel, c.HalfHeight-halfPixel, 0.0)))
+	assert.Assert(t, corner.Direction.Equals(expectedDirection))
+}
+
+func TestOrthographicViewWidthOfVerticalCanvas(t *testing.T) {
+	c := CreateCamera(100, 200, gomath.Pi/2.0)
+	c.SetViewWidth(4.0)
+	c.SetProjection(ORTHOGRAPHIC)
+
+	assert.Assert(t, floatEquals(c.HalfWidth, 2.0))
+	assert.Assert(t, floatEquals(c.HalfHeight, 4.0))
+}
+
+func TestFisheyeAngleGrowsWithDistanceToCenter(t *testing.T) {
+	// 180 degree fisheye, the horizontal edge of the image looks sideways
+	c := CreateCamera(200, 100, gomath.Pi)
+	c.SetProjection(FISHEYE)
+
+	center := c.RayForCoordinate(math.CreatePoint(0.0, 0.0, -1.0))
+	halfway := c.RayForCoordinate(math.CreatePoint(0.5, 0.0, -1.0))
+	edge := c.RayForCoordinate(math.CreatePoint(1.0, 0.0, -1.0))
+	top := c.RayForCoordinate(math.CreatePoint(0.0, 0.5, -1.0))
+
+	assert.Assert(t, center.Direction.Equals(math.CreateVector(0.0, 0.0, -1.0)))
+	assert.Assert(t, halfway.Direction.Equals(math.CreateVector(gomath.Sqrt(2)/2.0, 0.0, -gomath.Sqrt(2)/2.0)))
+	assert.Assert(t, edge.Direction.Equals(math.CreateVector(1.0, 0.0, 0.0)))
+	assert.Assert(t, top.Direction.Equals(math.CreateVector(0.0, gomath.Sqrt(2)/2.0, -gomath.Sqrt(2)/2.0)))
+}
+
+func TestEquirectangularCoversFullSphere(t *testing.T) {
+	c := CreateCamera(200, 100, gomath.Pi/2.0)
+	c.SetProjection(EQUIRECTANGULAR)
+
+	forward := c.RayForCoordinate(math.CreatePoint(0.0, 0.0, -1.0))
+	side := c.RayForCoordinate(math.CreatePoint(0.5, 0.0, -1.0))
+	behind := c.RayForCoordinate(math.CreatePoint(1.0, 0.0, -1.0))
+	up := c.RayForCoordinate(math.CreatePoint(0.0, 0.5, -1.0))
+	down := c.RayForCoordinate(math.CreatePoint(0.3, -0.5, -1.0))
+
+	assert.Assert(t, forward.Direction.Equals(math.CreateVector(0.0, 0.0, -1.0)))
+	assert.Assert(t, side.Direction.Equals(math.CreateVector(1.0, 0.0, 0.0)))
+	assert.Assert(t, behind.Direction.Equals(math.CreateVector(0.0, 0.0, 1.0)))
+	assert.Assert(t, up.Direction.Equals(math.CreateVector(0.0, 1.0, 0.0)))
+	assert.Assert(t, down.Direction.Equals(math.CreateVector(0.0, -1.0, 0.0)))
+}
+
+func TestProjectionsIgnoreAperture(t *testing.T) {
+	c := CreateCamera(200, 100, gomath.Pi/2.0)
+	c.Aperture = 0.5
+	c.FocalDistance = 3.0
+	c.SetProjection(EQUIRECTANGULAR)
+	coordinate := math.CreatePoint(0.2, 0.1, -1.0)
+
+	r := c.RayThroughLens(coordinate, 0.7, 0.2)
+
+	assert.Assert(t, r.Origin.Equals(math.CreatePoint(0.0, 0.0, 0.0)))
+	assert.Assert(t, r.Direction.Equals(c.RayForCoordinate(coordinate).Direction))
+}
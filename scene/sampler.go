@@ -0,0 +1,99 @@
+package scene
+
+import (
+	gomath "math"
+	"math/rand/v2"
+)
+
+type Filter int
+
+const (
+	BOX_FILTER Filter = iota
+	TENT_FILTER
+	GAUSSIAN_FILTER
+)
+
+// Sampler decides where rays are cast inside of a pixel and how their colors are combined.
+// A pixel is divided into Samples x Samples cells of equal size and one ray is cast through a random
+// position inside of every cell. The random positions only depend on Seed and the pixel, so
+// images are reproducible regardless of how the pixels are distributed among render workers.
+//
+// In adaptive mode Samples, Filter and Seed are ignored, see adaptive.go
+type Sampler struct {
+	Samples   int // samples per axis, 1 casts a single ray through the pixel center
+	Filter    Filter
+	Seed      uint64
+	Adaptive  bool
+	Threshold float64 // maximum color difference of a pixels corners before it gets subdivided
+	MaxDepth  int     // how often a pixel can be subdivided into quarters
+}
+
+type pixelSample struct {
+	xOffset float64 // offset from the top left corner of the pixel, in [0, 1)
+	yOffset float64
+	weight  float64
+	lensU   float64 // position on the lens for depth of field, both in [0, 1)
+	lensV   float64
+	time    float64 // point in time while the shutter is open for motion blur, in [0, 1)
+}
+
+func CreateSampler(samples int, filter Filter, seed uint64) Sampler {
+	return Sampler{
+		Samples: samples,
+		Filter:  filter,
+		Seed:    seed,
+	}
+}
+
+func CreateAdaptiveSampler(threshold float64, maxDepth int) Sampler {
+	return Sampler{
+		Samples:   1,
+		Filter:    BOX_FILTER,
+		Adaptive:  true,
+		Threshold: threshold,
+		MaxDepth:  maxDepth,
+	}
+}
+
+func DefaultSampler() Sampler {
+	return CreateSampler(1, BOX_FILTER, 0)
+}
+
+func (s *Sampler) pixelSamples(x int, y int) []pixelSample {
+	rng := rand.New(rand.NewPCG(s.Seed, uint64(x)<<32|uint64(uint32(y))))
+	if s.Samples <= 1 {
+		return []pixelSample{{xOffset: 0.5, yOffset: 0.5, weight: 1.0, lensU: rng.Float64(), lensV: rng.Float64(), time: rng.Float64()}}
+	}
+
+	cellSize := 1.0 / float64(s.Samples)
+
+	samples := make([]pixelSample, 0, s.Samples*s.Samples)
+	for v := range s.Samples {
+		for u := range s.Samples {
+			xOffset := (float64(u) + rng.Float64()) * cellSize
+			yOffset := (float64(v) + rng.Float64()) * cellSize
+			samples = append(samples, pixelSample{
+				xOffset: xOffset,
+				yOffset: yOffset,
+				weight:  s.Filter.weight(xOffset-0.5, yOffset-0.5),
+				lensU:   rng.Float64(),
+				lensV:   rng.Float64(),
+				time:    rng.Float64(),
+			})
+		}
+	}
+	return samples
+}
+
+// weight of a sample that is dx and dy pixels away from the pixel center
+func (f Filter) weight(dx float64, dy float64) float64 {
+	switch f {
+	case TENT_FILTER:
+		// falls off linearly and reaches zero one pixel away from the center
+		return (1.0 - gomath.Abs(dx)) * (1.0 - gomath.Abs(dy))
+	case GAUSSIAN_FILTER:
+		// standard deviation of half a pixel
+		return gomath.Exp(-2.0 * (dx*dx + dy*dy))
+	}
+	return 1.0
+}
@@ -0,0 +1,72 @@
+package scene
+
+import (
+	gomath "math"
+	"raygo/math"
+	"slices"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSinglePixelSampleIsCenter(t *testing.T) {
+	s := DefaultSampler()
+
+	samples := s.pixelSamples(3, 4)
+
+	assert.Assert(t, len(samples) == 1)
+	assert.Assert(t, samples[0].xOffset == 0.5)
+	assert.Assert(t, samples[0].yOffset == 0.5)
+}
+
+func TestPixelSamplesAreStratified(t *testing.T) {
+	s := CreateSampler(4, BOX_FILTER, 1)
+
+	samples := s.pixelSamples(10, 20)
+
+	assert.Assert(t, len(samples) == 16)
+	for i, sample := range samples {
+		u, v := i%4, i/4
+		assert.Assert(t, sample.xOffset >= float64(u)*0.25 && sample.xOffset < float64(u+1)*0.25)
+		assert.Assert(t, sample.yOffset >= float64(v)*0.25 && sample.yOffset < float64(v+1)*0.25)
+		assert.Assert(t, sample.weight == 1.0)
+	}
+}
+
+func TestPixelSamplesAreDeterministic(t *testing.T) {
+	s1 := CreateSampler(3, BOX_FILTER, 42)
+	s2 := CreateSampler(3, BOX_FILTER, 42)
+	s3 := CreateSampler(3, BOX_FILTER, 43)
+
+	assert.Assert(t, slices.Equal(s1.pixelSamples(5, 6), s2.pixelSamples(5, 6)))
+	assert.Assert(t, s1.pixelSamples(5, 6)[0] != s1.pixelSamples(6, 5)[0])
+	assert.Assert(t, s1.pixelSamples(5, 6)[0] != s3.pixelSamples(5, 6)[0])
+}
+
+func TestFilterWeights(t *testing.T) {
+	assert.Assert(t, BOX_FILTER.weight(0.4, -0.3) == 1.0)
+	assert.Assert(t, TENT_FILTER.weight(0.0, 0.0) == 1.0)
+	assert.Assert(t, TENT_FILTER.weight(0.5, 0.0) == 0.5)
+	assert.Assert(t, GAUSSIAN_FILTER.weight(0.0, 0.0) == 1.0)
+	assert.Assert(t, GAUSSIAN_FILTER.weight(0.5, 0.0) == gomath.Exp(-0.5))
+	assert.Assert(t, GAUSSIAN_FILTER.weight(0.5, 0.0) > GAUSSIAN_FILTER.weight(0.5, 0.5))
+}
+
+func TestSupersampledRenderIsCloseToCenterRays(t *testing.T) {
+	w := DefaultWorld()
+	w.CalculateInverseTransforms()
+	c := CreateCamera(101, 101, gomath.Pi/2.0)
+	c.Position = CreateCameraPosition(math.CreatePoint(0.0, 0.0, -5.0), math.CreatePoint(0.0, 0.0, 0.0), math.CreateVector(0.0, 1.0, 0.0))
+
+	expected := c.RenderSinglethreaded(w)
+	c.Sampler = CreateSampler(4, TENT_FILTER, 0)
+	actual := c.RenderSinglethreaded(w)
+
+	// the corner only sees the black background
+	assert.Assert(t, math.CreateColor(0.0, 0.0, 0.0).Equals(actual.GetPixelAt(0, 0)))
+	// within the sphere the shading barely changes inside of a single pixel
+	center := actual.GetPixelAt(50, 50).Subtract(expected.GetPixelAt(50, 50))
+	assert.Assert(t, gomath.Abs(center.X) < 0.001)
+	assert.Assert(t, gomath.Abs(center.Y) < 0.001)
+	assert.Assert(t, gomath.Abs(center.Z) < 0.001)
+}
@@ -10,20 +10,22 @@ import (
 const MAX_REFLECTION_LIMIT = 4
 
 type World struct {
-	Objects []g.Shape
-	Light   *lighting.Light
+	Objects   []g.Shape
+	Lights    []lighting.Light
+	Animation *Animation // nil for a static world
 }
 
-func CreateWorld(objs []g.Shape, l *lighting.Light) *World {
+func CreateWorld(objs []g.Shape, lights []lighting.Light) *World {
 	return &World{
 		Objects: objs,
-		Light:   l,
+		Lights:  lights,
 	}
 }
 
 func EmptyWorld() *World {
 	objects := make([]g.Shape, 0)
-	return CreateWorld(objects, nil)
+	lights := make([]lighting.Light, 0)
+	return CreateWorld(objects, lights)
 }
 
 func DefaultWorld() *World {
@@ -43,7 +45,12 @@ func DefaultWorld() *World {
 	s2.SetTransform(transform)
 	objects = append(objects, s2)
 
-	return CreateWorld(objects, &light)
+	return CreateWorld(objects, []lighting.Light{light})
+}
+
+func (w *World) AddLight(l lighting.Light) *World {
+	w.Lights = append(w.Lights, l)
+	return w
 }
 
 func (w *World) Intersect(r g.Ray) []g.Intersection {
@@ -56,13 +63,20 @@ func (w *World) Intersect(r g.Ray) []g.Intersection {
 }
 
 func (w *World) ShadeHit(comp g.IntersectionComputations, remainingReflections int) math.Color {
-	shadowed := w.IsShadowed(comp.OverPoint)
-
-	surfaceColor := lighting.PhongLighting(*comp.Object.GetMaterial(),
-		comp.Object,
-		*w.Light,
-		comp.OverPoint, comp.Eyev, comp.Normalv,
-		shadowed)
+	// every light contributes its own phong term and is occluded independently
+	surfaceColor := math.CreateColor(0.0, 0.0, 0.0)
+	material := restMaterial(comp)
+	for _, light := range w.Lights {
+		samples := light.Samples(comp.OverPoint)
+		intensity := w.sampledIntensity(samples, comp.OverPoint, comp.Time)
+
+		surfaceColor = surfaceColor.Add(lighting.PhongLightingSamples(material,
+			comp.Object,
+			light,
+			samples,
+			comp.OverPoint, comp.Eyev, comp.Normalv,
+			intensity))
+	}
 
 	reflectedColor := w.ReflectedColor(comp, remainingReflections)
 	refractedColor := w.RefractedColor(comp, remainingReflections)
@@ -78,6 +92,25 @@ func (w *World) ShadeHit(comp g.IntersectionComputations, remainingReflections i
 	return surfaceColor.Add(reflectedColor).Add(refractedColor)
 }
 
+// restMaterial returns the material of the hit object. Patterns and textures move along with the shape,
+// for a moving shape they are looked up where the hit point was when the shutter opened.
+func restMaterial(comp g.IntersectionComputations) g.Material {
+	material := *comp.Object.GetMaterial()
+	if comp.Time <= 0.0 || (material.Pattern == nil && !material.Texture.Exists()) || !g.IsMoving(comp.Object) {
+		return material
+	}
+
+	position := g.RestPosition(comp.Object, comp.OverPoint, comp.Time)
+	normal := comp.Object.NormalAt(position, g.CreateIntersection(comp.IntersectionAt, comp.Object))
+	if comp.Inside {
+		normal = normal.Negate()
+	}
+	material.Color = lighting.SurfaceColor(material, comp.Object, position, normal)
+	material.Pattern = nil
+	material.Texture = g.Texture{}
+	return material
+}
+
 func (w *World) ColorAt(r g.Ray, remainingReflections int) math.Color {
 	xs := w.Intersect(r)
 	hit := g.Hit(xs)
@@ -94,16 +127,41 @@ func (w *World) GetObject(index int) *g.Shape {
 	return &w.Objects[index]
 }
 
-func (w *World) IsShadowed(p math.Point) bool {
-	v := w.Light.Position.Subtract(p)
-	distance := v.Magnitude()
-	direction := v.Normalize()
+// IntensityAt returns the fraction of the light that reaches p.
+// Point lights are either fully visible (1.0) or fully blocked (0.0),
+// area lights are visible from a subset of their cells.
+func (w *World) IntensityAt(light lighting.Light, p math.Point) float64 {
+	return w.intensityAt(light, p, 0.0)
+}
+
+// intensityAt tests the shadows at time in the shutter interval
+func (w *World) intensityAt(light lighting.Light, p math.Point, time float64) float64 {
+	return w.sampledIntensity(light.Samples(p), p, time)
+}
+
+// sampledIntensity is the fraction of the samples that are visible from p
+func (w *World) sampledIntensity(samples []lighting.LightSample, p math.Point, time float64) float64 {
+	total := 0.0
+	for _, sample := range samples {
+		if !w.isOccluded(p, sample, time) {
+			total += 1.0
+		}
+	}
+	return total / float64(len(samples))
+}
+
+// IsShadowed reports whether no part of the light is visible from p
+func (w *World) IsShadowed(p math.Point, light lighting.Light) bool {
+	return w.IntensityAt(light, p) == 0.0
+}
 
-	r := g.CreateRay(p, direction)
+func (w *World) isOccluded(p math.Point, sample lighting.LightSample, time float64) bool {
+	r := g.CreateRay(p, sample.Direction)
+	r.Time = time
 	xs := w.Intersect(r)
 
 	h := g.Hit(xs)
-	return h != nil && h.IntersectionAt > math.EPSILON && h.IntersectionAt < distance
+	return h != nil && h.IntersectionAt > math.EPSILON && h.IntersectionAt < sample.Distance
 }
 
 func (w *World) ReflectedColor(precomps g.IntersectionComputations, remainingReflections int) math.Color {
@@ -112,6 +170,7 @@ func (w *World) ReflectedColor(precomps g.IntersectionComputations, remainingRef
 	}
 
 	reflectedRay := g.CreateRay(precomps.OverPoint, precomps.Reflectv)
+	reflectedRay.Time = precomps.Time
 	colorAtReflectionTarget := w.ColorAt(reflectedRay, remainingReflections-1)
 
 	return colorAtReflectionTarget.Mul(precomps.Object.GetMaterial().Reflective)
@@ -146,6 +205,7 @@ func (w *World) RefractedColor(precomps g.IntersectionComputations, remainingRef
 
 	// create the refracted ray
 	refractRay := g.CreateRay(precomps.UnderPoint, direction)
+	refractRay.Time = precomps.Time
 
 	// find the color of the refracted ray, making sure to multiply
 	// by the transparency value to account for any opacity
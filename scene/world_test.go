@@ -13,7 +13,7 @@ import (
 func TestEmptyWorld(t *testing.T) {
 	w := EmptyWorld()
 
-	assert.Assert(t, w.Light == nil)
+	assert.Assert(t, len(w.Lights) == 0)
 	assert.Assert(t, len(w.Objects) == 0)
 }
 
@@ -31,7 +31,8 @@ func TestDefaultWorld(t *testing.T) {
 
 	w := DefaultWorld()
 
-	assert.Assert(t, expectedLight.Equals(*w.Light))
+	assert.Assert(t, len(w.Lights) == 1)
+	assert.Assert(t, expectedLight.Equals(w.Lights[0]))
 	assert.Assert(t, len(w.Objects) == 2)
 	assert.Assert(t, expectedShape1.Equals(w.Objects[0]))
 	assert.Assert(t, expectedShape2.Equals(w.Objects[1]))
@@ -68,7 +69,7 @@ func TestShadeHitInside(t *testing.T) {
 	w := DefaultWorld()
 	w.CalculateInverseTransforms()
 	l := lighting.CreateLight(math.CreatePoint(0.0, 0.25, 0.0), math.CreateColor(1.0, 1.0, 1.0))
-	w.Light = &l
+	w.Lights = []lighting.Light{l}
 
 	r := g.CreateRay(math.CreatePoint(0.0, 0.0, 0.0), math.CreateVector(0.0, 0.0, 1.0))
 	i := g.CreateIntersection(0.5, w.Objects[1])
@@ -127,7 +128,7 @@ func TestColorAtHitBehindRay(t *testing.T) {
 	w.CalculateInverseTransforms()
 
 	light := lighting.CreateLight(math.CreatePoint(-10.0, 10.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
-	w.Light = &light
+	w.AddLight(light)
 
 	r := g.CreateRay(math.CreatePoint(0.0, 0.0, 0.75), math.CreateVector(0.0, 0.0, -1.0))
 
@@ -141,7 +142,7 @@ func TestIsShadowedColinearPoint(t *testing.T) {
 	w.CalculateInverseTransforms()
 	p := math.CreatePoint(0.0, 10.0, 0.0)
 
-	assert.Assert(t, w.IsShadowed(p) == false)
+	assert.Assert(t, w.IsShadowed(p, w.Lights[0]) == false)
 }
 
 func TestIsShadowedBehindSphere(t *testing.T) {
@@ -149,7 +150,7 @@ func TestIsShadowedBehindSphere(t *testing.T) {
 	w.CalculateInverseTransforms()
 	p := math.CreatePoint(10.0, -10.0, 10.0)
 
-	assert.Assert(t, w.IsShadowed(p) == true)
+	assert.Assert(t, w.IsShadowed(p, w.Lights[0]) == true)
 }
 
 func TestIsShadowedBehindLight(t *testing.T) {
@@ -157,7 +158,7 @@ func TestIsShadowedBehindLight(t *testing.T) {
 	w.CalculateInverseTransforms()
 	p := math.CreatePoint(-20.0, 20.0, -20.0)
 
-	assert.Assert(t, w.IsShadowed(p) == false)
+	assert.Assert(t, w.IsShadowed(p, w.Lights[0]) == false)
 }
 
 func TestIsShadowedBetweenLightAndShape(t *testing.T) {
@@ -165,7 +166,7 @@ func TestIsShadowedBetweenLightAndShape(t *testing.T) {
 	w.CalculateInverseTransforms()
 	p := math.CreatePoint(-2.0, 2.0, -2.0)
 
-	assert.Assert(t, w.IsShadowed(p) == false)
+	assert.Assert(t, w.IsShadowed(p, w.Lights[0]) == false)
 }
 
 func TestShadeHitInShadow(t *testing.T) {
@@ -181,7 +182,7 @@ func TestShadeHitInShadow(t *testing.T) {
 	w.CalculateInverseTransforms()
 
 	light := lighting.CreateLight(math.CreatePoint(0.0, 0.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
-	w.Light = &light
+	w.AddLight(light)
 
 	r := g.CreateRay(math.CreatePoint(0.0, 0.0, 5.0), math.CreateVector(0.0, 0.0, 1.0))
 	i := g.CreateIntersection(4.0, s2)
@@ -246,7 +247,7 @@ func TestColorAtNoEndlessRecursion(t *testing.T) {
 	w := EmptyWorld()
 
 	light := lighting.CreateLight(math.CreatePoint(0.0, 0.0, 0.0), math.CreateColor(1.0, 1.0, 1.0))
-	w.Light = &light
+	w.AddLight(light)
 
 	lower := g.CreatePlane()
 	lower.GetMaterial().SetReflective(1.0)
@@ -338,3 +339,111 @@ func TestShadeHitWithTransparentMaterial(t *testing.T) {
 
 	assert.Assert(t, expected.Equals(w.ShadeHit(precomps, 5)))
 }
+
+func TestShadeHitMultipleLights(t *testing.T) {
+	w := DefaultWorld()
+	w.AddLight(lighting.CreateLight(math.CreatePoint(-10.0, 10.0, -10.0), math.CreateColor(1.0, 1.0, 1.0)))
+	w.CalculateInverseTransforms()
+	r := g.CreateRay(math.CreatePoint(0.0, 0.0, -5.0), math.CreateVector(0.0, 0.0, 1.0))
+	i := g.CreateIntersection(4.0, w.Objects[0])
+	// two identical lights contribute twice the single light color
+	expected := math.CreateColor(0.38066, 0.47583, 0.2855).Mul(2.0)
+
+	comps := i.PrepareComputation(r, make([]g.Intersection, 0))
+	actual := w.ShadeHit(comps, 0)
+
+	assert.Assert(t, expected.Equals(actual))
+}
+
+func TestIsShadowedPerLight(t *testing.T) {
+	w := DefaultWorld()
+	w.AddLight(lighting.CreateLight(math.CreatePoint(20.0, -20.0, 20.0), math.CreateColor(1.0, 1.0, 1.0)))
+	w.CalculateInverseTransforms()
+	p := math.CreatePoint(10.0, -10.0, 10.0)
+
+	assert.Assert(t, w.IsShadowed(p, w.Lights[0]) == true)
+	assert.Assert(t, w.IsShadowed(p, w.Lights[1]) == false)
+}
+
+func TestPointLightIntensityAt(t *testing.T) {
+	w := DefaultWorld()
+	w.CalculateInverseTransforms()
+	light := w.Lights[0]
+
+	testCases := []struct {
+		point    math.Point
+		expected float64
+	}{
+		{math.CreatePoint(0.0, 1.0001, 0.0), 1.0},
+		{math.CreatePoint(-1.0001, 0.0, 0.0), 1.0},
+		{math.CreatePoint(0.0, 0.0, -1.0001), 1.0},
+		{math.CreatePoint(0.0, 0.0, 1.0001), 0.0},
+		{math.CreatePoint(1.0001, 0.0, 0.0), 0.0},
+		{math.CreatePoint(0.0, -1.0001, 0.0), 0.0},
+		{math.CreatePoint(0.0, 0.0, 0.0), 0.0},
+	}
+
+	for _, tc := range testCases {
+		assert.Assert(t, floatEquals(tc.expected, w.IntensityAt(light, tc.point)))
+	}
+}
+
+func TestAreaLightIntensityAt(t *testing.T) {
+	w := DefaultWorld()
+	w.CalculateInverseTransforms()
+	corner := math.CreatePoint(-0.5, -0.5, -5.0)
+	v1 := math.CreateVector(1.0, 0.0, 0.0)
+	v2 := math.CreateVector(0.0, 1.0, 0.0)
+	light := lighting.CreateAreaLight(corner, v1, 2, v2, 2, math.CreateColor(1.0, 1.0, 1.0))
+
+	testCases := []struct {
+		point    math.Point
+		expected float64
+	}{
+		{math.CreatePoint(0.0, 0.0, 2.0), 0.0},
+		{math.CreatePoint(1.0, -1.0, 2.0), 0.25},
+		{math.CreatePoint(1.5, 0.0, 2.0), 0.5},
+		{math.CreatePoint(1.25, 1.25, 3.0), 0.75},
+		{math.CreatePoint(0.0, 0.0, -2.0), 1.0},
+	}
+
+	for _, tc := range testCases {
+		assert.Assert(t, floatEquals(tc.expected, w.IntensityAt(light, tc.point)))
+	}
+}
+
+func TestDirectionalLightShadow(t *testing.T) {
+	w := EmptyWorld()
+	s := g.CreateSphere()
+	s.SetTransform(math.Translation(0.0, 100.0, 0.0))
+	w.Objects = append(w.Objects, s)
+	w.CalculateInverseTransforms()
+	light := lighting.CreateDirectionalLight(math.CreateVector(0.0, -1.0, 0.0), math.CreateColor(1.0, 1.0, 1.0))
+
+	// no matter how far away the occluder is, parallel rays find it
+	assert.Assert(t, w.IsShadowed(math.CreatePoint(0.0, 0.0, 0.0), light))
+	assert.Assert(t, !w.IsShadowed(math.CreatePoint(5.0, 0.0, 0.0), light))
+}
+
+func TestColorAtMovingShape(t *testing.T) {
+	s := g.CreateSphere()
+	m := g.DefaultMaterial()
+	m.Ambient, m.Diffuse, m.Specular = 1.0, 0.0, 0.0
+	m.SetPattern(g.CreateStripePattern(math.CreateColor(1, 1, 1), math.CreateColor(0, 0, 0)))
+	m.Pattern.CalculateInverseTransform()
+	s.SetMaterial(m)
+	s.SetMotion(g.CreateMotion(math.Translation(1, 0, 0)))
+	light := lighting.CreateLight(math.CreatePoint(-10.0, 10.0, -10.0), math.CreateColor(1.0, 1.0, 1.0))
+	w := CreateWorld([]g.Shape{s}, []lighting.Light{light})
+	w.CalculateInverseTransforms()
+	r := g.CreateRay(math.CreatePoint(1.5, 0.0, -5.0), math.CreateVector(0.0, 0.0, 1.0))
+
+	// the sphere is hit once it has moved
+	r.Time = 1.0
+	assert.Assert(t, len(w.Intersect(r)) == 2)
+	// the stripes move along with the sphere, the hit point was at x = 0.5 when the shutter opened
+	assert.Assert(t, w.ColorAt(r, 0).Equals(math.CreateColor(1, 1, 1)))
+
+	r = g.CreateRay(math.CreatePoint(1.5, 0.0, -5.0), math.CreateVector(0.0, 0.0, 1.0))
+	assert.Assert(t, len(w.Intersect(r)) == 0)
+}